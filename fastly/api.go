@@ -0,0 +1,533 @@
+package fastly
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// The vendored go-fastly client does not decode or send every field Fastly
+// supports. The helpers in this file fill those gaps through the client's
+// generic request methods, decoding responses the same way go-fastly does.
+
+// versionObjectPath returns the API path of a named object on a service
+// version, such as a "header" or "logging/s3" endpoint.
+func versionObjectPath(service string, version int, objectType, name string) string {
+	return fmt.Sprintf("/service/%s/version/%d/%s/%s", service, version, objectType, name)
+}
+
+// isDuplicateRecord reports whether err is Fastly refusing to create an
+// object because one of the same name already exists.
+func isDuplicateRecord(err error) bool {
+	httpErr, ok := err.(*gofastly.HTTPError)
+	return ok && (httpErr.StatusCode == http.StatusConflict || httpErr.Message == "Duplicate record")
+}
+
+// adoptOnDuplicate handles the error from creating the object at path. When
+// adopt is set and the object already exists, for example because it was
+// added in the Fastly UI, the existing object is fetched and updated to match
+// input instead. Any other error is returned unchanged.
+func adoptOnDuplicate(conn *gofastly.Client, adopt bool, createErr error, path string, input interface{}) error {
+	if createErr == nil || !adopt || !isDuplicateRecord(createErr) {
+		return createErr
+	}
+
+	resp, err := conn.Get(path, nil)
+	if err != nil {
+		return fmt.Errorf("%s already exists, but fetching it to adopt it failed: %s", path, err)
+	}
+	// Only the name is logged, as the object may hold credentials.
+	var existing struct {
+		Name string `mapstructure:"name"`
+	}
+	if err := decodeFastlyJSON(&existing, resp.Body); err != nil {
+		return err
+	}
+	log.Printf("[INFO] Adopting existing %s (%s)", path, existing.Name)
+
+	resp, err = conn.PutForm(path, input, nil)
+	if err != nil {
+		return fmt.Errorf("%s already exists, but updating it to adopt it failed: %s", path, err)
+	}
+
+	return resp.Body.Close()
+}
+
+// decodeFastlyJSON mirrors go-fastly's response decoding. Fastly returns many
+// numeric and boolean fields as strings, so decoding is weakly typed.
+func decodeFastlyJSON(out interface{}, body io.ReadCloser) error {
+	defer body.Close()
+
+	var parsed interface{}
+	if err := json.NewDecoder(body).Decode(&parsed); err != nil {
+		return err
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(parsed)
+}
+
+// serviceVersion is a service configuration version. go-fastly's Version does
+// not decode the version timestamps, which audit reporting needs.
+type serviceVersion struct {
+	Number    int    `mapstructure:"number"`
+	Comment   string `mapstructure:"comment"`
+	Active    bool   `mapstructure:"active"`
+	Locked    bool   `mapstructure:"locked"`
+	Deployed  bool   `mapstructure:"deployed"`
+	Staging   bool   `mapstructure:"staging"`
+	Testing   bool   `mapstructure:"testing"`
+	CreatedAt string `mapstructure:"created_at"`
+	UpdatedAt string `mapstructure:"updated_at"`
+}
+
+// listServiceVersions returns every version of a service. The endpoint is not
+// paginated; a single response holds the full history.
+func listServiceVersions(conn *gofastly.Client, service string) ([]*serviceVersion, error) {
+	resp, err := conn.Get(fmt.Sprintf("/service/%s/version", service), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*serviceVersion
+	if err := decodeFastlyJSON(&versions, resp.Body); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// tokenInfo describes the API token the provider authenticates with.
+type tokenInfo struct {
+	ID         string `mapstructure:"id"`
+	UserID     string `mapstructure:"user_id"`
+	CustomerID string `mapstructure:"customer_id"`
+	Scope      string `mapstructure:"scope"`
+	ExpiresAt  string `mapstructure:"expires_at"`
+}
+
+// getTokenSelf describes the API token used by conn.
+func getTokenSelf(conn *gofastly.Client) (*tokenInfo, error) {
+	resp, err := conn.Get("/tokens/self", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var token tokenInfo
+	if err := decodeFastlyJSON(&token, resp.Body); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// backendOverride is the part of a backend go-fastly's Backend does not
+// decode.
+type backendOverride struct {
+	Name         string `mapstructure:"name"`
+	OverrideHost string `mapstructure:"override_host" form:"override_host,omitempty"`
+	Comment      string `mapstructure:"comment" form:"comment,omitempty"`
+}
+
+// listBackendOverrides returns the fields go-fastly does not decode of every
+// backend on a service version, keyed by backend name.
+func listBackendOverrides(conn *gofastly.Client, service string, version int) (map[string]*backendOverride, error) {
+	resp, err := conn.Get(fmt.Sprintf("/service/%s/version/%d/backend", service, version), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var backends []*backendOverride
+	if err := decodeFastlyJSON(&backends, resp.Body); err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]*backendOverride, len(backends))
+	for _, b := range backends {
+		overrides[b.Name] = b
+	}
+	return overrides, nil
+}
+
+// updateBackendOverride sets the non-empty fields of o, such as the Host
+// header Fastly sends to a backend, on the named backend.
+func updateBackendOverride(conn *gofastly.Client, service string, version int, backend string, o *backendOverride) error {
+	path := fmt.Sprintf("/service/%s/version/%d/backend/%s", service, version, backend)
+	resp, err := conn.PutForm(path, o, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// conditionComment is the part of a condition go-fastly's Condition does not
+// decode.
+type conditionComment struct {
+	Name    string `mapstructure:"name"`
+	Comment string `mapstructure:"comment"`
+}
+
+// listConditionComments returns the comment of every condition on a service
+// version, keyed by condition name.
+func listConditionComments(conn *gofastly.Client, service string, version int) (map[string]string, error) {
+	resp, err := conn.Get(fmt.Sprintf("/service/%s/version/%d/condition", service, version), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []*conditionComment
+	if err := decodeFastlyJSON(&conditions, resp.Body); err != nil {
+		return nil, err
+	}
+
+	comments := make(map[string]string, len(conditions))
+	for _, c := range conditions {
+		comments[c.Name] = c.Comment
+	}
+	return comments, nil
+}
+
+// conditionCommentInput sets the comment of a condition.
+type conditionCommentInput struct {
+	Comment string `form:"comment"`
+}
+
+// updateConditionComment sets the comment of a condition, which go-fastly's
+// CreateConditionInput cannot carry.
+func updateConditionComment(conn *gofastly.Client, service string, version int, condition, comment string) error {
+	path := fmt.Sprintf("/service/%s/version/%d/condition/%s", service, version, condition)
+	resp, err := conn.PutForm(path, &conditionCommentInput{Comment: comment}, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// healthCheckExtra is the part of a healthcheck go-fastly's HealthCheck does
+// not decode.
+type healthCheckExtra struct {
+	Name    string   `mapstructure:"name"`
+	Comment string   `mapstructure:"comment"`
+	Headers []string `mapstructure:"headers"`
+}
+
+// listHealthCheckExtras returns the comment and headers of every healthcheck
+// on a service version, keyed by healthcheck name.
+func listHealthCheckExtras(conn *gofastly.Client, service string, version int) (map[string]*healthCheckExtra, error) {
+	resp, err := conn.Get(fmt.Sprintf("/service/%s/version/%d/healthcheck", service, version), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var healthChecks []*healthCheckExtra
+	if err := decodeFastlyJSON(&healthChecks, resp.Body); err != nil {
+		return nil, err
+	}
+
+	extras := make(map[string]*healthCheckExtra, len(healthChecks))
+	for _, h := range healthChecks {
+		extras[h.Name] = h
+	}
+	return extras, nil
+}
+
+// healthCheckCommentInput sets the comment of a healthcheck.
+type healthCheckCommentInput struct {
+	Comment string `form:"comment"`
+}
+
+// updateHealthCheckComment sets the comment of a healthcheck, which
+// go-fastly's CreateHealthCheckInput cannot carry.
+func updateHealthCheckComment(conn *gofastly.Client, service string, version int, healthCheck, comment string) error {
+	resp, err := conn.PutForm(versionObjectPath(service, version, "healthcheck", healthCheck), &healthCheckCommentInput{Comment: comment}, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// updateHealthCheckHeaders sets the headers sent with the probes of a
+// healthcheck, each as "Name: value". Fastly takes them as a headers[] list,
+// which go-fastly's form encoder cannot produce.
+func updateHealthCheckHeaders(conn *gofastly.Client, service string, version int, healthCheck string, headers []string) error {
+	body := url.Values{"headers[]": headers}.Encode()
+	resp, err := conn.Request("PUT", versionObjectPath(service, version, "healthcheck", healthCheck), &gofastly.RequestOptions{
+		Headers:    map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Body:       strings.NewReader(body),
+		BodyLength: int64(len(body)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// sumologicFormatInput sets the format of a Sumologic endpoint. Unlike
+// go-fastly's CreateSumologicInput, an empty format is sent rather than
+// omitted, which would leave Fastly's default Apache format in place.
+type sumologicFormatInput struct {
+	Format string `form:"format"`
+}
+
+// updateSumologicFormat sets the format of a Sumologic endpoint, including to
+// the empty string used with message_type "blank".
+func updateSumologicFormat(conn *gofastly.Client, service string, version int, name, format string) error {
+	resp, err := conn.PutForm(versionObjectPath(service, version, "logging/sumologic", name), &sumologicFormatInput{Format: format}, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// requestSettingMaxStaleAgeInput sets the max_stale_age of a request setting.
+// Unlike go-fastly's inputs, a zero value is sent rather than omitted.
+type requestSettingMaxStaleAgeInput struct {
+	MaxStaleAge uint `form:"max_stale_age"`
+}
+
+// updateRequestSettingMaxStaleAge sets how old, in seconds, a stale object
+// served by a request setting may be.
+func updateRequestSettingMaxStaleAge(conn *gofastly.Client, service string, version int, name string, age uint) error {
+	resp, err := conn.PutForm(versionObjectPath(service, version, "request_settings", name), &requestSettingMaxStaleAgeInput{MaxStaleAge: age}, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// tlsActivations is a page of the JSON:API list of TLS activations.
+type tlsActivations struct {
+	Data []struct {
+		ID string `mapstructure:"id"`
+	} `mapstructure:"data"`
+	Links struct {
+		Next string `mapstructure:"next"`
+	} `mapstructure:"links"`
+}
+
+// tlsActivationDomainBatch is the most domains TLS activations are filtered by
+// in one request, which keeps the query string to a safe length.
+const tlsActivationDomainBatch = 50
+
+// listTLSActivationIDs returns the IDs of the TLS activations of the given
+// domains. TLS activations belong to domains rather than services, so they
+// are filtered by a batch of domains at a time, following every page.
+func listTLSActivationIDs(conn *gofastly.Client, domains []string) ([]string, error) {
+	var ids []string
+	for start := 0; start < len(domains); start += tlsActivationDomainBatch {
+		end := start + tlsActivationDomainBatch
+		if end > len(domains) {
+			end = len(domains)
+		}
+
+		params := map[string]string{
+			"filter[tls_domain.id]": strings.Join(domains[start:end], ","),
+			"page[size]":            "100",
+		}
+		for params != nil {
+			resp, err := conn.Get("/tls/activations", &gofastly.RequestOptions{
+				Params:  params,
+				Headers: map[string]string{"Accept": "application/vnd.api+json"},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			var page tlsActivations
+			if err := decodeFastlyJSON(&page, resp.Body); err != nil {
+				return nil, err
+			}
+			for _, a := range page.Data {
+				ids = append(ids, a.ID)
+			}
+
+			params, err = nextPageParams(page.Links.Next)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+// nextPageParams returns the query parameters of a JSON:API next page link, or
+// nil when there is no next page.
+func nextPageParams(next string) (map[string]string, error) {
+	if next == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(next)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next page link %q: %s", next, err)
+	}
+	params := make(map[string]string)
+	for k, v := range u.Query() {
+		params[k] = v[0]
+	}
+	return params, nil
+}
+
+// backendHealthCheckInput sets the healthcheck of a backend. Unlike
+// go-fastly's UpdateBackendInput, an empty value is sent rather than omitted,
+// which detaches the healthcheck.
+type backendHealthCheckInput struct {
+	HealthCheck string `form:"healthcheck"`
+}
+
+// updateBackendHealthCheck attaches the named healthcheck to a backend, or
+// detaches any healthcheck when healthcheck is empty.
+func updateBackendHealthCheck(conn *gofastly.Client, service string, version int, backend, healthcheck string) error {
+	path := fmt.Sprintf("/service/%s/version/%d/backend/%s", service, version, backend)
+	resp, err := conn.PutForm(path, &backendHealthCheckInput{HealthCheck: healthcheck}, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// pool is a load balancing pool of origin servers. The pool itself is part of
+// a service version, like a backend, but its servers are not: they are managed
+// through the pool ID and take effect without a new version.
+type pool struct {
+	ID               string `mapstructure:"id" form:"-"`
+	Name             string `mapstructure:"name" form:"name,omitempty"`
+	Comment          string `mapstructure:"comment" form:"comment"`
+	Type             string `mapstructure:"type" form:"type,omitempty"`
+	Healthcheck      string `mapstructure:"healthcheck" form:"healthcheck"`
+	MaxConnDefault   uint   `mapstructure:"max_conn_default" form:"max_conn_default"`
+	ConnectTimeout   uint   `mapstructure:"connect_timeout" form:"connect_timeout"`
+	FirstByteTimeout uint   `mapstructure:"first_byte_timeout" form:"first_byte_timeout"`
+	Quorum           uint   `mapstructure:"quorum" form:"quorum"`
+}
+
+// listPools returns the pools of a service version.
+func listPools(conn *gofastly.Client, service string, version int) ([]*pool, error) {
+	resp, err := conn.Get(fmt.Sprintf("/service/%s/version/%d/pool", service, version), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pools []*pool
+	if err := decodeFastlyJSON(&pools, resp.Body); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+// createPool adds a pool to a service version.
+func createPool(conn *gofastly.Client, service string, version int, p *pool) error {
+	resp, err := conn.PostForm(fmt.Sprintf("/service/%s/version/%d/pool", service, version), p, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// updatePool updates the named pool on a service version in place, which
+// keeps its ID and so its servers.
+func updatePool(conn *gofastly.Client, service string, version int, name string, p *pool) error {
+	resp, err := conn.PutForm(versionObjectPath(service, version, "pool", name), p, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// deletePool removes the named pool from a service version.
+func deletePool(conn *gofastly.Client, service string, version int, name string) error {
+	resp, err := conn.Delete(versionObjectPath(service, version, "pool", name), nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// poolServer is an origin server in a pool.
+type poolServer struct {
+	ID       string               `mapstructure:"id" form:"-"`
+	Address  string               `mapstructure:"address" form:"address,omitempty"`
+	Port     uint                 `mapstructure:"port" form:"port"`
+	Weight   uint                 `mapstructure:"weight" form:"weight"`
+	Disabled gofastly.Compatibool `mapstructure:"disabled" form:"disabled"`
+	Comment  string               `mapstructure:"comment" form:"comment"`
+}
+
+// poolServerPath returns the API path of the servers of a pool, optionally
+// scoped to a single server.
+func poolServerPath(service, poolID string, serverID ...string) string {
+	path := fmt.Sprintf("/service/%s/pool/%s/server", service, poolID)
+	if len(serverID) > 0 {
+		path = fmt.Sprintf("%s/%s", path, serverID[0])
+	}
+	return path
+}
+
+// listPoolServers returns the servers of a pool.
+func listPoolServers(conn *gofastly.Client, service, poolID string) ([]*poolServer, error) {
+	resp, err := conn.Get(poolServerPath(service, poolID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []*poolServer
+	if err := decodeFastlyJSON(&servers, resp.Body); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// createPoolServer adds a server to a pool and returns it with its ID.
+func createPoolServer(conn *gofastly.Client, service, poolID string, s *poolServer) (*poolServer, error) {
+	resp, err := conn.PostForm(poolServerPath(service, poolID), s, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *poolServer
+	if err := decodeFastlyJSON(&created, resp.Body); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// updatePoolServer updates a server of a pool in place.
+func updatePoolServer(conn *gofastly.Client, service, poolID, serverID string, s *poolServer) error {
+	resp, err := conn.PutForm(poolServerPath(service, poolID, serverID), s, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// deletePoolServer removes a server from a pool.
+func deletePoolServer(conn *gofastly.Client, service, poolID, serverID string) error {
+	resp, err := conn.Delete(poolServerPath(service, poolID, serverID), nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
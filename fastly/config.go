@@ -1,17 +1,74 @@
 package fastly
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/hashicorp/go-cleanhttp"
 	gofastly "github.com/sethvargo/go-fastly"
 )
 
+// defaultManagementMarker is written to the comment of every service the
+// provider creates.
+const defaultManagementMarker = "Managed by Terraform"
+
 type Config struct {
 	ApiKey string
+
+	// Credentials used by logging endpoints that do not set their own.
+	DefaultS3AccessKey  string
+	DefaultS3SecretKey  string
+	DefaultGCSEmail     string
+	DefaultGCSSecretKey string
+
+	// A Google Cloud service account used by GCS logging endpoints that set
+	// neither their own credentials nor the GCS defaults above.
+	GCPEmail     string
+	GCPSecretKey string
+
+	// StrictTLS turns warnings about backends with weakened TLS into errors.
+	StrictTLS bool
+
+	// ManagementMarker is written to the comment of new services, and a
+	// service whose comment lacks it is reported as managed elsewhere.
+	ManagementMarker string
+
+	// DriftReport logs the set blocks that drifted from state on refresh.
+	DriftReport bool
+
+	// CABundle holds PEM certificates, or the path of a file of them, that
+	// are trusted for the Fastly API on top of the system roots.
+	CABundle string
+
+	// HTTPProxy is the URL of the proxy to reach the Fastly API through.
+	// When empty, HTTPS_PROXY and NO_PROXY select one.
+	HTTPProxy string
 }
 
 type FastlyClient struct {
 	conn *gofastly.Client
+
+	// loggingDefaults holds the provider-level credential defaults, keyed by
+	// logging block and then field name.
+	loggingDefaults map[string]map[string]string
+
+	// domains catches two fastly_service_v1 resources configuring the same
+	// domain.
+	domains domainRegistry
+
+	// strictTLS makes validateBackendTLS return errors instead of warnings.
+	strictTLS bool
+
+	// managementMarker marks the comments of services this provider manages.
+	managementMarker string
+
+	// driftReport makes resourceServiceV1Read log drifted blocks.
+	driftReport bool
 }
 
 func (c *Config) Client() (interface{}, error) {
@@ -25,7 +82,76 @@ func (c *Config) Client() (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	fconn.HTTPClient, err = c.httpClient()
+	if err != nil {
+		return nil, err
+	}
 
 	client.conn = fconn
+	client.strictTLS = c.StrictTLS
+	client.managementMarker = c.ManagementMarker
+	client.driftReport = c.DriftReport
+	client.loggingDefaults = c.loggingDefaults()
 	return &client, nil
 }
+
+// loggingDefaults returns the credential defaults for logging endpoints, keyed
+// by logging block and then field name. Inherited credentials are only held
+// by the client; endpoints relying on them keep their fields empty in state,
+// see omitLoggingDefaults.
+func (c *Config) loggingDefaults() map[string]map[string]string {
+	gcsEmail, gcsSecretKey := c.DefaultGCSEmail, c.DefaultGCSSecretKey
+	if gcsEmail == "" {
+		gcsEmail = c.GCPEmail
+	}
+	if gcsSecretKey == "" {
+		gcsSecretKey = c.GCPSecretKey
+	}
+
+	return map[string]map[string]string{
+		"s3logging": {
+			"s3_access_key": c.DefaultS3AccessKey,
+			"s3_secret_key": c.DefaultS3SecretKey,
+		},
+		"gcslogging": {
+			"email":      gcsEmail,
+			"secret_key": gcsSecretKey,
+		},
+	}
+}
+
+// httpClient returns the HTTP client for the Fastly API, configured like
+// go-fastly's default one apart from CABundle and HTTPProxy.
+func (c *Config) httpClient() (*http.Client, error) {
+	transport := cleanhttp.DefaultTransport()
+
+	if c.CABundle != "" {
+		bundle := []byte(c.CABundle)
+		if !strings.Contains(c.CABundle, "-----BEGIN") {
+			var err error
+			if bundle, err = ioutil.ReadFile(c.CABundle); err != nil {
+				return nil, fmt.Errorf("[Err] Error reading ca_bundle: %s", err)
+			}
+		}
+
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("[Err] ca_bundle contains no PEM certificates")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	}
+
+	if c.HTTPProxy != "" {
+		// The URL may hold the proxy's credentials, so it is not echoed.
+		proxy, err := url.Parse(c.HTTPProxy)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("[Err] http_proxy must be a URL, such as http://proxy.example.com:3128")
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
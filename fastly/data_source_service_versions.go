@@ -0,0 +1,148 @@
+package fastly
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceFastlyServiceVersions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFastlyServiceVersionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"service_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the Service to list versions of",
+			},
+			"active_only": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Only return the active version",
+			},
+			"since": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return versions created at or after this RFC 3339 timestamp",
+				ValidateFunc: validateRFC3339Timestamp,
+			},
+			"versions": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"number": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"comment": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"active": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"locked": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"deployed": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceFastlyServiceVersionsRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*FastlyClient).conn
+	serviceID := d.Get("service_id").(string)
+
+	log.Printf("[DEBUG] Reading versions for Fastly Service (%s)", serviceID)
+	versions, err := listServiceVersions(conn, serviceID)
+	if err != nil {
+		return fmt.Errorf("Error listing versions for Fastly Service (%s): %s", serviceID, err)
+	}
+
+	var since time.Time
+	if v, ok := d.GetOk("since"); ok {
+		// already checked by validateRFC3339Timestamp
+		since, _ = time.Parse(time.RFC3339, v.(string))
+	}
+
+	versions = filterServiceVersions(versions, d.Get("active_only").(bool), since)
+
+	d.SetId(serviceVersionsID(serviceID, versions))
+	if err := d.Set("versions", flattenServiceVersions(versions)); err != nil {
+		return fmt.Errorf("Error setting versions: %s", err)
+	}
+
+	return nil
+}
+
+// filterServiceVersions returns the versions matching the data source filters,
+// ordered by version number. A zero since disables the time filter. Versions
+// whose created_at cannot be parsed are kept rather than silently dropped.
+func filterServiceVersions(versions []*serviceVersion, activeOnly bool, since time.Time) []*serviceVersion {
+	var out []*serviceVersion
+	for _, v := range versions {
+		if activeOnly && !v.Active {
+			continue
+		}
+		if !since.IsZero() {
+			if created, err := time.Parse(time.RFC3339, v.CreatedAt); err == nil && created.Before(since) {
+				continue
+			}
+		}
+		out = append(out, v)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out
+}
+
+// serviceVersionsID combines the service ID with a hash of the version numbers
+// so the data source ID changes whenever a version is added.
+func serviceVersionsID(serviceID string, versions []*serviceVersion) string {
+	numbers := make([]string, 0, len(versions))
+	for _, v := range versions {
+		numbers = append(numbers, strconv.Itoa(v.Number))
+	}
+	return fmt.Sprintf("%s-%d", serviceID, hashcode.String(strings.Join(numbers, ",")))
+}
+
+func flattenServiceVersions(versions []*serviceVersion) []map[string]interface{} {
+	vl := make([]map[string]interface{}, 0, len(versions))
+	for _, v := range versions {
+		vl = append(vl, map[string]interface{}{
+			"number":     v.Number,
+			"comment":    v.Comment,
+			"active":     v.Active,
+			"locked":     v.Locked,
+			"deployed":   v.Deployed,
+			"created_at": v.CreatedAt,
+			"updated_at": v.UpdatedAt,
+		})
+	}
+	return vl
+}
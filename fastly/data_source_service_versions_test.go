@@ -0,0 +1,180 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestFilterServiceVersions(t *testing.T) {
+	v1 := &serviceVersion{Number: 1, CreatedAt: "2017-01-01T00:00:00Z"}
+	v2 := &serviceVersion{Number: 2, CreatedAt: "2017-03-01T00:00:00Z", Active: true, Locked: true}
+	v3 := &serviceVersion{Number: 3, CreatedAt: "2017-05-01T00:00:00Z"}
+	since, _ := time.Parse(time.RFC3339, "2017-02-01T00:00:00Z")
+
+	cases := []struct {
+		versions   []*serviceVersion
+		activeOnly bool
+		since      time.Time
+		expected   []*serviceVersion
+	}{
+		{
+			versions: []*serviceVersion{v3, v1, v2},
+			expected: []*serviceVersion{v1, v2, v3},
+		},
+		{
+			versions:   []*serviceVersion{v1, v2, v3},
+			activeOnly: true,
+			expected:   []*serviceVersion{v2},
+		},
+		{
+			versions: []*serviceVersion{v1, v2, v3},
+			since:    since,
+			expected: []*serviceVersion{v2, v3},
+		},
+		{
+			versions:   []*serviceVersion{v1, v3},
+			activeOnly: true,
+		},
+		{
+			versions: nil,
+		},
+	}
+
+	for _, c := range cases {
+		out := filterServiceVersions(c.versions, c.activeOnly, c.since)
+		if !reflect.DeepEqual(out, c.expected) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.expected, out)
+		}
+	}
+}
+
+func TestServiceVersionsID(t *testing.T) {
+	one := []*serviceVersion{&serviceVersion{Number: 1}}
+	two := []*serviceVersion{&serviceVersion{Number: 1}, &serviceVersion{Number: 2}}
+
+	if serviceVersionsID("abc", one) == serviceVersionsID("abc", two) {
+		t.Fatal("expected ID to change when a version is added")
+	}
+	if serviceVersionsID("abc", two) != serviceVersionsID("abc", two) {
+		t.Fatal("expected ID to be stable for the same versions")
+	}
+	if serviceVersionsID("abc", nil) == serviceVersionsID("def", nil) {
+		t.Fatal("expected ID to include the service ID")
+	}
+}
+
+func TestResourceFastlyFlattenServiceVersions(t *testing.T) {
+	out := flattenServiceVersions(nil)
+	if out == nil || len(out) != 0 {
+		t.Fatalf("expected an empty, non-nil list, got: %#v", out)
+	}
+
+	out = flattenServiceVersions([]*serviceVersion{
+		&serviceVersion{
+			Number:    2,
+			Comment:   "audit",
+			Active:    true,
+			Locked:    true,
+			Deployed:  true,
+			CreatedAt: "2017-03-01T00:00:00Z",
+			UpdatedAt: "2017-03-02T00:00:00Z",
+		},
+	})
+	expected := []map[string]interface{}{
+		map[string]interface{}{
+			"number":     2,
+			"comment":    "audit",
+			"active":     true,
+			"locked":     true,
+			"deployed":   true,
+			"created_at": "2017-03-01T00:00:00Z",
+			"updated_at": "2017-03-02T00:00:00Z",
+		},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", expected, out)
+	}
+}
+
+func TestAccFastlyServiceVersions(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccFastlyServiceVersionsConfig(name, domainName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.fastly_service_versions.all", "versions.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.fastly_service_versions.all", "versions.0.number", "1"),
+					resource.TestCheckResourceAttr(
+						"data.fastly_service_versions.all", "versions.0.active", "true"),
+					resource.TestCheckResourceAttr(
+						"data.fastly_service_versions.active", "versions.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.fastly_service_versions.none", "versions.#", "0"),
+					testAccFastlyServiceVersionsID("data.fastly_service_versions.all", "fastly_service_v1.foo"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFastlyServiceVersionsID(n, service string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		ds := s.RootModule().Resources[n]
+		svc := s.RootModule().Resources[service]
+		if ds == nil || svc == nil {
+			return fmt.Errorf("Not found: %s or %s", n, service)
+		}
+
+		if ds.Primary.Attributes["service_id"] != svc.Primary.ID {
+			return fmt.Errorf("Bad service_id, expected (%s), got (%s)", svc.Primary.ID, ds.Primary.Attributes["service_id"])
+		}
+
+		return nil
+	}
+}
+
+func testAccFastlyServiceVersionsConfig(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  force_destroy = true
+}
+
+data "fastly_service_versions" "all" {
+  service_id = "${fastly_service_v1.foo.id}"
+}
+
+data "fastly_service_versions" "active" {
+  service_id  = "${fastly_service_v1.foo.id}"
+  active_only = true
+}
+
+data "fastly_service_versions" "none" {
+  service_id = "${fastly_service_v1.foo.id}"
+  since      = "2999-01-01T00:00:00Z"
+}`, name, domain)
+}
@@ -0,0 +1,95 @@
+package fastly
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+func dataSourceFastlyTokenInfo() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFastlyTokenInfoRead,
+
+		Schema: map[string]*schema.Schema{
+			"customer_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the Fastly customer (account) the token belongs to",
+			},
+			"user_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the user the token belongs to",
+			},
+			"scopes": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The scopes granted to the token, such as global or purge_all",
+			},
+			"expires_at": &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the token expires, or empty if it does not",
+			},
+		},
+	}
+}
+
+func dataSourceFastlyTokenInfoRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*FastlyClient).conn
+
+	log.Printf("[DEBUG] Reading the Fastly API token in use")
+	token, err := getTokenSelf(conn)
+	if err != nil {
+		if httpErr, ok := err.(*gofastly.HTTPError); ok && tokenCannotIntrospect(httpErr.StatusCode) {
+			return fmt.Errorf("Error reading the Fastly API token: the token cannot describe itself, "+
+				"as is the case for legacy API keys; use an API token instead: %s", err)
+		}
+		return fmt.Errorf("Error reading the Fastly API token: %s", err)
+	}
+
+	if err := checkTokenExpiry(token, time.Now()); err != nil {
+		return err
+	}
+
+	d.SetId(token.ID)
+	d.Set("customer_id", token.CustomerID)
+	d.Set("user_id", token.UserID)
+	d.Set("expires_at", token.ExpiresAt)
+	if err := d.Set("scopes", strings.Fields(token.Scope)); err != nil {
+		return fmt.Errorf("Error setting scopes: %s", err)
+	}
+
+	return nil
+}
+
+// tokenCannotIntrospect reports whether a status returned by the token self
+// endpoint means the credential is not a token it can describe.
+func tokenCannotIntrospect(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusUnauthorized ||
+		status == http.StatusForbidden || status == http.StatusNotFound
+}
+
+// checkTokenExpiry returns an error for a token that expired before now.
+// Fastly may still describe an expired token, and module assertions relying
+// on it would then pass on stale data.
+func checkTokenExpiry(token *tokenInfo, now time.Time) error {
+	if token.ExpiresAt == "" {
+		return nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("Error parsing the expiry of the Fastly API token (%s): %s", token.ExpiresAt, err)
+	}
+	if expires.Before(now) {
+		return fmt.Errorf("The Fastly API token (%s) expired at %s", token.ID, token.ExpiresAt)
+	}
+	return nil
+}
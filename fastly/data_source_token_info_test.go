@@ -0,0 +1,119 @@
+package fastly
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestCheckTokenExpiry(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2017-06-01T00:00:00Z")
+
+	cases := []struct {
+		expiresAt string
+		errored   bool
+	}{
+		{expiresAt: ""},
+		{expiresAt: "2018-01-01T00:00:00Z"},
+		{expiresAt: "2017-01-01T00:00:00Z", errored: true},
+		{expiresAt: "not a time", errored: true},
+	}
+
+	for _, c := range cases {
+		err := checkTokenExpiry(&tokenInfo{ID: "abc", ExpiresAt: c.expiresAt}, now)
+		if (err != nil) != c.errored {
+			t.Fatalf("expires_at %q: expected error %t, got: %v", c.expiresAt, c.errored, err)
+		}
+	}
+}
+
+func TestDataSourceFastlyTokenInfoRead(t *testing.T) {
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /tokens/self": testFastlyJSON(`{
+			"id": "token-id",
+			"user_id": "user-id",
+			"customer_id": "customer-id",
+			"scope": "global purge_select",
+			"expires_at": "2999-01-01T00:00:00Z"
+		}`),
+	})
+	defer closeServer()
+
+	d := schema.TestResourceDataRaw(t, dataSourceFastlyTokenInfo().Schema, map[string]interface{}{})
+	if err := dataSourceFastlyTokenInfoRead(d, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if d.Id() != "token-id" {
+		t.Fatalf("expected ID token-id, got: %s", d.Id())
+	}
+	for k, v := range map[string]string{
+		"customer_id": "customer-id",
+		"user_id":     "user-id",
+		"expires_at":  "2999-01-01T00:00:00Z",
+	} {
+		if got := d.Get(k).(string); got != v {
+			t.Fatalf("expected %s %q, got: %q", k, v, got)
+		}
+	}
+	expected := []interface{}{"global", "purge_select"}
+	if scopes := d.Get("scopes").([]interface{}); !reflect.DeepEqual(scopes, expected) {
+		t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", expected, scopes)
+	}
+}
+
+func TestDataSourceFastlyTokenInfoRead_expired(t *testing.T) {
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /tokens/self": testFastlyJSON(`{"id": "token-id", "scope": "global", "expires_at": "2017-01-01T00:00:00Z"}`),
+	})
+	defer closeServer()
+
+	d := schema.TestResourceDataRaw(t, dataSourceFastlyTokenInfo().Schema, map[string]interface{}{})
+	err := dataSourceFastlyTokenInfoRead(d, client)
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("expected an expiry error, got: %v", err)
+	}
+}
+
+func TestDataSourceFastlyTokenInfoRead_legacyKey(t *testing.T) {
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /tokens/self": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"msg": "Provided credentials are missing or invalid"}`))
+		},
+	})
+	defer closeServer()
+
+	d := schema.TestResourceDataRaw(t, dataSourceFastlyTokenInfo().Schema, map[string]interface{}{})
+	err := dataSourceFastlyTokenInfoRead(d, client)
+	if err == nil || !strings.Contains(err.Error(), "legacy API keys") {
+		t.Fatalf("expected a legacy key error, got: %v", err)
+	}
+}
+
+func TestAccFastlyTokenInfo(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccFastlyTokenInfoConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.fastly_token_info.current", "customer_id"),
+					resource.TestCheckResourceAttrSet("data.fastly_token_info.current", "user_id"),
+					resource.TestCheckResourceAttrSet("data.fastly_token_info.current", "scopes.#"),
+				),
+			},
+		},
+	})
+}
+
+const testAccFastlyTokenInfoConfig = `
+data "fastly_token_info" "current" {}
+`
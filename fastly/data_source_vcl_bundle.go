@@ -0,0 +1,188 @@
+package fastly
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceFastlyVCLBundle() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFastlyVCLBundleRead,
+
+		Schema: map[string]*schema.Schema{
+			"paths": &schema.Schema{
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "Paths of the VCL files in the bundle",
+				ConflictsWith: []string{"directory"},
+			},
+			"directory": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "A directory whose .vcl files make up the bundle",
+				ConflictsWith: []string{"paths"},
+			},
+			"vcl": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"content": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"content_hash": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"main": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceFastlyVCLBundleRead(d *schema.ResourceData, meta interface{}) error {
+	var files []string
+	for _, p := range d.Get("paths").([]interface{}) {
+		files = append(files, p.(string))
+	}
+	if dir, ok := d.GetOk("directory"); ok {
+		matches, err := filepath.Glob(filepath.Join(dir.(string), "*.vcl"))
+		if err != nil {
+			return fmt.Errorf("Error listing VCL files in %s: %s", dir, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("No .vcl files found in %s", dir)
+		}
+		files = matches
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("One of paths or directory must be set")
+	}
+
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		log.Printf("[DEBUG] Reading VCL file %s", f)
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("Error reading VCL file: %s", err)
+		}
+		contents[f] = string(b)
+	}
+
+	vcls, err := buildVCLBundle(contents)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(vclBundleID(vcls))
+	if err := d.Set("vcl", vcls); err != nil {
+		return fmt.Errorf("Error setting vcl: %s", err)
+	}
+
+	return nil
+}
+
+// buildVCLBundle turns VCL file contents, keyed by path, into vcl blocks for
+// fastly_service_v1, ordered by name. Each file is named after its base name
+// without the .vcl extension. A file carrying Fastly's boilerplate macros is
+// the main VCL; the rest are includes.
+func buildVCLBundle(contents map[string]string) ([]map[string]interface{}, error) {
+	var vcls []map[string]interface{}
+	var list []interface{}
+	for path, content := range contents {
+		name := strings.TrimSuffix(filepath.Base(path), ".vcl")
+		main, err := checkVCLBoilerplate(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+
+		vcl := map[string]interface{}{
+			"name":         name,
+			"content":      content,
+			"content_hash": hashVCLContent(content),
+			"main":         main,
+		}
+		vcls = append(vcls, vcl)
+		list = append(list, vcl)
+	}
+
+	sort.Slice(vcls, func(i, j int) bool { return vcls[i]["name"].(string) < vcls[j]["name"].(string) })
+	if err := validateVCLList(list); err != nil {
+		return nil, err
+	}
+	return vcls, nil
+}
+
+// vclBoilerplateMacros are the #FASTLY macros Fastly expands into each
+// subroutine of a main VCL. See
+// https://docs.fastly.com/guides/vcl/mixing-and-matching-fastly-vcl-with-custom-vcl
+var vclBoilerplateMacros = []string{"recv", "hash", "hit", "miss", "pass", "fetch", "error", "deliver", "log"}
+
+var vclMacroRegexp = regexp.MustCompile(`(?m)^\s*#FASTLY\s+(\w+)`)
+
+// checkVCLBoilerplate reports whether VCL content is a main VCL, meaning it
+// carries Fastly's boilerplate macros. A main VCL must carry every macro
+// exactly once, and an include carries none.
+func checkVCLBoilerplate(content string) (bool, error) {
+	found := make(map[string]int)
+	for _, m := range vclMacroRegexp.FindAllStringSubmatch(content, -1) {
+		found[strings.ToLower(m[1])]++
+	}
+	if len(found) == 0 {
+		return false, nil
+	}
+
+	var problems []string
+	for _, macro := range vclBoilerplateMacros {
+		switch found[macro] {
+		case 0:
+			problems = append(problems, fmt.Sprintf("missing #FASTLY %s", macro))
+		case 1:
+		default:
+			problems = append(problems, fmt.Sprintf("#FASTLY %s appears %d times", macro, found[macro]))
+		}
+		delete(found, macro)
+	}
+	var unknown []string
+	for macro := range found {
+		unknown = append(unknown, macro)
+	}
+	sort.Strings(unknown)
+	for _, macro := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown macro #FASTLY %s", macro))
+	}
+
+	if len(problems) > 0 {
+		return false, fmt.Errorf("incomplete Fastly boilerplate: %s", strings.Join(problems, ", "))
+	}
+	return true, nil
+}
+
+// vclBundleID hashes the names and content hashes of the bundle so the ID
+// changes whenever a file does.
+func vclBundleID(vcls []map[string]interface{}) string {
+	parts := make([]string, 0, len(vcls))
+	for _, v := range vcls {
+		parts = append(parts, fmt.Sprintf("%s:%s", v["name"], v["content_hash"]))
+	}
+	return fmt.Sprintf("%d", hashcode.String(strings.Join(parts, ",")))
+}
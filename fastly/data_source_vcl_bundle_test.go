@@ -0,0 +1,196 @@
+package fastly
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const testVCLBundleMain = `sub vcl_recv {
+#FASTLY recv
+  include "errors";
+}
+sub vcl_hash {
+#FASTLY hash
+}
+sub vcl_hit {
+#FASTLY hit
+}
+sub vcl_miss {
+#FASTLY miss
+}
+sub vcl_pass {
+#FASTLY pass
+}
+sub vcl_fetch {
+#FASTLY fetch
+}
+sub vcl_error {
+#FASTLY error
+}
+sub vcl_deliver {
+#FASTLY deliver
+}
+sub vcl_log {
+#FASTLY log
+}
+`
+
+const testVCLBundleInclude = `sub vcl_error_page {
+  set obj.http.Content-Type = "text/html";
+}
+`
+
+func TestCheckVCLBoilerplate(t *testing.T) {
+	cases := []struct {
+		content string
+		main    bool
+		err     string
+	}{
+		{
+			content: testVCLBundleMain,
+			main:    true,
+		},
+		{
+			content: testVCLBundleInclude,
+		},
+		{
+			content: strings.Replace(testVCLBundleMain, "#FASTLY log", "", 1),
+			err:     "missing #FASTLY log",
+		},
+		{
+			content: testVCLBundleMain + "#FASTLY recv\n",
+			err:     "#FASTLY recv appears 2 times",
+		},
+		{
+			content: testVCLBundleMain + "  #FASTLY bogus\n",
+			err:     "unknown macro #FASTLY bogus",
+		},
+	}
+
+	for _, c := range cases {
+		main, err := checkVCLBoilerplate(c.content)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error containing %q, got: %v", c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if main != c.main {
+			t.Fatalf("expected main to be %t for:\n%s", c.main, c.content)
+		}
+	}
+}
+
+func TestBuildVCLBundle(t *testing.T) {
+	vcls, err := buildVCLBundle(map[string]string{
+		"lib/main.vcl":   testVCLBundleMain,
+		"lib/errors.vcl": testVCLBundleInclude,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(vcls) != 2 || vcls[0]["name"] != "errors" || vcls[1]["name"] != "main" {
+		t.Fatalf("expected errors and main VCLs in name order, got: %#v", vcls)
+	}
+	if vcls[0]["main"].(bool) || !vcls[1]["main"].(bool) {
+		t.Fatalf("expected only main to be the main VCL, got: %#v", vcls)
+	}
+
+	// The hash must match what the resource keeps in state for the content.
+	stateFunc := resourceServiceV1().Schema["vcl"].Elem.(*schema.Resource).Schema["content"].StateFunc
+	for _, v := range vcls {
+		if v["content_hash"] != stateFunc(v["content"]) {
+			t.Fatalf("content_hash of %s does not match the resource state", v["name"])
+		}
+	}
+
+	errCases := []struct {
+		contents map[string]string
+		err      string
+	}{
+		{
+			contents: map[string]string{
+				"a/errors.vcl": testVCLBundleInclude,
+				"b/errors.vcl": testVCLBundleInclude,
+				"main.vcl":     testVCLBundleMain,
+			},
+			err: `more than one VCL configuration is named "errors"`,
+		},
+		{
+			contents: map[string]string{
+				"main.vcl":  testVCLBundleMain,
+				"other.vcl": testVCLBundleMain,
+			},
+			err: "more than one VCL configuration with main = true",
+		},
+		{
+			contents: map[string]string{
+				"errors.vcl": testVCLBundleInclude,
+			},
+			err: "one of them should have main = true",
+		},
+		{
+			contents: map[string]string{
+				"main.vcl": strings.Replace(testVCLBundleMain, "#FASTLY hit", "", 1),
+			},
+			err: "main.vcl: incomplete Fastly boilerplate: missing #FASTLY hit",
+		},
+	}
+
+	for _, c := range errCases {
+		_, err := buildVCLBundle(c.contents)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expected error containing %q, got: %v", c.err, err)
+		}
+	}
+}
+
+func TestDataSourceFastlyVCLBundleRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vcl-bundle")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.vcl":   testVCLBundleMain,
+		"errors.vcl": testVCLBundleInclude,
+		"README.md":  "not VCL",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("error writing %s: %s", name, err)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceFastlyVCLBundle().Schema, map[string]interface{}{
+		"directory": dir,
+	})
+	if err := dataSourceFastlyVCLBundleRead(d, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d.Id() == "" {
+		t.Fatal("expected an ID to be set")
+	}
+	if n := d.Get("vcl.#").(int); n != 2 {
+		t.Fatalf("expected 2 VCLs from the directory, got %d", n)
+	}
+	if d.Get("vcl.0.name") != "errors" || d.Get("vcl.1.main") != true {
+		t.Fatalf("unexpected bundle: %#v", d.Get("vcl"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceFastlyVCLBundle().Schema, map[string]interface{}{
+		"paths": []interface{}{filepath.Join(dir, "errors.vcl")},
+	})
+	if err := dataSourceFastlyVCLBundleRead(d, nil); err == nil {
+		t.Fatal("expected a bundle without a main VCL to fail")
+	}
+}
@@ -0,0 +1,95 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// domainRegistry records which fastly_service_v1 resource claims each domain
+// during a Terraform run. Fastly only rejects a domain used by two services
+// once the second service is being built, which can leave it half configured;
+// the registry lets the provider refuse the change before any API call.
+//
+// Resources claim their domains as they are refreshed and applied, so a
+// conflict is found as soon as both resources have been seen in the run. A
+// claim made during a refresh holds the domains the service has in Fastly,
+// and is only given up when that service is updated or deleted. A domain
+// moving between services is therefore refused until the service losing it
+// has been updated, just as Fastly would refuse it.
+type domainRegistry struct {
+	mu sync.Mutex
+	// owners maps each lower-cased domain to the key of the resource that
+	// claimed it, and labels maps those keys to a description for errors.
+	owners map[string]string
+	labels map[string]string
+}
+
+// claim records domains for owner, replacing its previous claims. It fails,
+// recording nothing, if another owner already claims any of them.
+func (r *domainRegistry) claim(owner, label string, domains []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.owners == nil {
+		r.owners = make(map[string]string)
+		r.labels = make(map[string]string)
+	}
+
+	var conflicts []string
+	for _, domain := range domains {
+		if other, ok := r.owners[strings.ToLower(domain)]; ok && other != owner {
+			conflicts = append(conflicts, fmt.Sprintf("domain %q is already configured on %s", domain, r.labels[other]))
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("%s: %s", label, strings.Join(conflicts, "; "))
+	}
+
+	r.releaseLocked(owner)
+	for _, domain := range domains {
+		r.owners[strings.ToLower(domain)] = owner
+	}
+	r.labels[owner] = label
+	return nil
+}
+
+// release drops every claim held by owner.
+func (r *domainRegistry) release(owner string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.releaseLocked(owner)
+}
+
+func (r *domainRegistry) releaseLocked(owner string) {
+	for domain, o := range r.owners {
+		if o == owner {
+			delete(r.owners, domain)
+		}
+	}
+	delete(r.labels, owner)
+}
+
+// serviceV1Domains returns the domain names configured on a service.
+func serviceV1Domains(d *schema.ResourceData) []string {
+	var domains []string
+	for _, dRaw := range d.Get("domain").(*schema.Set).List() {
+		domains = append(domains, dRaw.(map[string]interface{})["name"].(string))
+	}
+	for _, n := range d.Get("domains").(*schema.Set).List() {
+		domains = append(domains, n.(string))
+	}
+	return domains
+}
+
+// serviceV1DomainLabel describes a service in domain conflict errors.
+func serviceV1DomainLabel(d *schema.ResourceData) string {
+	if d.Id() == "" {
+		return fmt.Sprintf("new service %q", d.Get("name").(string))
+	}
+	return fmt.Sprintf("service %q (%s)", d.Get("name").(string), d.Id())
+}
@@ -0,0 +1,41 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDomainRegistry(t *testing.T) {
+	var r domainRegistry
+
+	if err := r.claim("svc-a", `service "a"`, []string{"example.com", "www.example.com"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Claiming again replaces the owner's claims
+	if err := r.claim("svc-a", `service "a"`, []string{"example.com"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := r.claim("svc-b", `service "b"`, []string{"www.example.com"}); err != nil {
+		t.Fatalf("expected www.example.com to be released by svc-a, got: %s", err)
+	}
+
+	// Domains are compared case-insensitively
+	err := r.claim("svc-c", `service "c"`, []string{"EXAMPLE.com", "other.example.com"})
+	if err == nil {
+		t.Fatal("expected a conflict on example.com")
+	}
+	if !strings.Contains(err.Error(), `domain "EXAMPLE.com" is already configured on service "a"`) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// A failed claim records nothing
+	if err := r.claim("svc-d", `service "d"`, []string{"other.example.com"}); err != nil {
+		t.Fatalf("expected other.example.com to be unclaimed, got: %s", err)
+	}
+
+	r.release("svc-a")
+	if err := r.claim("svc-c", `service "c"`, []string{"example.com"}); err != nil {
+		t.Fatalf("expected example.com to be released, got: %s", err)
+	}
+}
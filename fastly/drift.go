@@ -0,0 +1,166 @@
+package fastly
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// blockDrift lists the elements of a set block, by name, that differ between
+// the prior state and the configuration read from Fastly.
+type blockDrift struct {
+	Block   string
+	Changed []string
+	Added   []string
+	Removed []string
+}
+
+func (b blockDrift) empty() bool {
+	return len(b.Changed) == 0 && len(b.Added) == 0 && len(b.Removed) == 0
+}
+
+// snapshotServiceV1Blocks returns the elements of every set block in d.
+func snapshotServiceV1Blocks(d *schema.ResourceData) map[string][]interface{} {
+	blocks := make(map[string][]interface{}, len(serviceV1SetBlocks))
+	for _, block := range serviceV1SetBlocks {
+		if set, ok := d.Get(block).(*schema.Set); ok {
+			blocks[block] = set.List()
+		}
+	}
+	return blocks
+}
+
+// serviceV1Drift compares two snapshots of a service's set blocks and returns
+// the blocks that differ semantically, in serviceV1SetBlocks order. Unlike
+// the set hashes, it ignores the order of list elements and treats fields at
+// their default or zero value as unset, so only real configuration changes
+// are reported.
+func serviceV1Drift(prior, remote map[string][]interface{}) []blockDrift {
+	s := resourceServiceV1().Schema
+
+	var drifts []blockDrift
+	for _, block := range serviceV1SetBlocks {
+		if drift := diffBlock(block, s[block], prior[block], remote[block]); !drift.empty() {
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts
+}
+
+// logServiceV1Drift logs one line per block of d that drifted from prior, for
+// scheduled jobs to grep for, e.g.
+//
+//	[WARN] fastly drift: service=abc123 block=backend changed=["origin"] added=[] removed=[]
+func logServiceV1Drift(d *schema.ResourceData, prior map[string][]interface{}) {
+	for _, drift := range serviceV1Drift(prior, snapshotServiceV1Blocks(d)) {
+		log.Printf("[WARN] fastly drift: service=%s block=%s changed=%q added=%q removed=%q",
+			d.Id(), drift.Block, drift.Changed, drift.Added, drift.Removed)
+	}
+}
+
+// diffBlock compares the prior and remote elements of one block, matching
+// them by name, or by value for blocks of plain strings.
+func diffBlock(block string, attr *schema.Schema, prior, remote []interface{}) blockDrift {
+	drift := blockDrift{Block: block}
+	p := normalizeBlockElements(attr, prior)
+	r := normalizeBlockElements(attr, remote)
+
+	for name, pv := range p {
+		rv, ok := r[name]
+		switch {
+		case !ok:
+			drift.Removed = append(drift.Removed, name)
+		case !reflect.DeepEqual(pv, rv):
+			drift.Changed = append(drift.Changed, name)
+		}
+	}
+	for name := range r {
+		if _, ok := p[name]; !ok {
+			drift.Added = append(drift.Added, name)
+		}
+	}
+
+	sort.Strings(drift.Changed)
+	sort.Strings(drift.Added)
+	sort.Strings(drift.Removed)
+	return drift
+}
+
+// normalizeBlockElements returns the normalized elements of a block keyed by
+// name.
+func normalizeBlockElements(attr *schema.Schema, elements []interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(elements))
+	r, ok := attr.Elem.(*schema.Resource)
+	for _, e := range elements {
+		if !ok {
+			out[fmt.Sprint(e)] = fmt.Sprint(e)
+			continue
+		}
+		m, _ := e.(map[string]interface{})
+		name, _ := m["name"].(string)
+		out[name] = normalizeElement(r.Schema, m)
+	}
+	return out
+}
+
+// normalizeElement returns the configurable fields of an element that are
+// set to something other than their default or zero value.
+func normalizeElement(s map[string]*schema.Schema, element map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for k, attr := range s {
+		if attr.Computed && !attr.Optional {
+			continue
+		}
+		if v := normalizeValue(attr, element[k]); v != nil {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// normalizeValue returns nil for unset, default and zero values, a sorted
+// list for sets and lists, and the text of any other value, so that 80 and
+// "80" compare equal. Strings are passed through the attribute's StateFunc,
+// so for example condition types compare regardless of case.
+func normalizeValue(attr *schema.Schema, v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *schema.Set:
+		return normalizeList(attr, v.List())
+	case []interface{}:
+		return normalizeList(attr, v)
+	case string:
+		if v != "" && attr.StateFunc != nil {
+			return attr.StateFunc(v)
+		}
+	}
+
+	if attr.Default != nil && fmt.Sprint(v) == fmt.Sprint(attr.Default) {
+		return nil
+	}
+	if reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface()) {
+		return nil
+	}
+	return fmt.Sprint(v)
+}
+
+func normalizeList(attr *schema.Schema, list []interface{}) interface{} {
+	var elems []string
+	for _, e := range list {
+		if r, ok := attr.Elem.(*schema.Resource); ok {
+			m, _ := e.(map[string]interface{})
+			elems = append(elems, fmt.Sprint(normalizeElement(r.Schema, m)))
+		} else {
+			elems = append(elems, fmt.Sprint(e))
+		}
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+	sort.Strings(elems)
+	return elems
+}
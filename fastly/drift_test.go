@@ -0,0 +1,77 @@
+package fastly
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestServiceV1Drift(t *testing.T) {
+	prior := map[string][]interface{}{
+		"backend": {
+			map[string]interface{}{"name": "origin", "address": "origin.example.com", "port": 80},
+			map[string]interface{}{"name": "api", "address": "api.example.com"},
+			map[string]interface{}{"name": "old", "address": "old.example.com"},
+		},
+		"gzip": {
+			map[string]interface{}{"name": "compress", "extensions": schema.NewSet(schema.HashString, []interface{}{"css", "js"})},
+		},
+		"condition": {
+			map[string]interface{}{"name": "is_api", "statement": "req.url ~ \"^/api/\"\n", "type": "request"},
+		},
+		"domains": {"example.com", "www.example.com"},
+	}
+	remote := map[string][]interface{}{
+		"backend": {
+			// Default port and unset fields at their zero value are not drift
+			map[string]interface{}{"name": "origin", "address": "origin.example.com", "ssl_check_cert": true, "weight": 100},
+			map[string]interface{}{"name": "api", "address": "api2.example.com"},
+			map[string]interface{}{"name": "new", "address": "new.example.com"},
+		},
+		"gzip": {
+			map[string]interface{}{"name": "compress", "extensions": schema.NewSet(schema.HashString, []interface{}{"js", "css"})},
+		},
+		"condition": {
+			map[string]interface{}{"name": "is_api", "statement": "req.url ~ \"^/api/\"", "type": "REQUEST"},
+		},
+		"domains": {"www.example.com", "example.org"},
+	}
+
+	expected := []blockDrift{
+		{Block: "domains", Added: []string{"example.org"}, Removed: []string{"example.com"}},
+		{Block: "backend", Changed: []string{"api"}, Added: []string{"new"}, Removed: []string{"old"}},
+	}
+	if got := serviceV1Drift(prior, remote); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected drift %#v, got: %#v", expected, got)
+	}
+
+	if got := serviceV1Drift(remote, remote); len(got) != 0 {
+		t.Fatalf("expected no drift against itself, got: %#v", got)
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	port := &schema.Schema{Type: schema.TypeInt, Default: 80}
+	cases := []struct {
+		attr     *schema.Schema
+		a, b     interface{}
+		expected bool
+	}{
+		{port, 80, nil, true},
+		{port, 80, "80", true},
+		{port, 443, 80, false},
+		{&schema.Schema{Type: schema.TypeString}, "", nil, true},
+		{&schema.Schema{Type: schema.TypeBool}, false, nil, true},
+		{&schema.Schema{Type: schema.TypeList, Elem: &schema.Schema{Type: schema.TypeString}}, []interface{}{"a", "b"}, []interface{}{"b", "a"}, true},
+		{&schema.Schema{Type: schema.TypeList, Elem: &schema.Schema{Type: schema.TypeString}}, []interface{}{}, nil, true},
+		{&schema.Schema{Type: schema.TypeString, StateFunc: hashVCLContent}, "sub vcl_recv {}", "sub vcl_recv {}", true},
+		{&schema.Schema{Type: schema.TypeString, StateFunc: hashVCLContent}, "sub vcl_recv {}", "sub vcl_fetch {}", false},
+	}
+
+	for _, c := range cases {
+		if got := reflect.DeepEqual(normalizeValue(c.attr, c.a), normalizeValue(c.attr, c.b)); got != c.expected {
+			t.Errorf("%#v and %#v: expected equal %t, got %t", c.a, c.b, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Package adapter holds the provider's own representation of the Fastly
+// objects that the fastly_service_v1 resource manages through go-fastly.
+//
+// Each type converts to and from its go-fastly counterpart in exactly one
+// place, so that a renamed or retyped go-fastly field breaks this package
+// (and its round-trip tests) rather than silently changing what the resource
+// sends or stores. Logging endpoints are decoded from Fastly's response here,
+// in a single pass, including the fields go-fastly leaves out and the endpoint
+// types it does not cover at all. The resource code works only with the types
+// defined here.
+//
+// Every type has a Form method returning the form body it is created with,
+// for requests the adapter does not make itself, such as updating an existing
+// object to adopt it.
+package adapter
@@ -0,0 +1,286 @@
+package adapter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// apiMetadata are fields Fastly reports on every object that identify or
+// timestamp it rather than configure it.
+var apiMetadata = map[string]bool{
+	"ServiceID": true,
+	"Version":   true,
+	"CreatedAt": true,
+	"UpdatedAt": true,
+	"DeletedAt": true,
+}
+
+// TestRoundTrip fills every field of each go-fastly object, converts it to the
+// provider's type and back to its create input, and checks that no field was
+// lost or altered on the way. A field added to or renamed in go-fastly fails
+// here until the conversions handle it.
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		remote interface{}
+		// convert returns the provider object and its create input.
+		convert func(interface{}) (interface{}, interface{})
+	}{
+		{
+			remote: &gofastly.Backend{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				b := backendFromAPI(r.(*gofastly.Backend))
+				return b, b.createInput("service", 3)
+			},
+		},
+		{
+			remote: &gofastly.Header{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				h := headerFromAPI(r.(*gofastly.Header))
+				return h, h.createInput("service", 3)
+			},
+		},
+		{
+			remote: &gofastly.Condition{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				c := conditionFromAPI(r.(*gofastly.Condition))
+				return c, c.createInput("service", 3)
+			},
+		},
+		{
+			remote: &s3Remote{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				s := s3FromAPI(r.(*s3Remote))
+				return s, s.createInput("service", 3)
+			},
+		},
+		{
+			remote: &gofastly.Papertrail{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				p := papertrailFromAPI(r.(*gofastly.Papertrail))
+				return p, p.createInput("service", 3)
+			},
+		},
+		{
+			remote: &gofastly.Sumologic{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				s := sumologicFromAPI(r.(*gofastly.Sumologic))
+				return s, s.createInput("service", 3)
+			},
+		},
+		{
+			remote: &gcsRemote{},
+			convert: func(r interface{}) (interface{}, interface{}) {
+				g := gcsFromAPI(r.(*gcsRemote))
+				return g, g.createInput("service", 3)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		rv := reflect.ValueOf(c.remote).Elem()
+		fillFields(rv)
+
+		local, input := c.convert(c.remote)
+		lv := reflect.ValueOf(local).Elem()
+		iv := reflect.ValueOf(input).Elem()
+
+		// Every field Fastly reports is carried by the provider's type.
+		for _, name := range fieldNames(rv.Type()) {
+			if apiMetadata[name] {
+				continue
+			}
+			if err := compareField(lv, name, rv.FieldByName(name)); err != nil {
+				t.Errorf("%s: reading: %s", rv.Type(), err)
+			}
+		}
+
+		// The provider's type has no field Fastly does not report.
+		for i := 0; i < lv.NumField(); i++ {
+			name := lv.Type().Field(i).Name
+			if !rv.FieldByName(name).IsValid() {
+				t.Errorf("%s: %s has no counterpart on %s", lv.Type(), name, rv.Type())
+			}
+		}
+
+		// Every field of the create input is sent as Fastly reported it.
+		if s := iv.FieldByName("Service").String(); s != "service" {
+			t.Errorf("%s: expected Service %q, got %q", iv.Type(), "service", s)
+		}
+		if v := iv.FieldByName("Version").Int(); v != 3 {
+			t.Errorf("%s: expected Version 3, got %d", iv.Type(), v)
+		}
+		for i := 0; i < iv.NumField(); i++ {
+			name := iv.Type().Field(i).Name
+			if name == "Service" || apiMetadata[name] {
+				continue
+			}
+			rf := rv.FieldByName(name)
+			if !rf.IsValid() {
+				t.Errorf("%s: %s has no counterpart on %s", iv.Type(), name, rv.Type())
+				continue
+			}
+			if err := compareField(iv, name, rf); err != nil {
+				t.Errorf("%s: creating: %s", iv.Type(), err)
+			}
+		}
+	}
+}
+
+// A priority of 0 is sent to Fastly rather than left for it to default.
+func TestCreateCondition_zeroPriority(t *testing.T) {
+	var priority []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/service/service/version/3/condition" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		priority = r.PostForm["priority"]
+		fmt.Fprint(w, `{"name": "first"}`)
+	}))
+	defer ts.Close()
+
+	conn, err := gofastly.NewClientForEndpoint("test", ts.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := &Condition{Name: "first", Statement: "req.url", Type: "REQUEST", Priority: 0}
+	if err := CreateCondition(conn, "service", 3, c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(priority, []string{"0"}) {
+		t.Fatalf("expected priority 0 to be sent, got: %v", priority)
+	}
+}
+
+// The fields go-fastly does not decode are read from the same response as the
+// rest of the endpoint, timestamps included.
+func TestListS3s_singleRequest(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != "GET" || r.URL.Path != "/service/service/version/3/logging/s3" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[{"name": "logs", "period": "3600", "public_key": "key", "file_max_bytes": "1048576", "created_at": "2017-06-01T10:00:00Z"}]`)
+	}))
+	defer ts.Close()
+
+	conn, err := gofastly.NewClientForEndpoint("test", ts.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	s3s, err := ListS3s(conn, "service", 3)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []*S3{{Name: "logs", Period: 3600, PublicKey: "key", FileMaxBytes: 1048576}}
+	if !reflect.DeepEqual(s3s, expected) {
+		t.Fatalf("expected %#v, got %#v", expected[0], s3s[0])
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+}
+
+// The format version and public key are sent with the rest of a new GCS
+// endpoint rather than set on it afterwards.
+func TestCreateGCS_singleRequest(t *testing.T) {
+	var forms []map[string][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/service/service/version/3/logging/gcs" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		forms = append(forms, r.PostForm)
+		fmt.Fprint(w, `{"name": "logs"}`)
+	}))
+	defer ts.Close()
+
+	conn, err := gofastly.NewClientForEndpoint("test", ts.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	g := &GCS{Name: "logs", Bucket: "bucket", FormatVersion: 2, PublicKey: "key"}
+	if err := CreateGCS(conn, "service", 3, g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(forms) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(forms))
+	}
+	for field, want := range map[string]string{"name": "logs", "bucket_name": "bucket", "format_version": "2", "public_key": "key"} {
+		if got := forms[0][field]; !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("expected %s %q, got %v", field, want, got)
+		}
+	}
+}
+
+// fieldNames returns the names of the fields of struct type t, including those
+// promoted from embedded structs, such as the go-fastly type that a remote
+// type extends with the fields go-fastly does not decode.
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			names = append(names, fieldNames(f.Type)...)
+			continue
+		}
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// fillFields sets every configuration field of v to a value that is not the
+// zero value and differs between fields, so a field mapped onto the wrong
+// counterpart cannot pass unnoticed.
+func fillFields(v reflect.Value) {
+	for i, name := range fieldNames(v.Type()) {
+		if apiMetadata[name] {
+			continue
+		}
+		f := v.FieldByName(name)
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(name)
+		case reflect.Bool:
+			f.SetBool(true)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(int64(i + 1))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.SetUint(uint64(i + 1))
+		case reflect.Slice:
+			f.Set(reflect.Append(f, reflect.ValueOf(name).Convert(f.Type().Elem())))
+		default:
+			panic(fmt.Sprintf("fillFields: unhandled field %s of kind %s", name, f.Kind()))
+		}
+	}
+}
+
+// compareField checks that the named field of v holds the same value as want,
+// allowing for differences in numeric and string types.
+func compareField(v reflect.Value, name string, want reflect.Value) error {
+	f := v.FieldByName(name)
+	if !f.IsValid() {
+		return fmt.Errorf("%s of %s has no counterpart on %s", name, want.Type(), v.Type())
+	}
+
+	got := f.Interface()
+	if cb, ok := got.(*gofastly.Compatibool); ok && cb != nil {
+		got = bool(*cb)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want.Interface()) {
+		return fmt.Errorf("%s did not survive the conversion: got %v, expected %v", name, got, want.Interface())
+	}
+	return nil
+}
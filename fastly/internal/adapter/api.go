@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// loggingPath returns the API path of the logging endpoints of the given type
+// on a service version, optionally scoped to a single named endpoint.
+func loggingPath(service string, version int, endpoint string, name ...string) string {
+	path := fmt.Sprintf("/service/%s/version/%d/logging/%s", service, version, endpoint)
+	if len(name) > 0 {
+		path = fmt.Sprintf("%s/%s", path, name[0])
+	}
+	return path
+}
+
+// getJSON decodes the response to a GET of path into out the way go-fastly
+// does: weakly typed, as Fastly returns many numeric and boolean fields as
+// strings, and with timestamps parsed.
+func getJSON(conn *gofastly.Client, path string, out interface{}) error {
+	resp, err := conn.Get(path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var parsed interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return err
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       stringToTime,
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(parsed)
+}
+
+// stringToTime parses the RFC 3339 timestamps Fastly reports, such as
+// created_at, into go-fastly's time fields.
+func stringToTime(f, t reflect.Type, data interface{}) (interface{}, error) {
+	if f.Kind() != reflect.String || t != reflect.TypeOf(time.Time{}) {
+		return data, nil
+	}
+	return time.Parse(time.RFC3339, data.(string))
+}
+
+// postForm creates an object by posting form to path. form is encoded using
+// its `form` struct tags.
+func postForm(conn *gofastly.Client, path string, form interface{}) error {
+	resp, err := conn.PostForm(path, form, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// deletePath deletes the object at path.
+func deletePath(conn *gofastly.Client, path string) error {
+	resp, err := conn.Delete(path, nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,134 @@
+package adapter
+
+import (
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// Backend is a Fastly backend. Timeouts are in milliseconds.
+type Backend struct {
+	Name                string
+	Address             string
+	Port                int
+	ConnectTimeout      int
+	MaxConn             int
+	ErrorThreshold      int
+	FirstByteTimeout    int
+	BetweenBytesTimeout int
+	AutoLoadbalance     bool
+	Weight              int
+	RequestCondition    string
+	HealthCheck         string
+	Shield              string
+	UseSSL              bool
+	SSLCheckCert        bool
+	SSLCACert           string
+	SSLClientCert       string
+	SSLClientKey        string
+	SSLHostname         string
+	SSLCertHostname     string
+	SSLSNIHostname      string
+	MinTLSVersion       string
+	MaxTLSVersion       string
+	SSLCiphers          []string
+
+	// Hostname is reported by Fastly and never sent.
+	Hostname string
+}
+
+func backendFromAPI(b *gofastly.Backend) *Backend {
+	return &Backend{
+		Name:                b.Name,
+		Address:             b.Address,
+		Port:                int(b.Port),
+		ConnectTimeout:      int(b.ConnectTimeout),
+		MaxConn:             int(b.MaxConn),
+		ErrorThreshold:      int(b.ErrorThreshold),
+		FirstByteTimeout:    int(b.FirstByteTimeout),
+		BetweenBytesTimeout: int(b.BetweenBytesTimeout),
+		AutoLoadbalance:     b.AutoLoadbalance,
+		Weight:              int(b.Weight),
+		RequestCondition:    b.RequestCondition,
+		HealthCheck:         b.HealthCheck,
+		Shield:              b.Shield,
+		UseSSL:              b.UseSSL,
+		SSLCheckCert:        b.SSLCheckCert,
+		SSLCACert:           b.SSLCACert,
+		SSLClientCert:       b.SSLClientCert,
+		SSLClientKey:        b.SSLClientKey,
+		SSLHostname:         b.SSLHostname,
+		SSLCertHostname:     b.SSLCertHostname,
+		SSLSNIHostname:      b.SSLSNIHostname,
+		MinTLSVersion:       b.MinTLSVersion,
+		MaxTLSVersion:       b.MaxTLSVersion,
+		SSLCiphers:          b.SSLCiphers,
+		Hostname:            b.Hostname,
+	}
+}
+
+func (b *Backend) createInput(service string, version int) *gofastly.CreateBackendInput {
+	return &gofastly.CreateBackendInput{
+		Service:             service,
+		Version:             version,
+		Name:                b.Name,
+		Address:             b.Address,
+		Port:                uint(b.Port),
+		ConnectTimeout:      uint(b.ConnectTimeout),
+		MaxConn:             uint(b.MaxConn),
+		ErrorThreshold:      uint(b.ErrorThreshold),
+		FirstByteTimeout:    uint(b.FirstByteTimeout),
+		BetweenBytesTimeout: uint(b.BetweenBytesTimeout),
+		AutoLoadbalance:     gofastly.CBool(b.AutoLoadbalance),
+		Weight:              uint(b.Weight),
+		RequestCondition:    b.RequestCondition,
+		HealthCheck:         b.HealthCheck,
+		Shield:              b.Shield,
+		UseSSL:              gofastly.CBool(b.UseSSL),
+		SSLCheckCert:        gofastly.CBool(b.SSLCheckCert),
+		SSLCACert:           b.SSLCACert,
+		SSLClientCert:       b.SSLClientCert,
+		SSLClientKey:        b.SSLClientKey,
+		SSLHostname:         b.SSLHostname,
+		SSLCertHostname:     b.SSLCertHostname,
+		SSLSNIHostname:      b.SSLSNIHostname,
+		MinTLSVersion:       b.MinTLSVersion,
+		MaxTLSVersion:       b.MaxTLSVersion,
+		SSLCiphers:          b.SSLCiphers,
+	}
+}
+
+// Form returns the form body that creates b.
+func (b *Backend) Form(service string, version int) interface{} {
+	return b.createInput(service, version)
+}
+
+// ListBackends returns the backends of a service version.
+func ListBackends(conn *gofastly.Client, service string, version int) ([]*Backend, error) {
+	list, err := conn.ListBackends(&gofastly.ListBackendsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backends := make([]*Backend, 0, len(list))
+	for _, b := range list {
+		backends = append(backends, backendFromAPI(b))
+	}
+	return backends, nil
+}
+
+// CreateBackend creates b in a service version.
+func CreateBackend(conn *gofastly.Client, service string, version int, b *Backend) error {
+	_, err := conn.CreateBackend(b.createInput(service, version))
+	return err
+}
+
+// DeleteBackend deletes the named backend from a service version.
+func DeleteBackend(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeleteBackend(&gofastly.DeleteBackendInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
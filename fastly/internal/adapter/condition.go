@@ -0,0 +1,104 @@
+package adapter
+
+import (
+	"fmt"
+
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// Condition is a Fastly condition. Type is one of REQUEST, CACHE or RESPONSE.
+type Condition struct {
+	Name      string
+	Statement string
+	Type      string
+	Priority  int
+}
+
+func conditionFromAPI(c *gofastly.Condition) *Condition {
+	return &Condition{
+		Name:      c.Name,
+		Statement: c.Statement,
+		Type:      c.Type,
+		Priority:  c.Priority,
+	}
+}
+
+func (c *Condition) createInput(service string, version int) *gofastly.CreateConditionInput {
+	return &gofastly.CreateConditionInput{
+		Service:   service,
+		Version:   version,
+		Name:      c.Name,
+		Statement: c.Statement,
+		Type:      c.Type,
+		Priority:  c.Priority,
+	}
+}
+
+// conditionForm is the form body that creates a condition. go-fastly's
+// CreateConditionInput leaves out a priority of 0, which Fastly then defaults
+// to 100, so the priority is always sent here.
+type conditionForm struct {
+	Name      string `form:"name,omitempty"`
+	Statement string `form:"statement,omitempty"`
+	Type      string `form:"type,omitempty"`
+	Priority  int    `form:"priority"`
+}
+
+// Form returns the form body that creates c.
+func (c *Condition) Form(service string, version int) interface{} {
+	input := c.createInput(service, version)
+	return &conditionForm{
+		Name:      input.Name,
+		Statement: input.Statement,
+		Type:      input.Type,
+		Priority:  input.Priority,
+	}
+}
+
+// ListConditions returns the conditions of a service version.
+func ListConditions(conn *gofastly.Client, service string, version int) ([]*Condition, error) {
+	list, err := conn.ListConditions(&gofastly.ListConditionsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make([]*Condition, 0, len(list))
+	for _, c := range list {
+		conditions = append(conditions, conditionFromAPI(c))
+	}
+	return conditions, nil
+}
+
+// GetCondition returns the named condition of a service version.
+func GetCondition(conn *gofastly.Client, service string, version int, name string) (*Condition, error) {
+	c, err := conn.GetCondition(&gofastly.GetConditionInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conditionFromAPI(c), nil
+}
+
+// CreateCondition creates c in a service version.
+func CreateCondition(conn *gofastly.Client, service string, version int, c *Condition) error {
+	resp, err := conn.PostForm(fmt.Sprintf("/service/%s/version/%d/condition", service, version), c.Form(service, version), nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DeleteCondition deletes the named condition from a service version.
+func DeleteCondition(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeleteCondition(&gofastly.DeleteConditionInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
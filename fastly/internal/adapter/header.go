@@ -0,0 +1,95 @@
+package adapter
+
+import (
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// Header is a Fastly header object. Action is one of set, append, delete,
+// regex or regex_repeat, and Type one of request, fetch, cache or response.
+type Header struct {
+	Name              string
+	Action            string
+	IgnoreIfSet       bool
+	Type              string
+	Destination       string
+	Source            string
+	Regex             string
+	Substitution      string
+	Priority          int
+	RequestCondition  string
+	CacheCondition    string
+	ResponseCondition string
+}
+
+func headerFromAPI(h *gofastly.Header) *Header {
+	return &Header{
+		Name:              h.Name,
+		Action:            string(h.Action),
+		IgnoreIfSet:       h.IgnoreIfSet,
+		Type:              string(h.Type),
+		Destination:       h.Destination,
+		Source:            h.Source,
+		Regex:             h.Regex,
+		Substitution:      h.Substitution,
+		Priority:          int(h.Priority),
+		RequestCondition:  h.RequestCondition,
+		CacheCondition:    h.CacheCondition,
+		ResponseCondition: h.ResponseCondition,
+	}
+}
+
+func (h *Header) createInput(service string, version int) *gofastly.CreateHeaderInput {
+	return &gofastly.CreateHeaderInput{
+		Service:           service,
+		Version:           version,
+		Name:              h.Name,
+		Action:            gofastly.HeaderAction(h.Action),
+		IgnoreIfSet:       gofastly.CBool(h.IgnoreIfSet),
+		Type:              gofastly.HeaderType(h.Type),
+		Destination:       h.Destination,
+		Source:            h.Source,
+		Regex:             h.Regex,
+		Substitution:      h.Substitution,
+		Priority:          uint(h.Priority),
+		RequestCondition:  h.RequestCondition,
+		CacheCondition:    h.CacheCondition,
+		ResponseCondition: h.ResponseCondition,
+	}
+}
+
+// Form returns the form body that creates h.
+func (h *Header) Form(service string, version int) interface{} {
+	return h.createInput(service, version)
+}
+
+// ListHeaders returns the headers of a service version.
+func ListHeaders(conn *gofastly.Client, service string, version int) ([]*Header, error) {
+	list, err := conn.ListHeaders(&gofastly.ListHeadersInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make([]*Header, 0, len(list))
+	for _, h := range list {
+		headers = append(headers, headerFromAPI(h))
+	}
+	return headers, nil
+}
+
+// CreateHeader creates h in a service version.
+func CreateHeader(conn *gofastly.Client, service string, version int, h *Header) error {
+	_, err := conn.CreateHeader(h.createInput(service, version))
+	return err
+}
+
+// DeleteHeader deletes the named header from a service version.
+func DeleteHeader(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeleteHeader(&gofastly.DeleteHeaderInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
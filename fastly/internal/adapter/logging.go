@@ -0,0 +1,574 @@
+package adapter
+
+import (
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// S3 is an Amazon S3 logging endpoint. Period is in seconds, and FileMaxBytes
+// is the size at which a new log file is started, or 0 for Fastly's default.
+type S3 struct {
+	Name              string
+	BucketName        string
+	Domain            string
+	AccessKey         string
+	SecretKey         string
+	Path              string
+	Period            int
+	GzipLevel         int
+	Format            string
+	FormatVersion     int
+	ResponseCondition string
+	TimestampFormat   string
+	Redundancy        string
+	PublicKey         string
+	FileMaxBytes      int
+}
+
+// s3Remote is an S3 logging endpoint as Fastly reports it. go-fastly's S3 does
+// not decode the public key or file size limit.
+type s3Remote struct {
+	gofastly.S3  `mapstructure:",squash"`
+	PublicKey    string `mapstructure:"public_key"`
+	FileMaxBytes int    `mapstructure:"file_max_bytes"`
+}
+
+func s3FromAPI(s *s3Remote) *S3 {
+	return &S3{
+		Name:              s.Name,
+		BucketName:        s.BucketName,
+		Domain:            s.Domain,
+		AccessKey:         s.AccessKey,
+		SecretKey:         s.SecretKey,
+		Path:              s.Path,
+		Period:            int(s.Period),
+		GzipLevel:         int(s.GzipLevel),
+		Format:            s.Format,
+		FormatVersion:     int(s.FormatVersion),
+		ResponseCondition: s.ResponseCondition,
+		TimestampFormat:   s.TimestampFormat,
+		Redundancy:        string(s.Redundancy),
+		PublicKey:         s.PublicKey,
+		FileMaxBytes:      s.FileMaxBytes,
+	}
+}
+
+// s3Input is the form body that creates an S3 logging endpoint. It follows
+// go-fastly's CreateS3Input, which has no public key or file size limit.
+type s3Input struct {
+	Service string `form:"-"`
+	Version int    `form:"-"`
+
+	Name              string `form:"name,omitempty"`
+	BucketName        string `form:"bucket_name,omitempty"`
+	Domain            string `form:"domain,omitempty"`
+	AccessKey         string `form:"access_key,omitempty"`
+	SecretKey         string `form:"secret_key,omitempty"`
+	Path              string `form:"path,omitempty"`
+	Period            uint   `form:"period,omitempty"`
+	GzipLevel         uint   `form:"gzip_level,omitempty"`
+	Format            string `form:"format,omitempty"`
+	FormatVersion     uint   `form:"format_version,omitempty"`
+	ResponseCondition string `form:"response_condition,omitempty"`
+	TimestampFormat   string `form:"timestamp_format,omitempty"`
+	Redundancy        string `form:"redundancy,omitempty"`
+	PublicKey         string `form:"public_key,omitempty"`
+	FileMaxBytes      int    `form:"file_max_bytes,omitempty"`
+}
+
+func (s *S3) createInput(service string, version int) *s3Input {
+	return &s3Input{
+		Service:           service,
+		Version:           version,
+		Name:              s.Name,
+		BucketName:        s.BucketName,
+		Domain:            s.Domain,
+		AccessKey:         s.AccessKey,
+		SecretKey:         s.SecretKey,
+		Path:              s.Path,
+		Period:            uint(s.Period),
+		GzipLevel:         uint(s.GzipLevel),
+		Format:            s.Format,
+		FormatVersion:     uint(s.FormatVersion),
+		ResponseCondition: s.ResponseCondition,
+		TimestampFormat:   s.TimestampFormat,
+		Redundancy:        s.Redundancy,
+		PublicKey:         s.PublicKey,
+		FileMaxBytes:      s.FileMaxBytes,
+	}
+}
+
+// Form returns the form body that creates s.
+func (s *S3) Form(service string, version int) interface{} {
+	return s.createInput(service, version)
+}
+
+// ListS3s returns the S3 logging endpoints of a service version.
+func ListS3s(conn *gofastly.Client, service string, version int) ([]*S3, error) {
+	var list []*s3Remote
+	if err := getJSON(conn, loggingPath(service, version, "s3"), &list); err != nil {
+		return nil, err
+	}
+
+	s3s := make([]*S3, 0, len(list))
+	for _, s := range list {
+		s3s = append(s3s, s3FromAPI(s))
+	}
+	return s3s, nil
+}
+
+// CreateS3 creates s in a service version.
+func CreateS3(conn *gofastly.Client, service string, version int, s *S3) error {
+	return postForm(conn, loggingPath(service, version, "s3"), s.Form(service, version))
+}
+
+// DeleteS3 deletes the named S3 logging endpoint from a service version.
+func DeleteS3(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeleteS3(&gofastly.DeleteS3Input{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
+
+// Papertrail is a Papertrail logging endpoint.
+type Papertrail struct {
+	Name              string
+	Address           string
+	Port              int
+	Format            string
+	ResponseCondition string
+}
+
+func papertrailFromAPI(p *gofastly.Papertrail) *Papertrail {
+	return &Papertrail{
+		Name:              p.Name,
+		Address:           p.Address,
+		Port:              int(p.Port),
+		Format:            p.Format,
+		ResponseCondition: p.ResponseCondition,
+	}
+}
+
+func (p *Papertrail) createInput(service string, version int) *gofastly.CreatePapertrailInput {
+	return &gofastly.CreatePapertrailInput{
+		Service:           service,
+		Version:           version,
+		Name:              p.Name,
+		Address:           p.Address,
+		Port:              uint(p.Port),
+		Format:            p.Format,
+		ResponseCondition: p.ResponseCondition,
+	}
+}
+
+// Form returns the form body that creates p.
+func (p *Papertrail) Form(service string, version int) interface{} {
+	return p.createInput(service, version)
+}
+
+// ListPapertrails returns the Papertrail logging endpoints of a service
+// version.
+func ListPapertrails(conn *gofastly.Client, service string, version int) ([]*Papertrail, error) {
+	list, err := conn.ListPapertrails(&gofastly.ListPapertrailsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	papertrails := make([]*Papertrail, 0, len(list))
+	for _, p := range list {
+		papertrails = append(papertrails, papertrailFromAPI(p))
+	}
+	return papertrails, nil
+}
+
+// CreatePapertrail creates p in a service version.
+func CreatePapertrail(conn *gofastly.Client, service string, version int, p *Papertrail) error {
+	_, err := conn.CreatePapertrail(p.createInput(service, version))
+	return err
+}
+
+// DeletePapertrail deletes the named Papertrail logging endpoint from a
+// service version.
+func DeletePapertrail(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeletePapertrail(&gofastly.DeletePapertrailInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
+
+// Sumologic is a Sumo Logic logging endpoint.
+type Sumologic struct {
+	Name              string
+	Address           string
+	URL               string
+	Format            string
+	ResponseCondition string
+	MessageType       string
+	FormatVersion     int
+}
+
+func sumologicFromAPI(s *gofastly.Sumologic) *Sumologic {
+	return &Sumologic{
+		Name:              s.Name,
+		Address:           s.Address,
+		URL:               s.URL,
+		Format:            s.Format,
+		ResponseCondition: s.ResponseCondition,
+		MessageType:       s.MessageType,
+		FormatVersion:     s.FormatVersion,
+	}
+}
+
+func (s *Sumologic) createInput(service string, version int) *gofastly.CreateSumologicInput {
+	return &gofastly.CreateSumologicInput{
+		Service:           service,
+		Version:           version,
+		Name:              s.Name,
+		Address:           s.Address,
+		URL:               s.URL,
+		Format:            s.Format,
+		ResponseCondition: s.ResponseCondition,
+		MessageType:       s.MessageType,
+		FormatVersion:     s.FormatVersion,
+	}
+}
+
+// Form returns the form body that creates s.
+func (s *Sumologic) Form(service string, version int) interface{} {
+	return s.createInput(service, version)
+}
+
+// ListSumologics returns the Sumo Logic logging endpoints of a service
+// version.
+func ListSumologics(conn *gofastly.Client, service string, version int) ([]*Sumologic, error) {
+	list, err := conn.ListSumologics(&gofastly.ListSumologicsInput{
+		Service: service,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sumologics := make([]*Sumologic, 0, len(list))
+	for _, s := range list {
+		sumologics = append(sumologics, sumologicFromAPI(s))
+	}
+	return sumologics, nil
+}
+
+// CreateSumologic creates s in a service version.
+func CreateSumologic(conn *gofastly.Client, service string, version int, s *Sumologic) error {
+	_, err := conn.CreateSumologic(s.createInput(service, version))
+	return err
+}
+
+// DeleteSumologic deletes the named Sumo Logic logging endpoint from a
+// service version.
+func DeleteSumologic(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeleteSumologic(&gofastly.DeleteSumologicInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
+
+// GCS is a Google Cloud Storage logging endpoint. User is the service account
+// email and Period is in seconds.
+type GCS struct {
+	Name              string
+	Bucket            string
+	User              string
+	SecretKey         string
+	Path              string
+	Period            int
+	GzipLevel         int
+	Format            string
+	FormatVersion     int
+	ResponseCondition string
+	TimestampFormat   string
+	PublicKey         string
+}
+
+// gcsRemote is a GCS logging endpoint as Fastly reports it. go-fastly's GCS
+// does not decode the format version or public key.
+type gcsRemote struct {
+	gofastly.GCS  `mapstructure:",squash"`
+	FormatVersion int    `mapstructure:"format_version"`
+	PublicKey     string `mapstructure:"public_key"`
+}
+
+func gcsFromAPI(g *gcsRemote) *GCS {
+	return &GCS{
+		Name:              g.Name,
+		Bucket:            g.Bucket,
+		User:              g.User,
+		SecretKey:         g.SecretKey,
+		Path:              g.Path,
+		Period:            int(g.Period),
+		GzipLevel:         int(g.GzipLevel),
+		Format:            g.Format,
+		FormatVersion:     g.FormatVersion,
+		ResponseCondition: g.ResponseCondition,
+		TimestampFormat:   g.TimestampFormat,
+		PublicKey:         g.PublicKey,
+	}
+}
+
+// gcsInput is the form body that creates a GCS logging endpoint. It follows
+// go-fastly's CreateGCSInput, which has no format version or public key.
+type gcsInput struct {
+	Service string `form:"-"`
+	Version int    `form:"-"`
+
+	Name              string `form:"name,omitempty"`
+	Bucket            string `form:"bucket_name,omitempty"`
+	User              string `form:"user,omitempty"`
+	SecretKey         string `form:"secret_key,omitempty"`
+	Path              string `form:"path,omitempty"`
+	Period            uint   `form:"period,omitempty"`
+	GzipLevel         uint8  `form:"gzip_level,omitempty"`
+	Format            string `form:"format,omitempty"`
+	FormatVersion     int    `form:"format_version,omitempty"`
+	ResponseCondition string `form:"response_condition,omitempty"`
+	TimestampFormat   string `form:"timestamp_format,omitempty"`
+	PublicKey         string `form:"public_key,omitempty"`
+}
+
+func (g *GCS) createInput(service string, version int) *gcsInput {
+	return &gcsInput{
+		Service:           service,
+		Version:           version,
+		Name:              g.Name,
+		Bucket:            g.Bucket,
+		User:              g.User,
+		SecretKey:         g.SecretKey,
+		Path:              g.Path,
+		Period:            uint(g.Period),
+		GzipLevel:         uint8(g.GzipLevel),
+		Format:            g.Format,
+		FormatVersion:     g.FormatVersion,
+		ResponseCondition: g.ResponseCondition,
+		TimestampFormat:   g.TimestampFormat,
+		PublicKey:         g.PublicKey,
+	}
+}
+
+// Form returns the form body that creates g.
+func (g *GCS) Form(service string, version int) interface{} {
+	return g.createInput(service, version)
+}
+
+// ListGCSs returns the GCS logging endpoints of a service version.
+func ListGCSs(conn *gofastly.Client, service string, version int) ([]*GCS, error) {
+	var list []*gcsRemote
+	if err := getJSON(conn, loggingPath(service, version, "gcs"), &list); err != nil {
+		return nil, err
+	}
+
+	gcss := make([]*GCS, 0, len(list))
+	for _, g := range list {
+		gcss = append(gcss, gcsFromAPI(g))
+	}
+	return gcss, nil
+}
+
+// CreateGCS creates g in a service version.
+func CreateGCS(conn *gofastly.Client, service string, version int, g *GCS) error {
+	return postForm(conn, loggingPath(service, version, "gcs"), g.Form(service, version))
+}
+
+// DeleteGCS deletes the named GCS logging endpoint from a service version.
+func DeleteGCS(conn *gofastly.Client, service string, version int, name string) error {
+	return conn.DeleteGCS(&gofastly.DeleteGCSInput{
+		Service: service,
+		Version: version,
+		Name:    name,
+	})
+}
+
+// The logging endpoints below are not covered by go-fastly, so Fastly's
+// response is decoded straight into the provider's types.
+
+// Loki is a Grafana Loki logging endpoint. Fastly ships logs to Loki through
+// its Grafana Cloud Logs integration, where the tenant is the endpoint user.
+type Loki struct {
+	Name              string `mapstructure:"name" form:"name,omitempty"`
+	URL               string `mapstructure:"url" form:"url,omitempty"`
+	AuthToken         string `mapstructure:"token" form:"token,omitempty"`
+	TenantID          string `mapstructure:"user" form:"user,omitempty"`
+	Index             string `mapstructure:"index" form:"index,omitempty"`
+	Format            string `mapstructure:"format" form:"format,omitempty"`
+	FormatVersion     int    `mapstructure:"format_version" form:"format_version,omitempty"`
+	ResponseCondition string `mapstructure:"response_condition" form:"response_condition,omitempty"`
+}
+
+// Form returns the form body that creates l.
+func (l *Loki) Form(service string, version int) interface{} {
+	return l
+}
+
+// ListLokis returns the Loki logging endpoints of a service version.
+func ListLokis(conn *gofastly.Client, service string, version int) ([]*Loki, error) {
+	var lokis []*Loki
+	if err := getJSON(conn, loggingPath(service, version, "grafanacloudlogs"), &lokis); err != nil {
+		return nil, err
+	}
+	return lokis, nil
+}
+
+// CreateLoki creates l in a service version.
+func CreateLoki(conn *gofastly.Client, service string, version int, l *Loki) error {
+	return postForm(conn, loggingPath(service, version, "grafanacloudlogs"), l.Form(service, version))
+}
+
+// DeleteLoki deletes the named Loki logging endpoint from a service version.
+func DeleteLoki(conn *gofastly.Client, service string, version int, name string) error {
+	return deletePath(conn, loggingPath(service, version, "grafanacloudlogs", name))
+}
+
+// HTTPS is a logging endpoint that sends batches of logs to an arbitrary HTTPS
+// URL.
+type HTTPS struct {
+	Name              string `mapstructure:"name" form:"name,omitempty"`
+	URL               string `mapstructure:"url" form:"url,omitempty"`
+	RequestMaxEntries int    `mapstructure:"request_max_entries" form:"request_max_entries,omitempty"`
+	RequestMaxBytes   int    `mapstructure:"request_max_bytes" form:"request_max_bytes,omitempty"`
+	ContentType       string `mapstructure:"content_type" form:"content_type,omitempty"`
+	HeaderName        string `mapstructure:"header_name" form:"header_name,omitempty"`
+	HeaderValue       string `mapstructure:"header_value" form:"header_value,omitempty"`
+	Method            string `mapstructure:"method" form:"method,omitempty"`
+	JSONFormat        string `mapstructure:"json_format" form:"json_format,omitempty"`
+	TLSCACert         string `mapstructure:"tls_ca_cert" form:"tls_ca_cert,omitempty"`
+	TLSClientCert     string `mapstructure:"tls_client_cert" form:"tls_client_cert,omitempty"`
+	TLSClientKey      string `mapstructure:"tls_client_key" form:"tls_client_key,omitempty"`
+	TLSHostname       string `mapstructure:"tls_hostname" form:"tls_hostname,omitempty"`
+	Format            string `mapstructure:"format" form:"format,omitempty"`
+	FormatVersion     int    `mapstructure:"format_version" form:"format_version,omitempty"`
+	ResponseCondition string `mapstructure:"response_condition" form:"response_condition,omitempty"`
+}
+
+// Form returns the form body that creates h.
+func (h *HTTPS) Form(service string, version int) interface{} {
+	return h
+}
+
+// ListHTTPS returns the HTTPS logging endpoints of a service version.
+func ListHTTPS(conn *gofastly.Client, service string, version int) ([]*HTTPS, error) {
+	var https []*HTTPS
+	if err := getJSON(conn, loggingPath(service, version, "https"), &https); err != nil {
+		return nil, err
+	}
+	return https, nil
+}
+
+// CreateHTTPS creates h in a service version.
+func CreateHTTPS(conn *gofastly.Client, service string, version int, h *HTTPS) error {
+	return postForm(conn, loggingPath(service, version, "https"), h.Form(service, version))
+}
+
+// DeleteHTTPS deletes the named HTTPS logging endpoint from a service version.
+func DeleteHTTPS(conn *gofastly.Client, service string, version int, name string) error {
+	return deletePath(conn, loggingPath(service, version, "https", name))
+}
+
+// OpenStack is a logging endpoint that uploads log files to an OpenStack Swift
+// container. Period is in seconds.
+type OpenStack struct {
+	Name              string `mapstructure:"name" form:"name,omitempty"`
+	URL               string `mapstructure:"url" form:"url,omitempty"`
+	User              string `mapstructure:"user" form:"user,omitempty"`
+	BucketName        string `mapstructure:"bucket_name" form:"bucket_name,omitempty"`
+	AccessKey         string `mapstructure:"access_key" form:"access_key,omitempty"`
+	Path              string `mapstructure:"path" form:"path,omitempty"`
+	Period            int    `mapstructure:"period" form:"period,omitempty"`
+	GzipLevel         int    `mapstructure:"gzip_level" form:"gzip_level,omitempty"`
+	Format            string `mapstructure:"format" form:"format,omitempty"`
+	FormatVersion     int    `mapstructure:"format_version" form:"format_version,omitempty"`
+	ResponseCondition string `mapstructure:"response_condition" form:"response_condition,omitempty"`
+	MessageType       string `mapstructure:"message_type" form:"message_type,omitempty"`
+	TimestampFormat   string `mapstructure:"timestamp_format" form:"timestamp_format,omitempty"`
+	PublicKey         string `mapstructure:"public_key" form:"public_key,omitempty"`
+}
+
+// Form returns the form body that creates o.
+func (o *OpenStack) Form(service string, version int) interface{} {
+	return o
+}
+
+// ListOpenStacks returns the OpenStack logging endpoints of a service version.
+func ListOpenStacks(conn *gofastly.Client, service string, version int) ([]*OpenStack, error) {
+	var openstacks []*OpenStack
+	if err := getJSON(conn, loggingPath(service, version, "openstack"), &openstacks); err != nil {
+		return nil, err
+	}
+	return openstacks, nil
+}
+
+// CreateOpenStack creates o in a service version.
+func CreateOpenStack(conn *gofastly.Client, service string, version int, o *OpenStack) error {
+	return postForm(conn, loggingPath(service, version, "openstack"), o.Form(service, version))
+}
+
+// DeleteOpenStack deletes the named OpenStack logging endpoint from a service
+// version.
+func DeleteOpenStack(conn *gofastly.Client, service string, version int, name string) error {
+	return deletePath(conn, loggingPath(service, version, "openstack", name))
+}
+
+// LogShuttle is a logging endpoint that forwards logs to a Log Shuttle proxy,
+// authenticating with a token.
+type LogShuttle struct {
+	Name              string `mapstructure:"name" form:"name,omitempty"`
+	URL               string `mapstructure:"url" form:"url,omitempty"`
+	Token             string `mapstructure:"token" form:"token,omitempty"`
+	Format            string `mapstructure:"format" form:"format,omitempty"`
+	FormatVersion     int    `mapstructure:"format_version" form:"format_version,omitempty"`
+	ResponseCondition string `mapstructure:"response_condition" form:"response_condition,omitempty"`
+}
+
+// Form returns the form body that creates l.
+func (l *LogShuttle) Form(service string, version int) interface{} {
+	return l
+}
+
+// ListLogShuttles returns the Log Shuttle logging endpoints of a service
+// version.
+func ListLogShuttles(conn *gofastly.Client, service string, version int) ([]*LogShuttle, error) {
+	var logShuttles []*LogShuttle
+	if err := getJSON(conn, loggingPath(service, version, "logshuttle"), &logShuttles); err != nil {
+		return nil, err
+	}
+	return logShuttles, nil
+}
+
+// CreateLogShuttle creates l in a service version.
+func CreateLogShuttle(conn *gofastly.Client, service string, version int, l *LogShuttle) error {
+	return postForm(conn, loggingPath(service, version, "logshuttle"), l.Form(service, version))
+}
+
+// DeleteLogShuttle deletes the named Log Shuttle logging endpoint from a
+// service version.
+func DeleteLogShuttle(conn *gofastly.Client, service string, version int, name string) error {
+	return deletePath(conn, loggingPath(service, version, "logshuttle", name))
+}
+
+// LoggingCondition is the part of every logging endpoint that says when it
+// logs.
+type LoggingCondition struct {
+	Name              string `mapstructure:"name"`
+	ResponseCondition string `mapstructure:"response_condition"`
+}
+
+// ListLoggingConditions returns the response condition of every logging
+// endpoint of the given type, such as "s3", on a service version.
+func ListLoggingConditions(conn *gofastly.Client, service string, version int, endpoint string) ([]*LoggingCondition, error) {
+	var conditions []*LoggingCondition
+	if err := getJSON(conn, loggingPath(service, version, endpoint), &conditions); err != nil {
+		return nil, err
+	}
+	return conditions, nil
+}
@@ -0,0 +1,94 @@
+package fastly
+
+import (
+	"fmt"
+	"log"
+
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+// maintenanceModeName names the condition and response object generated for
+// maintenance_mode. Configured objects may not use it.
+const maintenanceModeName = "terraform-maintenance-mode"
+
+// defaultMaintenanceContent is the maintenance page served when
+// maintenance_content is not set.
+const defaultMaintenanceContent = "<html><head><title>Down for maintenance</title></head><body><h1>Down for maintenance</h1><p>Please try again shortly.</p></body></html>"
+
+// createMaintenanceMode adds a condition matching every request and a 503
+// response object serving content when it matches.
+func createMaintenanceMode(conn *gofastly.Client, adopt bool, service string, version int, content string) error {
+	condition := &adapter.Condition{
+		Name:      maintenanceModeName,
+		Type:      "REQUEST",
+		Statement: "true",
+		Priority:  1,
+	}
+	log.Printf("[DEBUG] Create Maintenance Mode Condition Opts: %#v", condition)
+	err := adapter.CreateCondition(conn, service, version, condition)
+	err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(service, version, "condition", maintenanceModeName), condition.Form(service, version))
+	if err != nil {
+		return fmt.Errorf("[ERR] Error creating the maintenance mode condition: %s", err)
+	}
+
+	response := gofastly.CreateResponseObjectInput{
+		Service:          service,
+		Version:          version,
+		Name:             maintenanceModeName,
+		Status:           503,
+		Response:         "Service Unavailable",
+		Content:          content,
+		ContentType:      "text/html",
+		RequestCondition: maintenanceModeName,
+	}
+	log.Printf("[DEBUG] Create Maintenance Mode Response Object Opts: %#v", response)
+	_, err = conn.CreateResponseObject(&response)
+	err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(service, version, "response_object", maintenanceModeName), &response)
+	if err != nil {
+		return fmt.Errorf("[ERR] Error creating the maintenance mode response object: %s", err)
+	}
+	return nil
+}
+
+// deleteMaintenanceMode removes the objects added by createMaintenanceMode,
+// the response object first as it references the condition.
+func deleteMaintenanceMode(conn *gofastly.Client, service string, version int) error {
+	if err := conn.DeleteResponseObject(&gofastly.DeleteResponseObjectInput{
+		Service: service,
+		Version: version,
+		Name:    maintenanceModeName,
+	}); err != nil {
+		return fmt.Errorf("[ERR] Error deleting the maintenance mode response object: %s", err)
+	}
+
+	if err := adapter.DeleteCondition(conn, service, version, maintenanceModeName); err != nil {
+		return fmt.Errorf("[ERR] Error deleting the maintenance mode condition: %s", err)
+	}
+	return nil
+}
+
+// removeMaintenanceMode returns a flattened condition or response_object list
+// without the element generated for maintenance mode, and that element, or
+// nil if there is none.
+func removeMaintenanceMode(list []map[string]interface{}) ([]map[string]interface{}, map[string]interface{}) {
+	var rest []map[string]interface{}
+	var generated map[string]interface{}
+	for _, e := range list {
+		if e["name"] == maintenanceModeName {
+			generated = e
+			continue
+		}
+		rest = append(rest, e)
+	}
+	return rest, generated
+}
+
+// validateNotMaintenanceModeName rejects a condition or response object name
+// that would clash with the objects generated for maintenance mode.
+func validateNotMaintenanceModeName(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) == maintenanceModeName {
+		errors = append(errors, fmt.Errorf("%q: %q is reserved for the objects generated by maintenance_mode", k, maintenanceModeName))
+	}
+	return
+}
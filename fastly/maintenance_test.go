@@ -0,0 +1,268 @@
+package fastly
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+func TestRemoveMaintenanceMode(t *testing.T) {
+	generated := map[string]interface{}{"name": maintenanceModeName, "content": "down"}
+	list := []map[string]interface{}{{"name": "a"}, generated, {"name": "b"}}
+
+	rest, got := removeMaintenanceMode(list)
+	if expected := []map[string]interface{}{{"name": "a"}, {"name": "b"}}; !reflect.DeepEqual(rest, expected) {
+		t.Fatalf("expected %#v, got: %#v", expected, rest)
+	}
+	if !reflect.DeepEqual(got, generated) {
+		t.Fatalf("expected the generated element, got: %#v", got)
+	}
+
+	if _, got := removeMaintenanceMode(rest); got != nil {
+		t.Fatalf("expected no generated element, got: %#v", got)
+	}
+}
+
+// The names generated for maintenance mode are rejected at plan time.
+func TestValidateNotMaintenanceModeName(t *testing.T) {
+	elements := map[string]map[string]interface{}{
+		"condition":       {"name": maintenanceModeName, "statement": "req.url ~ \"^/\"", "type": "REQUEST"},
+		"response_object": {"name": maintenanceModeName},
+	}
+	for block, element := range elements {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name":   "test",
+			"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+			block:    []interface{}{element},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if _, es := resourceServiceV1().Validate(terraform.NewResourceConfig(c)); len(es) != 1 {
+			t.Fatalf("%s: expected the reserved name to be rejected, got: %q", block, es)
+		}
+	}
+
+	if _, es := validateNotMaintenanceModeName("maintenance", "name"); len(es) != 0 {
+		t.Fatalf("expected no errors, got: %q", es)
+	}
+}
+
+func TestResourceServiceV1Update_maintenanceMode(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var calls []string
+	var condition, response url.Values
+	record := testRecordCalls(&calls)
+	readForm := func(form *url.Values, body string) func(w http.ResponseWriter, r *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			*form, _ = url.ParseQuery(string(b))
+			testFastlyJSON(body)(w, r)
+		}
+	}
+
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":                                             testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":                                     record("clone", testFastlyJSON(`{"number": 2}`)),
+		"PUT /service/test-service/version/2":                                           testFastlyJSON(`{"number": 2}`),
+		"POST /service/test-service/version/2/condition":                                record("create condition", readForm(&condition, `{"name": "terraform-maintenance-mode"}`)),
+		"POST /service/test-service/version/2/response_object":                          record("create response object", readForm(&response, `{"name": "terraform-maintenance-mode"}`)),
+		"DELETE /service/test-service/version/2/response_object/" + maintenanceModeName: record("delete response object", testFastlyJSON(`{"status": "ok"}`)),
+		"DELETE /service/test-service/version/2/condition/" + maintenanceModeName:       record("delete condition", testFastlyJSON(`{"status": "ok"}`)),
+		"GET /service/test-service/version/2/validate":                                  record("validate", testFastlyJSON(`{"status": "ok"}`)),
+		"PUT /service/test-service/version/2/activate":                                  record("activate", testFastlyJSON(`{"number": 2, "active": true}`)),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	apply := func(from, to bool) {
+		r := resourceServiceV1()
+		raw := map[string]interface{}{
+			"name":             "test",
+			"domain":           []interface{}{map[string]interface{}{"name": "example.com"}},
+			"maintenance_mode": from,
+		}
+		od := schema.TestResourceDataRaw(t, r.Schema, raw)
+		od.SetId("test-service")
+		od.Set("active_version", 1)
+
+		raw["maintenance_mode"] = to
+		raw["maintenance_content"] = "back soon"
+		c, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if _, err := r.Apply(od.State(), diff, client); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	apply(false, true)
+	if expected := []string{"clone", "create condition", "create response object", "validate", "activate"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+	if condition.Get("statement") != "true" || condition.Get("type") != "REQUEST" {
+		t.Fatalf("expected a condition matching every request, got: %q", condition)
+	}
+	if response.Get("status") != "503" || response.Get("content") != "back soon" || response.Get("request_condition") != maintenanceModeName {
+		t.Fatalf("expected a 503 response object serving the maintenance content, got: %q", response)
+	}
+
+	calls = nil
+	apply(true, false)
+	if expected := []string{"clone", "delete response object", "delete condition", "validate", "activate"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+}
+
+func TestAccFastlyServiceV1_maintenanceMode(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("tf-acc-test-%s.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1Config_maintenanceMode(name, domainName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1MaintenanceMode(&service, false),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "maintenance_mode", "false"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1Config_maintenanceMode(name, domainName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1MaintenanceMode(&service, true),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "maintenance_mode", "true"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "maintenance_content", "<h1>Back soon</h1>"),
+					// The generated objects are not part of the configured blocks
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "condition.#", "1"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "response_object.#", "1"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1Config_maintenanceMode(name, domainName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1MaintenanceMode(&service, false),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "maintenance_mode", "false"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckFastlyServiceV1MaintenanceMode checks whether the active
+// version has the generated maintenance mode condition and response object.
+func testAccCheckFastlyServiceV1MaintenanceMode(service *gofastly.ServiceDetail, enabled bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		responseObjects, err := conn.ListResponseObjects(&gofastly.ListResponseObjectsInput{
+			Service: service.ID,
+			Version: service.ActiveVersion.Number,
+		})
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Response Objects for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+		conditions, err := conn.ListConditions(&gofastly.ListConditionsInput{
+			Service: service.ID,
+			Version: service.ActiveVersion.Number,
+		})
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Conditions for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		var response *gofastly.ResponseObject
+		for _, r := range responseObjects {
+			if r.Name == maintenanceModeName {
+				response = r
+			}
+		}
+		var condition bool
+		for _, c := range conditions {
+			if c.Name == maintenanceModeName {
+				condition = true
+			}
+		}
+
+		if !enabled {
+			if response != nil || condition {
+				return fmt.Errorf("Expected the maintenance mode objects to be removed")
+			}
+			return nil
+		}
+		if response == nil || !condition {
+			return fmt.Errorf("Expected the maintenance mode condition and response object")
+		}
+		if response.Status != 503 || response.Content != "<h1>Back soon</h1>" {
+			return fmt.Errorf("Bad maintenance response object: %#v", response)
+		}
+		return nil
+	}
+}
+
+func testAccServiceV1Config_maintenanceMode(name, domain string, enabled bool) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  condition {
+    name      = "is_teapot"
+    statement = "req.url ~ \"^/teapot\""
+    type      = "REQUEST"
+  }
+
+  response_object {
+    name              = "teapot"
+    status            = 418
+    response          = "I'm a teapot"
+    request_condition = "is_teapot"
+  }
+
+  maintenance_mode    = %t
+  maintenance_content = "<h1>Back soon</h1>"
+
+  force_destroy = true
+}`, name, domain, enabled)
+}
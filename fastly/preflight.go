@@ -0,0 +1,142 @@
+package fastly
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// preflightCheckBackends verifies that every backend listed in the
+// preflight_check block accepts connections before a version is activated.
+// The checks are made from the machine running Terraform, not from Fastly, so
+// they only catch backends that are unreachable from everywhere (for example a
+// typo'd address), not Fastly-specific network problems.
+func preflightCheckBackends(d *schema.ResourceData) error {
+	pcl := d.Get("preflight_check").([]interface{})
+	if len(pcl) == 0 || pcl[0] == nil {
+		return nil
+	}
+	pc := pcl[0].(map[string]interface{})
+	timeout := time.Duration(pc["timeout"].(int)) * time.Millisecond
+
+	backends := make(map[string]map[string]interface{})
+	for _, bRaw := range d.Get("backend").(*schema.Set).List() {
+		bf := bRaw.(map[string]interface{})
+		backends[bf["name"].(string)] = bf
+	}
+
+	var errs error
+	for _, nRaw := range pc["backends"].([]interface{}) {
+		name := nRaw.(string)
+		bf, ok := backends[name]
+		if !ok {
+			errs = multierror.Append(errs, fmt.Errorf("preflight_check: backend %q is not defined on this service", name))
+			continue
+		}
+
+		address := bf["address"].(string)
+		port := bf["port"].(int)
+
+		var tlsConfig *tls.Config
+		if useSSL, _ := bf["use_ssl"].(bool); useSSL {
+			tlsConfig = preflightTLSConfig(bf)
+		}
+
+		log.Printf("[DEBUG] Preflight check of backend (%s) at %s:%d", name, address, port)
+		if err := dialBackend(address, port, tlsConfig, timeout); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("preflight_check: backend %q is unreachable from this machine: %s", name, err))
+		}
+	}
+
+	return errs
+}
+
+// preflightTLSConfig builds the TLS configuration used to handshake with a
+// backend, mirroring the hostname and verification settings Fastly would use:
+// ssl_sni_hostname is only sent as SNI, the certificate is verified against
+// ssl_cert_hostname, and ssl_ca_cert, when set, replaces the system roots.
+// The legacy ssl_hostname, then the address, stand in for either hostname.
+func preflightTLSConfig(bf map[string]interface{}) *tls.Config {
+	fallback := bf["address"].(string)
+	if v, ok := bf["ssl_hostname"].(string); ok && v != "" {
+		fallback = v
+	}
+	sniHostname, certHostname := fallback, fallback
+	if v, ok := bf["ssl_sni_hostname"].(string); ok && v != "" {
+		sniHostname = v
+	}
+	if v, ok := bf["ssl_cert_hostname"].(string); ok && v != "" {
+		certHostname = v
+	}
+
+	var roots *x509.CertPool
+	if ca, ok := bf["ssl_ca_cert"].(string); ok && ca != "" {
+		roots = x509.NewCertPool()
+		roots.AppendCertsFromPEM([]byte(ca))
+	}
+
+	// crypto/tls verifies the certificate against ServerName, which here is
+	// the SNI hostname, so its own verification is turned off and the chain
+	// is checked against certHostname instead.
+	checkCert, _ := bf["ssl_check_cert"].(bool)
+	cfg := &tls.Config{
+		ServerName:         sniHostname,
+		RootCAs:            roots,
+		InsecureSkipVerify: true,
+	}
+	if checkCert {
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyBackendCertificate(cs.PeerCertificates, roots, certHostname)
+		}
+	}
+	return cfg
+}
+
+// verifyBackendCertificate verifies the chain a backend presented against
+// roots, or the system roots when roots is nil, and checks that the leaf
+// certificate is valid for hostname.
+func verifyBackendCertificate(certs []*x509.Certificate, roots *x509.CertPool, hostname string) error {
+	if len(certs) == 0 {
+		return fmt.Errorf("backend presented no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       hostname,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// dialBackend opens a TCP connection to address:port, performing a TLS
+// handshake when tlsConfig is non-nil, and closes it again. The whole check,
+// handshake included, must finish within timeout.
+func dialBackend(address string, port int, tlsConfig *tls.Config, timeout time.Duration) error {
+	addr := net.JoinHostPort(address, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: timeout, Deadline: time.Now().Add(timeout)}
+
+	if tlsConfig == nil {
+		conn, err := dialer.Dial("tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,311 @@
+package fastly
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestDialBackend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting listener: %s", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	host, port := splitTestAddr(t, ln.Addr().String())
+	if err := dialBackend(host, port, nil, time.Second); err != nil {
+		t.Fatalf("expected open listener to be reachable, got: %s", err)
+	}
+
+	// Grab a port, then release it so nothing is listening there.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting listener: %s", err)
+	}
+	host, port = splitTestAddr(t, closed.Addr().String())
+	closed.Close()
+	if err := dialBackend(host, port, nil, time.Second); err == nil {
+		t.Fatal("expected closed port to be unreachable")
+	}
+}
+
+func TestDialBackend_tls(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	host, port := splitTestAddr(t, ts.Listener.Addr().String())
+	if err := dialBackend(host, port, &tls.Config{InsecureSkipVerify: true}, time.Second); err != nil {
+		t.Fatalf("expected TLS handshake to succeed, got: %s", err)
+	}
+
+	// The test server's certificate is self-signed, so a verified handshake
+	// must fail.
+	if err := dialBackend(host, port, &tls.Config{ServerName: "example.com"}, time.Second); err == nil {
+		t.Fatal("expected TLS handshake with an untrusted certificate to fail")
+	}
+
+	// A plain TCP listener never completes a handshake.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting listener: %s", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port = splitTestAddr(t, ln.Addr().String())
+	if err := dialBackend(host, port, &tls.Config{InsecureSkipVerify: true}, 200*time.Millisecond); err == nil {
+		t.Fatal("expected TLS handshake against a plain listener to time out")
+	}
+}
+
+func TestPreflightTLSConfig(t *testing.T) {
+	var sni string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			sni = hello.ServerName
+			return nil, nil
+		},
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// The test server's certificate is self-signed and valid for
+	// example.com.
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	host, port := splitTestAddr(t, ts.Listener.Addr().String())
+
+	cases := []struct {
+		name    string
+		backend map[string]interface{}
+		ok      bool
+	}{
+		{
+			name: "sni and cert hostnames differ",
+			backend: map[string]interface{}{
+				"ssl_check_cert":    true,
+				"ssl_cert_hostname": "example.com",
+				"ssl_sni_hostname":  "sni.example.net",
+				"ssl_ca_cert":       ca,
+			},
+			ok: true,
+		},
+		{
+			name: "cert hostname not in certificate",
+			backend: map[string]interface{}{
+				"ssl_check_cert":    true,
+				"ssl_cert_hostname": "sni.example.net",
+				"ssl_sni_hostname":  "example.com",
+				"ssl_ca_cert":       ca,
+			},
+		},
+		{
+			name: "certificate not trusted",
+			backend: map[string]interface{}{
+				"ssl_check_cert":    true,
+				"ssl_cert_hostname": "example.com",
+				"ssl_sni_hostname":  "sni.example.net",
+			},
+		},
+		{
+			name: "unchecked",
+			backend: map[string]interface{}{
+				"ssl_check_cert":    false,
+				"ssl_cert_hostname": "sni.example.net",
+				"ssl_sni_hostname":  "sni.example.net",
+			},
+			ok: true,
+		},
+	}
+
+	for _, c := range cases {
+		c.backend["address"] = host
+		sni = ""
+		err := dialBackend(host, port, preflightTLSConfig(c.backend), time.Second)
+		if c.ok && err != nil {
+			t.Fatalf("%s: expected TLS handshake to succeed, got: %s", c.name, err)
+		}
+		if !c.ok && err == nil {
+			t.Fatalf("%s: expected TLS handshake to fail", c.name)
+		}
+		if sni != c.backend["ssl_sni_hostname"] {
+			t.Fatalf("%s: expected SNI %q, got %q", c.name, c.backend["ssl_sni_hostname"], sni)
+		}
+	}
+}
+
+func TestPreflightCheckBackends(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting listener: %s", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port := splitTestAddr(t, ln.Addr().String())
+
+	backends := []interface{}{
+		map[string]interface{}{
+			"name":    "up",
+			"address": "127.0.0.1",
+			"port":    port,
+		},
+	}
+
+	cases := []struct {
+		names []interface{}
+		err   string
+	}{
+		{
+			names: []interface{}{"up"},
+		},
+		{
+			names: []interface{}{"up", "missing"},
+			err:   `backend "missing" is not defined`,
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"name":    "preflight",
+			"domain":  []interface{}{map[string]interface{}{"name": "example.com"}},
+			"backend": backends,
+			"preflight_check": []interface{}{
+				map[string]interface{}{
+					"backends": c.names,
+					"timeout":  1000,
+				},
+			},
+		})
+
+		err := preflightCheckBackends(d)
+		if c.err == "" {
+			if err != nil {
+				t.Fatalf("unexpected error for %v: %s", c.names, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expected error containing %q for %v, got: %v", c.err, c.names, err)
+		}
+	}
+}
+
+func TestResourceServiceV1Update_preflightAbandonsVersion(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	// Take a free port and release it, so nothing accepts connections on it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting listener: %s", err)
+	}
+	_, port := splitTestAddr(t, ln.Addr().String())
+	ln.Close()
+
+	var comment string
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":         testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone": testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2": func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			comment = r.PostForm.Get("comment")
+			testFastlyJSON(`{"number": 2}`)(w, r)
+		},
+		"POST /service/test-service/version/2/backend": testFastlyJSON(`{"name": "down"}`),
+		"GET /service/test-service/version/2/validate": testFastlyJSON(`{"status": "ok"}`),
+		// Activating the abandoned version is an unexpected request
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+	od.Set("cloned_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"backend": []interface{}{
+			map[string]interface{}{
+				"name":    "down",
+				"address": "127.0.0.1",
+				"port":    port,
+			},
+		},
+		"preflight_check": []interface{}{
+			map[string]interface{}{
+				"backends": []interface{}{"down"},
+				"timeout":  1000,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := r.Apply(od.State(), diff, client)
+	if err == nil || !strings.Contains(err.Error(), "Preflight check failed") {
+		t.Fatalf("expected the preflight check to fail, got: %v", err)
+	}
+	if !strings.HasPrefix(comment, "Abandoned by Terraform: ") || !strings.Contains(comment, `backend "down" is unreachable`) {
+		t.Fatalf("expected version 2 to be commented as abandoned, got: %q", comment)
+	}
+	if v := state.Attributes["cloned_version"]; v != "1" {
+		t.Fatalf("expected cloned_version to stay at 1, got: %s", v)
+	}
+}
+
+func splitTestAddr(t *testing.T, addr string) (string, int) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("error splitting %q: %s", addr, err)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		t.Fatalf("error parsing port %q: %s", p, err)
+	}
+	return host, port
+}
@@ -7,7 +7,7 @@ import (
 
 // Provider returns a terraform.ResourceProvider.
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	return &provider{&schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"api_key": &schema.Schema{
 				Type:     schema.TypeString,
@@ -16,22 +16,135 @@ func Provider() terraform.ResourceProvider {
 					"FASTLY_API_KEY",
 				}, nil),
 				Description: "Fastly API Key from https://app.fastly.com/#account",
+				Sensitive:   true,
+			},
+			"default_s3_access_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("FASTLY_S3_ACCESS_KEY", ""),
+				Description:  "AWS Access Key used by s3logging endpoints that do not set their own",
+				Sensitive:    true,
+				ValidateFunc: validateS3AccessKey,
+			},
+			"default_s3_secret_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("FASTLY_S3_SECRET_KEY", ""),
+				Description:  "AWS Secret Key used by s3logging endpoints that do not set their own",
+				Sensitive:    true,
+				ValidateFunc: validateS3SecretKey,
+			},
+			"default_gcs_email": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "GCS email used by gcslogging endpoints that do not set their own",
+			},
+			"default_gcs_secret_key": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "GCS secret key used by gcslogging endpoints that do not set their own",
+				Sensitive:   true,
+			},
+			"gcp_credentials": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Google Cloud service account used by gcslogging endpoints that do not set their own, after default_gcs_email and default_gcs_secret_key",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"email": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The email of the service account",
+						},
+						"secret_key": &schema.Schema{
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The private key of the service account",
+							Sensitive:   true,
+						},
+					},
+				},
+			},
+			"strict_tls": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail, rather than warn, when a TLS backend does not verify its certificate or sets no ssl_cert_hostname",
+			},
+			"management_marker": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultManagementMarker,
+				Description: "Text written to the comment of new services, and looked for to tell whether a service is managed elsewhere",
+			},
+			"drift_report": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Log a WARN line for each block of a service that differs from state on refresh",
+			},
+			"ca_bundle": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM certificates, or the path of a file of them, to trust for the Fastly API on top of the system roots",
+			},
+			"http_proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "URL of the proxy to reach the Fastly API through, instead of the one HTTPS_PROXY and NO_PROXY select",
+				Sensitive:   true,
 			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"fastly_ip_ranges": dataSourceFastlyIPRanges(),
+			"fastly_ip_ranges":        dataSourceFastlyIPRanges(),
+			"fastly_service_versions": dataSourceFastlyServiceVersions(),
+			"fastly_token_info":       dataSourceFastlyTokenInfo(),
+			"fastly_vcl_bundle":       dataSourceFastlyVCLBundle(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"fastly_service_v1": resourceServiceV1(),
+			"fastly_service_v1":           resourceServiceV1(),
+			"fastly_service_pool_servers": resourceServicePoolServers(),
 		},
 
 		ConfigureFunc: providerConfigure,
+	}}
+}
+
+// provider is a schema.Provider that also checks the raw configuration of
+// resources at plan time. Unlike the schema.ResourceData validateServiceV1
+// sees at apply time, the raw configuration tells a field left out apart from
+// one set to its default.
+type provider struct {
+	*schema.Provider
+}
+
+// ValidateResource validates the configuration of a resource of type t.
+func (p *provider) ValidateResource(t string, c *terraform.ResourceConfig) ([]string, []error) {
+	ws, es := p.Provider.ValidateResource(t, c)
+	if t == "fastly_service_v1" {
+		es = append(es, validateBackendTimeouts(c)...)
 	}
+	return ws, es
 }
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	config := Config{
-		ApiKey: d.Get("api_key").(string),
+		ApiKey:              d.Get("api_key").(string),
+		DefaultS3AccessKey:  d.Get("default_s3_access_key").(string),
+		DefaultS3SecretKey:  d.Get("default_s3_secret_key").(string),
+		DefaultGCSEmail:     d.Get("default_gcs_email").(string),
+		DefaultGCSSecretKey: d.Get("default_gcs_secret_key").(string),
+		StrictTLS:           d.Get("strict_tls").(bool),
+		ManagementMarker:    d.Get("management_marker").(string),
+		DriftReport:         d.Get("drift_report").(bool),
+		CABundle:            d.Get("ca_bundle").(string),
+		HTTPProxy:           d.Get("http_proxy").(string),
+	}
+	if v, ok := d.GetOk("gcp_credentials"); ok {
+		gcp := v.([]interface{})[0].(map[string]interface{})
+		config.GCPEmail = gcp["email"].(string)
+		config.GCPSecretKey = gcp["secret_key"].(string)
 	}
 	return config.Client()
 }
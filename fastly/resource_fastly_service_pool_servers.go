@@ -0,0 +1,215 @@
+package fastly
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// resourceServicePoolServers manages the servers of a pool defined by a
+// fastly_service_v1 pool block. Servers are not versioned, so changing them
+// never clones or activates a service version.
+func resourceServicePoolServers() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceServicePoolServersCreate,
+		Read:   resourceServicePoolServersRead,
+		Update: resourceServicePoolServersUpdate,
+		Delete: resourceServicePoolServersDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"service_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the Service the pool belongs to",
+			},
+			"pool_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The ID of the pool, as exported by the pool block of fastly_service_v1",
+			},
+			"server": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// required fields
+						"address": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "An IPv4, IPv6, or hostname for the server",
+						},
+						// optional fields
+						"port": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     80,
+							Description: "The port number on which the server listens",
+						},
+						"weight": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     100,
+							Description: "The share of traffic the server receives, relative to the other servers",
+						},
+						"disabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Take the server out of rotation without removing it",
+						},
+						"comment": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+						// computed fields
+						"server_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceServicePoolServersCreate(d *schema.ResourceData, meta interface{}) error {
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("service_id").(string), d.Get("pool_id").(string)))
+	return resourceServicePoolServersUpdate(d, meta)
+}
+
+func resourceServicePoolServersUpdate(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*FastlyClient).conn
+	serviceID := d.Get("service_id").(string)
+	poolID := d.Get("pool_id").(string)
+
+	os, ns := d.GetChange("server")
+	if os == nil {
+		os = new(schema.Set)
+	}
+	if ns == nil {
+		ns = new(schema.Set)
+	}
+
+	removeServers, addServers, changeServers := diffByKey(os.(*schema.Set).List(), ns.(*schema.Set).List(), poolServerKey, "server_id")
+	log.Printf("[INFO] pool %s: removing %d servers, adding %d, updating %d", poolID, len(removeServers), len(addServers), len(changeServers))
+
+	for _, sf := range removeServers {
+		log.Printf("[DEBUG] Fastly Pool Server removal: %s", poolServerKey(sf))
+		if err := deletePoolServer(conn, serviceID, poolID, sf["server_id"].(string)); err != nil {
+			return err
+		}
+	}
+
+	for _, sc := range changeServers {
+		opts := buildPoolServer(sc.New)
+
+		log.Printf("[DEBUG] Update Pool Server Opts: %#v", opts)
+		if err := updatePoolServer(conn, serviceID, poolID, sc.Old["server_id"].(string), opts); err != nil {
+			return err
+		}
+	}
+
+	for _, sf := range addServers {
+		opts := buildPoolServer(sf)
+
+		log.Printf("[DEBUG] Create Pool Server Opts: %#v", opts)
+		if _, err := createPoolServer(conn, serviceID, poolID, opts); err != nil {
+			return err
+		}
+	}
+
+	return resourceServicePoolServersRead(d, meta)
+}
+
+func resourceServicePoolServersRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*FastlyClient).conn
+
+	// An imported resource only has its ID, which is service_id/pool_id.
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("[ERR] Invalid pool servers ID (%s), expected service_id/pool_id", d.Id())
+	}
+	serviceID, poolID := parts[0], parts[1]
+	d.Set("service_id", serviceID)
+	d.Set("pool_id", poolID)
+
+	servers, err := listPoolServers(conn, serviceID, poolID)
+	if err != nil {
+		if httpErr, ok := err.(*gofastly.HTTPError); ok && httpErr.IsNotFound() {
+			log.Printf("[WARN] Pool (%s) of Fastly Service (%s) not found, removing from state", poolID, serviceID)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("[ERR] Error looking up servers of pool (%s), service (%s): %s", poolID, serviceID, err)
+	}
+
+	if err := d.Set("server", flattenPoolServers(servers)); err != nil {
+		log.Printf("[WARN] Error setting servers of pool (%s): %s", poolID, err)
+	}
+
+	return nil
+}
+
+func resourceServicePoolServersDelete(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*FastlyClient).conn
+	serviceID := d.Get("service_id").(string)
+	poolID := d.Get("pool_id").(string)
+
+	for _, sRaw := range d.Get("server").(*schema.Set).List() {
+		sf := sRaw.(map[string]interface{})
+
+		log.Printf("[DEBUG] Fastly Pool Server removal: %s", poolServerKey(sf))
+		err := deletePoolServer(conn, serviceID, poolID, sf["server_id"].(string))
+		if httpErr, ok := err.(*gofastly.HTTPError); ok && httpErr.IsNotFound() {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// poolServerKey identifies a server of a pool by address and port.
+func poolServerKey(m map[string]interface{}) string {
+	return fmt.Sprintf("%s:%d", m["address"].(string), m["port"].(int))
+}
+
+func buildPoolServer(serverMap map[string]interface{}) *poolServer {
+	return &poolServer{
+		Address:  serverMap["address"].(string),
+		Port:     uint(serverMap["port"].(int)),
+		Weight:   uint(serverMap["weight"].(int)),
+		Disabled: gofastly.Compatibool(serverMap["disabled"].(bool)),
+		Comment:  serverMap["comment"].(string),
+	}
+}
+
+func flattenPoolServers(serverList []*poolServer) []map[string]interface{} {
+	var sl []map[string]interface{}
+	for _, s := range serverList {
+		// Convert pool servers to a map for saving to state.
+		sl = append(sl, map[string]interface{}{
+			"address":   s.Address,
+			"port":      int(s.Port),
+			"weight":    int(s.Weight),
+			"disabled":  bool(s.Disabled),
+			"comment":   s.Comment,
+			"server_id": s.ID,
+		})
+	}
+
+	return sl
+}
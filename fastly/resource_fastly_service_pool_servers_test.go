@@ -0,0 +1,253 @@
+package fastly
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+)
+
+// Membership changes are made to the servers directly: removed servers are
+// deleted, changed ones updated in place and new ones created, without any
+// service version being cloned.
+func TestResourceServicePoolServersUpdate(t *testing.T) {
+	var calls []string
+	record := testRecordCalls(&calls)
+
+	sent := map[string]string{}
+	form := func(call string) func(w http.ResponseWriter, r *http.Request) {
+		return record(call, func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			sent[call] = r.PostForm.Encode()
+			testFastlyJSON(`{"id": "s3", "address": "10.0.0.3", "port": "80"}`)(w, r)
+		})
+	}
+
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"DELETE /service/test-service/pool/p1/server/s1": record("delete 10.0.0.1", testFastlyJSON(`{"status": "ok"}`)),
+		"PUT /service/test-service/pool/p1/server/s2":    form("update 10.0.0.2"),
+		"POST /service/test-service/pool/p1/server":      form("create 10.0.0.3"),
+		"GET /service/test-service/pool/p1/server": testFastlyJSON(`[
+			{"id": "s2", "address": "10.0.0.2", "port": "80", "weight": "50", "disabled": true, "comment": ""},
+			{"id": "s3", "address": "10.0.0.3", "port": "80", "weight": "100", "disabled": false, "comment": ""}
+		]`),
+	})
+	defer closeServer()
+
+	server := func(address, id string, weight int, disabled bool) map[string]interface{} {
+		return map[string]interface{}{
+			"address":   address,
+			"port":      80,
+			"weight":    weight,
+			"disabled":  disabled,
+			"comment":   "",
+			"server_id": id,
+		}
+	}
+
+	r := resourceServicePoolServers()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"service_id": "test-service",
+		"pool_id":    "p1",
+		"server": []interface{}{
+			server("10.0.0.1", "s1", 100, false),
+			server("10.0.0.2", "s2", 100, false),
+		},
+	})
+	od.SetId("test-service/p1")
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"service_id": "test-service",
+		"pool_id":    "p1",
+		"server": []interface{}{
+			map[string]interface{}{"address": "10.0.0.2", "weight": 50, "disabled": true},
+			map[string]interface{}{"address": "10.0.0.3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := r.Apply(od.State(), diff, client)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"delete 10.0.0.1", "update 10.0.0.2", "create 10.0.0.3"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+	if expected := "address=10.0.0.2&comment=&disabled=1&port=80&weight=50"; sent["update 10.0.0.2"] != expected {
+		t.Fatalf("expected update %q, got: %q", expected, sent["update 10.0.0.2"])
+	}
+
+	var ids []string
+	for k, v := range state.Attributes {
+		if strings.HasSuffix(k, ".server_id") {
+			ids = append(ids, v)
+		}
+	}
+	sort.Strings(ids)
+	if expected := []string{"s2", "s3"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected server IDs %q in state, got: %q", expected, ids)
+	}
+}
+
+func TestResourceServicePoolServersRead_importID(t *testing.T) {
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/pool/p1/server": testFastlyJSON(`[{"id": "s1", "address": "10.0.0.1", "port": "8080", "weight": "100"}]`),
+	})
+	defer closeServer()
+
+	d := schema.TestResourceDataRaw(t, resourceServicePoolServers().Schema, map[string]interface{}{})
+	d.SetId("test-service/p1")
+	if err := resourceServicePoolServersRead(d, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if d.Get("service_id") != "test-service" || d.Get("pool_id") != "p1" {
+		t.Fatalf("expected service_id and pool_id from the ID, got: %q, %q", d.Get("service_id"), d.Get("pool_id"))
+	}
+	servers := d.Get("server").(*schema.Set).List()
+	if len(servers) != 1 || servers[0].(map[string]interface{})["port"] != 8080 {
+		t.Fatalf("expected the server on port 8080, got: %v", servers)
+	}
+
+	d.SetId("p1")
+	if err := resourceServicePoolServersRead(d, client); err == nil {
+		t.Fatal("expected an error for an ID without a service")
+	}
+}
+
+func TestAccFastlyServicePoolServers_basic(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServicePoolServersConfig(name, domain, 100),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "pool.#", "1"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_pool_servers.origins", "server.#", "2"),
+					testAccCheckFastlyServicePoolServerWeights("fastly_service_pool_servers.origins", 100, 100),
+				),
+			},
+
+			// Changing a weight must not create a new service version
+			resource.TestStep{
+				Config: testAccServicePoolServersConfig(name, domain, 25),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFastlyServiceV1ActiveVersion("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServicePoolServerWeights("fastly_service_pool_servers.origins", 25, 100),
+				),
+			},
+
+			resource.TestStep{
+				ResourceName:      "fastly_service_pool_servers.origins",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testAccCheckFastlyServiceV1ActiveVersion checks that the active version of
+// the service is still the one recorded in service.
+func testAccCheckFastlyServiceV1ActiveVersion(n string, service *gofastly.ServiceDetail) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if got := rs.Primary.Attributes["active_version"]; got != fmt.Sprint(service.ActiveVersion.Number) {
+			return fmt.Errorf("Expected active version to stay %d, got %s", service.ActiveVersion.Number, got)
+		}
+		return nil
+	}
+}
+
+func testAccCheckFastlyServicePoolServerWeights(n string, weights ...int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		servers, err := listPoolServers(conn, rs.Primary.Attributes["service_id"], rs.Primary.Attributes["pool_id"])
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up pool servers for (%s): %s", rs.Primary.ID, err)
+		}
+
+		var got []int
+		for _, server := range servers {
+			got = append(got, int(server.Weight))
+		}
+		sort.Ints(got)
+		sort.Ints(weights)
+		if !reflect.DeepEqual(got, weights) {
+			return fmt.Errorf("Pool server weights mismatch, expected: %v, got: %v", weights, got)
+		}
+
+		return nil
+	}
+}
+
+func testAccServicePoolServersConfig(name, domain string, weight int) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  pool {
+    name = "origins"
+  }
+
+  force_destroy = true
+}
+
+resource "fastly_service_pool_servers" "origins" {
+  service_id = "${fastly_service_v1.foo.id}"
+  pool_id    = "${element(fastly_service_v1.foo.pool.*.pool_id, 0)}"
+
+  server {
+    address = "10.0.0.1"
+    weight  = %d
+  }
+
+  server {
+    address = "10.0.0.2"
+  }
+}`, name, domain, weight)
+}
@@ -1,20 +1,48 @@
 package fastly
 
 import (
+	"bytes"
 	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
 )
 
 var fastlyNoServiceFoundErr = errors.New("No matching Fastly Service found")
 
+// versionAvailableDelay is how long Update waits for a cloned version to
+// become usable.
+var versionAvailableDelay = 7 * time.Second
+
+// conditionReadyAttempts and conditionReadyDelay bound how long Update waits
+// for a new condition to be readable before creating logging endpoints that
+// reference it.
+var (
+	conditionReadyAttempts = 5
+	conditionReadyDelay    = 2 * time.Second
+)
+
+// defaultVersionComment is the comment of versions built by Terraform when
+// version_comment is not set.
+const defaultVersionComment = "Managed by Terraform"
+
 func resourceServiceV1() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceServiceV1Create,
@@ -25,6 +53,9 @@ func resourceServiceV1() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		SchemaVersion: 1,
+		MigrateState:  resourceServiceV1MigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -41,56 +72,117 @@ func resourceServiceV1() *schema.Resource {
 				Computed: true,
 			},
 
+			// Cloned Version is the version Terraform last built, which is only
+			// ahead of active_version while activate is false.
+			"cloned_version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"maintenance_mode": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Serve a 503 maintenance page to every request",
+			},
+
+			"maintenance_content": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultMaintenanceContent,
+				Description: "The HTML page served while maintenance_mode is enabled",
+			},
+
+			"version_comment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultVersionComment,
+				Description: "The comment of the versions built by Terraform",
+			},
+
+			"include_generated_vcl": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to read the VCL Fastly generates for the active version into generated_vcl",
+			},
+
+			"generated_vcl": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The VCL Fastly generated for the active version, if include_generated_vcl is set",
+			},
+
+			"activate": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to activate the version built by an apply. When false, the version is left for manual activation",
+			},
+
+			"tls_activation_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The IDs of the TLS activations of the service's domains",
+			},
+
+			"customer_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the Fastly customer (account) the service belongs to",
+			},
+
+			"is_managed_elsewhere": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the service comment lacks the provider's management_marker",
+			},
+
+			"expected_customer_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "If set, reading or updating the service fails unless it belongs to this customer ID",
+			},
+
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the service was created",
+			},
+
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the service was last updated",
+			},
+
 			"domain": {
-				Type:     schema.TypeSet,
-				Required: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The domain that this Service will respond to",
-						},
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"domains"},
+				Elem:          domainResource(),
+			},
 
-						"comment": {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-					},
+			// domains is a compact alternative to domain blocks for services with
+			// many domains. Its domains have no comment.
+			"domains": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"domain"},
+				Description:   "The domains that this Service will respond to, as an alternative to domain blocks",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateDomainName,
 				},
+				Set: schema.HashString,
 			},
 
 			"condition": {
 				Type:     schema.TypeSet,
 				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"statement": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The statement used to determine if the condition is met",
-							StateFunc: func(v interface{}) string {
-								value := v.(string)
-								// Trim newlines and spaces, to match Fastly API
-								return strings.TrimSpace(value)
-							},
-						},
-						"priority": {
-							Type:        schema.TypeInt,
-							Required:    true,
-							Description: "A number used to determine the order in which multiple conditions execute. Lower numbers execute first",
-						},
-						"type": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "Type of the condition, either `REQUEST`, `RESPONSE`, or `CACHE`",
-						},
-					},
-				},
+				Set:      conditionHash,
+				Elem:     conditionResource(),
 			},
 
 			"default_ttl": {
@@ -130,22 +222,25 @@ func resourceServiceV1() *schema.Resource {
 						},
 						// optional fields
 						"check_interval": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     5000,
-							Description: "How often to run the healthcheck in milliseconds",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5000,
+							Description:  "How often to run the healthcheck in milliseconds",
+							ValidateFunc: validatePositiveInt,
 						},
 						"expected_response": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     200,
-							Description: "The status code expected from the host",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      200,
+							Description:  "The status code expected from the host",
+							ValidateFunc: validateHTTPStatusCode,
 						},
 						"http_version": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "1.1",
-							Description: "Whether to use version 1.0 or 1.1 HTTP",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "1.1",
+							Description:  "Whether to use version 1.0 or 1.1 HTTP",
+							ValidateFunc: validateHealthcheckHTTPVersion,
 						},
 						"initial": {
 							Type:        schema.TypeInt,
@@ -154,10 +249,11 @@ func resourceServiceV1() *schema.Resource {
 							Description: "When loading a config, the initial number of probes to be seen as OK",
 						},
 						"method": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "HEAD",
-							Description: "Which HTTP method to use",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "HEAD",
+							Description:  "Which HTTP method to use",
+							ValidateFunc: validateHealthcheckMethod,
 						},
 						"threshold": {
 							Type:        schema.TypeInt,
@@ -177,11 +273,29 @@ func resourceServiceV1() *schema.Resource {
 							Default:     5,
 							Description: "The number of most recent healthcheck queries to keep for this healthcheck",
 						},
+						"comment": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "An optional comment about the healthcheck",
+						},
+						"headers": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Custom HTTP headers to send with the healthcheck probes, by header name",
+						},
 					},
 				},
 			},
 
 			"backend": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Set:      backendHash,
+				Elem:     backendResource(),
+			},
+
+			"pool": {
 				Type:     schema.TypeSet,
 				Optional: true,
 				Elem: &schema.Resource{
@@ -190,25 +304,32 @@ func resourceServiceV1() *schema.Resource {
 						"name": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "A name for this Backend",
+							Description: "A name to refer to this pool",
 						},
-						"address": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Description: "An IPv4, hostname, or IPv6 address for the Backend",
+						// optional fields
+						"type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "random",
+							Description:  "How servers are chosen: random, hash or client",
+							ValidateFunc: validatePoolType,
 						},
-						// Optional fields, defaults where they exist
-						"auto_loadbalance": {
-							Type:        schema.TypeBool,
+						"healthcheck": {
+							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     true,
-							Description: "Should this Backend be load balanced",
+							Default:     "",
+							Description: "The healthcheck used to check the servers of this pool",
+						},
+						"comment": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
 						},
-						"between_bytes_timeout": {
+						"max_conn_default": {
 							Type:        schema.TypeInt,
 							Optional:    true,
-							Default:     10000,
-							Description: "How long to wait between bytes in milliseconds",
+							Default:     200,
+							Description: "The maximum number of connections to a server that does not set its own",
 						},
 						"connect_timeout": {
 							Type:        schema.TypeInt,
@@ -216,87 +337,24 @@ func resourceServiceV1() *schema.Resource {
 							Default:     1000,
 							Description: "How long to wait for a timeout in milliseconds",
 						},
-						"error_threshold": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     0,
-							Description: "Number of errors to allow before the Backend is marked as down",
-						},
 						"first_byte_timeout": {
 							Type:        schema.TypeInt,
 							Optional:    true,
 							Default:     15000,
 							Description: "How long to wait for the first bytes in milliseconds",
 						},
-						"healthcheck": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "",
-							Description: "The healthcheck name that should be used for this Backend",
-						},
-						"max_conn": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     200,
-							Description: "Maximum number of connections for this Backend",
-						},
-						"port": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     80,
-							Description: "The port number Backend responds on. Default 80",
-						},
-						"request_condition": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "",
-							Description: "Name of a condition, which if met, will select this backend during a request.",
-						},
-						"shield": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "",
-							Description: "The POP of the shield designated to reduce inbound load.",
-						},
-						"ssl_check_cert": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Default:     true,
-							Description: "Be strict on checking SSL certs",
-						},
-						"ssl_hostname": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "",
-							Description: "SSL certificate hostname",
-							Deprecated:  "Use ssl_cert_hostname and ssl_sni_hostname instead.",
-						},
-						"ssl_cert_hostname": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "",
-							Description: "SSL certificate hostname for cert verification",
+						"quorum": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      75,
+							Description:  "The percentage of servers that must be healthy for the pool to be up",
+							ValidateFunc: validatePercentage,
 						},
-						"ssl_sni_hostname": {
+						// computed fields
+						"pool_id": {
 							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "",
-							Description: "SSL certificate hostname for SNI verification",
-						},
-						// UseSSL is something we want to support in the future, but
-						// requires SSL setup we don't yet have
-						// TODO: Provide all SSL fields from https://docs.fastly.com/api/config#backend
-						// "use_ssl": &schema.Schema{
-						// 	Type:        schema.TypeBool,
-						// 	Optional:    true,
-						// 	Default:     false,
-						// 	Description: "Whether or not to use SSL to reach the Backend",
-						// },
-						"weight": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Default:     100,
-							Description: "The portion of traffic to send to a specific origins. Each origin receives weight/total of the traffic.",
+							Computed:    true,
+							Description: "The ID of the pool, which fastly_service_pool_servers manages servers by",
 						},
 					},
 				},
@@ -307,6 +365,48 @@ func resourceServiceV1() *schema.Resource {
 				Optional: true,
 			},
 
+			"adopt_existing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Take over objects that already exist on the service under a configured name, rather than failing to create them",
+			},
+
+			"service_id_reuse": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ID of a service to take over on create instead of creating a new one, if it exists and has never been activated",
+			},
+
+			"protected_backends": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Names of Backends that may not be removed or renamed",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"preflight_check": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"backends": {
+							Type:        schema.TypeList,
+							Required:    true,
+							Description: "Names of Backends that must accept connections before a version is activated",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"timeout": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Default:     5000,
+							Description: "How long to wait for each Backend to accept a connection, in milliseconds",
+						},
+					},
+				},
+			},
+
 			"cache_setting": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -348,6 +448,7 @@ func resourceServiceV1() *schema.Resource {
 			"gzip": {
 				Type:     schema.TypeSet,
 				Optional: true,
+				Set:      gzipHash,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						// required fields
@@ -375,6 +476,12 @@ func resourceServiceV1() *schema.Resource {
 							Default:     "",
 							Description: "Name of a condition controlling when this gzip configuration applies.",
 						},
+						"allow_empty": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Allow this gzip configuration to set no content_types, extensions or cache_condition",
+						},
 					},
 				},
 			},
@@ -439,10 +546,11 @@ func resourceServiceV1() *schema.Resource {
 							Description: "Don't add the header if it is already. (Only applies to 'set' action.). Default `false`",
 						},
 						"source": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Computed:    true,
-							Description: "Variable to be used as a source for the header content (Does not apply to 'delete' action.)",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							Description:  "Variable to be used as a source for the header content (Does not apply to 'delete' action.)",
+							ValidateFunc: validateHeaderSource,
 						},
 						"regex": {
 							Type:        schema.TypeString,
@@ -459,7 +567,7 @@ func resourceServiceV1() *schema.Resource {
 						"priority": {
 							Type:        schema.TypeInt,
 							Optional:    true,
-							Default:     100,
+							Default:     headerDefaultPriority,
 							Description: "Lower priorities execute first. (Default: 100.)",
 						},
 						"request_condition": {
@@ -484,6 +592,31 @@ func resourceServiceV1() *schema.Resource {
 				},
 			},
 
+			"surrogate_key": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "A name to refer to this Surrogate-Key header",
+						},
+						"key_template": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "VCL expression producing the space-separated surrogate keys, such as `\"product-\" req.url.basename`",
+						},
+						"cache_condition": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Optional name of a cache condition controlling when the keys are set",
+						},
+					},
+				},
+			},
+
 			"s3logging": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -501,18 +634,18 @@ func resourceServiceV1() *schema.Resource {
 							Description: "S3 Bucket name to store logs in",
 						},
 						"s3_access_key": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							DefaultFunc: schema.EnvDefaultFunc("FASTLY_S3_ACCESS_KEY", ""),
-							Description: "AWS Access Key",
-							Sensitive:   true,
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "AWS Access Key ID (AKIA...) of an IAM user allowed s3:PutObject on the bucket's objects and s3:GetBucketAcl and s3:ListBucket on the bucket, or the access key of an Oracle Cloud customer secret key. Defaults to the provider's default_s3_access_key",
+							Sensitive:    true,
+							ValidateFunc: validateS3AccessKey,
 						},
 						"s3_secret_key": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							DefaultFunc: schema.EnvDefaultFunc("FASTLY_S3_SECRET_KEY", ""),
-							Description: "AWS Secret Key",
-							Sensitive:   true,
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "AWS Secret Access Key (40 characters), or Oracle Cloud secret key (44 characters), for s3_access_key. Defaults to the provider's default_s3_secret_key",
+							Sensitive:    true,
+							ValidateFunc: validateS3SecretKey,
 						},
 						// Optional fields
 						"path": {
@@ -521,9 +654,10 @@ func resourceServiceV1() *schema.Resource {
 							Description: "Path to store the files. Must end with a trailing slash",
 						},
 						"domain": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Bucket endpoint",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "Bucket endpoint, or the hostname of an S3-compatible store such as Oracle Cloud Object Storage",
+							ValidateFunc: validateS3Domain,
 						},
 						"gzip_level": {
 							Type:        schema.TypeInt,
@@ -537,6 +671,12 @@ func resourceServiceV1() *schema.Resource {
 							Default:     3600,
 							Description: "How frequently the logs should be transferred, in seconds (Default 3600)",
 						},
+						"file_max_bytes": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Description:  "Start a new log file once the current one reaches this size, in bytes, between 1048576 (1MB) and 1073741824 (1GB)",
+							ValidateFunc: validateFileMaxBytes,
+						},
 						"format": {
 							Type:        schema.TypeString,
 							Optional:    true,
@@ -548,13 +688,14 @@ func resourceServiceV1() *schema.Resource {
 							Optional:     true,
 							Default:      1,
 							Description:  "The version of the custom logging format used for the configured endpoint. Can be either 1 or 2. (Default: 1)",
-							ValidateFunc: validateLoggingFormatVersion,
+							ValidateFunc: validateLoggingFormatVersionFor("s3logging"),
 						},
 						"timestamp_format": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Default:     "%Y-%m-%dT%H:%M:%S.000",
-							Description: "specified timestamp formatting (default `%Y-%m-%dT%H:%M:%S.000`)",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "%Y-%m-%dT%H:%M:%S.000",
+							Description:  "specified timestamp formatting (default `%Y-%m-%dT%H:%M:%S.000`)",
+							ValidateFunc: validateTimestampFormat,
 						},
 						"response_condition": {
 							Type:        schema.TypeString,
@@ -562,6 +703,13 @@ func resourceServiceV1() *schema.Resource {
 							Default:     "",
 							Description: "Name of a condition to apply this logging.",
 						},
+						"public_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							Description:  "A PGP public key used to encrypt log files before they are written",
+							ValidateFunc: validatePGPPublicKey,
+						},
 					},
 				},
 			},
@@ -618,6 +766,7 @@ func resourceServiceV1() *schema.Resource {
 							Type:        schema.TypeString,
 							Required:    true,
 							Description: "The URL to POST to.",
+							Sensitive:   true,
 						},
 						// Optional fields
 						"format": {
@@ -631,7 +780,7 @@ func resourceServiceV1() *schema.Resource {
 							Optional:     true,
 							Default:      1,
 							Description:  "The version of the custom logging format used for the configured endpoint. Can be either 1 or 2. (Default: 1)",
-							ValidateFunc: validateLoggingFormatVersion,
+							ValidateFunc: validateLoggingFormatVersionFor("sumologic"),
 						},
 						"response_condition": {
 							Type:        schema.TypeString,
@@ -646,8 +795,14 @@ func resourceServiceV1() *schema.Resource {
 							Description:  "How the message should be formatted.",
 							ValidateFunc: validateLoggingMessageType,
 						},
-					},
-				},
+						"region": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "The Sumo Logic deployment url posts to, for example US2. Only checked against url, as Fastly does not store it",
+							ValidateFunc: validateSumologicRegion,
+						},
+					},
+				},
 			},
 
 			"gcslogging": {
@@ -663,8 +818,8 @@ func resourceServiceV1() *schema.Resource {
 						},
 						"email": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The email address associated with the target GCS bucket on your account.",
+							Optional:    true,
+							Description: "The email address associated with the target GCS bucket on your account. Defaults to the provider's default_gcs_email",
 						},
 						"bucket_name": {
 							Type:        schema.TypeString,
@@ -673,8 +828,8 @@ func resourceServiceV1() *schema.Resource {
 						},
 						"secret_key": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The secret key associated with the target gcs bucket on your account.",
+							Optional:    true,
+							Description: "The secret key associated with the target gcs bucket on your account. Defaults to the provider's default_gcs_secret_key",
 							Sensitive:   true,
 						},
 						// Optional fields
@@ -701,11 +856,84 @@ func resourceServiceV1() *schema.Resource {
 							Default:     "%h %l %u %t %r %>s",
 							Description: "Apache-style string or VCL variables to use for log formatting",
 						},
+						"format_version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							Description:  "The version of the custom logging format used for the configured endpoint. Can be either 1 or 2. (Default: 1)",
+							ValidateFunc: validateLoggingFormatVersion,
+						},
 						"timestamp_format": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "%Y-%m-%dT%H:%M:%S.000",
+							Description:  "specified timestamp formatting (default `%Y-%m-%dT%H:%M:%S.000`)",
+							ValidateFunc: validateTimestampFormat,
+						},
+						"response_condition": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Name of a condition to apply this logging.",
+						},
+						"public_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							Description:  "A PGP public key used to encrypt log files before they are written",
+							ValidateFunc: validatePGPPublicKey,
+						},
+					},
+				},
+			},
+
+			"loki": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// Required fields
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Unique name to refer to this logging setup",
+						},
+						"url": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The URL of the Loki instance to push logs to.",
+						},
+						"auth_token": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The token used to authenticate with Loki.",
+							Sensitive:   true,
+						},
+						"index": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The stream selector Loki indexes the logs under, such as {env=\"prod\"}.",
+						},
+						// Optional fields
+						"tenant_id": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     "%Y-%m-%dT%H:%M:%S.000",
-							Description: "specified timestamp formatting (default `%Y-%m-%dT%H:%M:%S.000`)",
+							Description: "The Loki tenant (user) that logs are pushed as.",
+						},
+						"format": {
+							Type:     schema.TypeString,
+							Optional: true,
+							// Fastly applies its Apache-style default when no format
+							// is sent, so it is the default here too
+							Default:     "%h %l %u %t %r %>s",
+							Description: "VCL variables to use for log formatting, usually logfmt or JSON. Defaults to Fastly's Apache Common Log format (%h %l %u %t %r %>s).",
+						},
+						"format_version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2,
+							Description:  "The version of the custom logging format used for the configured endpoint. Must be 2. (Default: 2)",
+							ValidateFunc: validateLoggingFormatVersionFor("loki"),
 						},
 						"response_condition": {
 							Type:        schema.TypeString,
@@ -717,59 +945,113 @@ func resourceServiceV1() *schema.Resource {
 				},
 			},
 
-			"response_object": {
+			"httpslogging": {
 				Type:     schema.TypeSet,
 				Optional: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						// Required
+						// Required fields
 						"name": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "Unique name to refer to this request object",
+							Description: "Unique name to refer to this logging setup",
+						},
+						"url": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The HTTPS URL logs are sent to",
+							ValidateFunc: validateHTTPSURL,
+						},
+						"request_max_entries": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							Description:  "Maximum number of logs to append to a batch. 0 means no limit.",
+							ValidateFunc: validateNonNegativeInt,
+						},
+						"request_max_bytes": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							Description:  "Maximum size of a batch in bytes. 0 means no limit.",
+							ValidateFunc: validateNonNegativeInt,
+						},
+						"content_type": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Value of the Content-Type header sent with the logs",
+						},
+						"header_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Name of a custom header sent with the logs",
+						},
+						"header_value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Value of the custom header sent with the logs",
+							Sensitive:   true,
+						},
+						"method": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "HTTP method used to send the logs, either POST or PUT",
+							ValidateFunc: validateHTTPSLoggingMethod,
+						},
+						"json_format": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "How logs are batched as JSON: 0 (not JSON), 1 (JSON array) or 2 (newline delimited JSON)",
+							ValidateFunc: validateHTTPSLoggingJSONFormat,
 						},
 						// Optional fields
-						"status": {
-							Type:        schema.TypeInt,
+						"tls_ca_cert": {
+							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     200,
-							Description: "The HTTP Status Code of the object",
+							Default:     "",
+							Description: "A PEM-encoded CA certificate used to verify the endpoint",
 						},
-						"response": {
+						"tls_client_cert": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     "OK",
-							Description: "The HTTP Response of the object",
+							Default:     "",
+							Description: "A PEM-encoded client certificate presented to the endpoint",
 						},
-						"content": {
+						"tls_client_key": {
 							Type:        schema.TypeString,
 							Optional:    true,
 							Default:     "",
-							Description: "The content to deliver for the response object",
+							Description: "The PEM-encoded private key of the client certificate",
+							Sensitive:   true,
 						},
-						"content_type": {
+						"tls_hostname": {
 							Type:        schema.TypeString,
 							Optional:    true,
 							Default:     "",
-							Description: "The MIME type of the content",
+							Description: "The hostname used to verify the endpoint's certificate",
 						},
-						"request_condition": {
+						"format": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     "",
-							Description: "Name of the condition to be checked during the request phase to see if the object should be delivered",
+							Default:     "%h %l %u %t %r %>s",
+							Description: "Apache-style string or VCL variables to use for log formatting",
 						},
-						"cache_condition": {
+						"format_version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2,
+							Description:  "The version of the custom logging format used for the configured endpoint. Can be either 1 or 2. (Default: 2)",
+							ValidateFunc: validateLoggingFormatVersionFor("httpslogging"),
+						},
+						"response_condition": {
 							Type:        schema.TypeString,
 							Optional:    true,
 							Default:     "",
-							Description: "Name of the condition checked after we have retrieved an object. If the condition passes then deliver this Request Object instead.",
+							Description: "Name of a condition to apply this logging.",
 						},
 					},
 				},
 			},
 
-			"request_setting": {
+			"openstacklogging": {
 				Type:     schema.TypeSet,
 				Optional: true,
 				Elem: &schema.Resource{
@@ -778,132 +1060,640 @@ func resourceServiceV1() *schema.Resource {
 						"name": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "Unique name to refer to this Request Setting",
+							Description: "Unique name to refer to this logging setup",
+						},
+						"url": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The URL of the OpenStack authentication endpoint",
+						},
+						"user": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The OpenStack user name",
+						},
+						"bucket_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the container logs are written to",
+						},
+						"access_key": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The OpenStack access key",
+							Sensitive:   true,
 						},
 						// Optional fields
-						"request_condition": {
+						"path": {
 							Type:        schema.TypeString,
 							Optional:    true,
 							Default:     "",
-							Description: "Name of a request condition to apply. If there is no condition this setting will always be applied.",
+							Description: "The path to upload logs to",
 						},
-						"max_stale_age": {
+						"period": {
 							Type:        schema.TypeInt,
 							Optional:    true,
-							Default:     60,
-							Description: "How old an object is allowed to be, in seconds. Default `60`",
-						},
-						"force_miss": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Description: "Force a cache miss for the request",
+							Default:     3600,
+							Description: "How frequently the logs should be transferred, in seconds (Default 3600)",
 						},
-						"force_ssl": {
-							Type:        schema.TypeBool,
+						"gzip_level": {
+							Type:        schema.TypeInt,
 							Optional:    true,
-							Description: "Forces the request use SSL",
+							Default:     0,
+							Description: "Gzip Compression level",
 						},
-						"action": {
+						"format": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Description: "Allows you to terminate request handling and immediately perform an action",
-						},
-						"bypass_busy_wait": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Description: "Disable collapsed forwarding",
+							Default:     "%h %l %u %t %r %>s",
+							Description: "Apache-style string or VCL variables to use for log formatting",
 						},
-						"hash_keys": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "Comma separated list of varnish request object fields that should be in the hash key",
+						"format_version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2,
+							Description:  "The version of the custom logging format used for the configured endpoint. Can be either 1 or 2. (Default: 2)",
+							ValidateFunc: validateLoggingFormatVersionFor("openstacklogging"),
 						},
-						"xff": {
+						"response_condition": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     "append",
-							Description: "X-Forwarded-For options",
+							Default:     "",
+							Description: "Name of a condition to apply this logging.",
 						},
-						"timer_support": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Description: "Injects the X-Timer info into the request",
+						"message_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "classic",
+							Description:  "How the message should be formatted.",
+							ValidateFunc: validateLoggingMessageType,
 						},
-						"geo_headers": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Description: "Inject Fastly-Geo-Country, Fastly-Geo-City, and Fastly-Geo-Region",
+						"timestamp_format": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "%Y-%m-%dT%H:%M:%S.000",
+							Description:  "specified timestamp formatting (default `%Y-%m-%dT%H:%M:%S.000`)",
+							ValidateFunc: validateTimestampFormat,
 						},
-						"default_host": {
-							Type:        schema.TypeString,
-							Optional:    true,
-							Description: "the host header",
+						"public_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							Description:  "A PGP public key used to encrypt log files before they are written",
+							ValidateFunc: validatePGPPublicKey,
 						},
 					},
 				},
 			},
-			"vcl": {
+
+			"logshuttlelogging": {
 				Type:     schema.TypeSet,
 				Optional: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						// Required fields
 						"name": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "A name to refer to this VCL configuration",
+							Description: "Unique name to refer to this logging setup",
 						},
-						"content": {
+						"url": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The https:// URL of the Log Shuttle proxy to send logs to",
+							ValidateFunc: validateHTTPSURL,
+						},
+						"token": {
 							Type:        schema.TypeString,
 							Required:    true,
-							Description: "The contents of this VCL configuration",
-							StateFunc: func(v interface{}) string {
-								switch v.(type) {
-								case string:
-									hash := sha1.Sum([]byte(v.(string)))
-									return hex.EncodeToString(hash[:])
-								default:
-									return ""
-								}
-							},
+							Description: "The token used to authenticate with the Log Shuttle proxy",
+							Sensitive:   true,
 						},
-						"main": {
-							Type:        schema.TypeBool,
+						// Optional fields
+						"format": {
+							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     false,
-							Description: "Should this VCL configuration be the main configuration",
+							Default:     "%h %l %u %t %r %>s",
+							Description: "Apache-style string or VCL variables to use for log formatting",
+						},
+						"format_version": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      2,
+							Description:  "The version of the custom logging format used for the configured endpoint. Can be either 1 or 2. (Default: 2)",
+							ValidateFunc: validateLoggingFormatVersion,
+						},
+						"response_condition": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Name of a condition to apply this logging.",
 						},
 					},
 				},
 			},
-		},
-	}
-}
-
-func resourceServiceV1Create(d *schema.ResourceData, meta interface{}) error {
-	if err := validateVCLs(d); err != nil {
-		return err
-	}
-
-	conn := meta.(*FastlyClient).conn
-	service, err := conn.CreateService(&gofastly.CreateServiceInput{
-		Name:    d.Get("name").(string),
-		Comment: "Managed by Terraform",
-	})
-
-	if err != nil {
-		return err
-	}
-
-	d.SetId(service.ID)
-	return resourceServiceV1Update(d, meta)
-}
 
-func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
-	if err := validateVCLs(d); err != nil {
+			"response_object": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// Required
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Unique name to refer to this request object",
+							ValidateFunc: validateNotMaintenanceModeName,
+						},
+						// Optional fields
+						"status": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      200,
+							Description:  "The HTTP Status Code of the object",
+							ValidateFunc: validateHTTPStatusCode,
+						},
+						"response": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "OK",
+							Description: "The HTTP Response of the object",
+						},
+						"content": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The content to deliver for the response object",
+						},
+						"content_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The MIME type of the content",
+						},
+						"request_condition": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Name of the condition to be checked during the request phase to see if the object should be delivered",
+						},
+						"cache_condition": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Name of the condition checked after we have retrieved an object. If the condition passes then deliver this Request Object instead.",
+						},
+					},
+				},
+			},
+
+			"request_setting": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// Required fields
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Unique name to refer to this Request Setting",
+						},
+						// Optional fields
+						"request_condition": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "Name of a request condition to apply. If there is no condition this setting will always be applied.",
+						},
+						"max_stale_age": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          60,
+							Description:      "How old an object is allowed to be, in seconds. Default `60`. 0 disables serving stale content",
+							ValidateFunc:     validateMaxStaleAge,
+							DiffSuppressFunc: suppressZeroMaxStaleAge,
+						},
+						"force_miss": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Force a cache miss for the request",
+						},
+						"force_ssl": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Forces the request use SSL",
+						},
+						"action": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Allows you to terminate request handling and immediately perform an action",
+						},
+						"bypass_busy_wait": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Disable collapsed forwarding",
+						},
+						"hash_keys": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Comma separated list of varnish request object fields that should be in the hash key",
+						},
+						"hash_keys_list": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Varnish request object fields that should be in the hash key, as an alternative to hash_keys",
+						},
+						"xff": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "append",
+							Description: "X-Forwarded-For options",
+						},
+						"timer_support": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Injects the X-Timer info into the request",
+						},
+						"geo_headers": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Inject Fastly-Geo-Country, Fastly-Geo-City, and Fastly-Geo-Region",
+						},
+						"default_host": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "the host header",
+						},
+					},
+				},
+			},
+			"vcl": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "A name to refer to this VCL configuration",
+						},
+						"content": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The contents of this VCL configuration",
+							StateFunc:   hashVCLContent,
+						},
+						"main": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Should this VCL configuration be the main configuration",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// domainResource is the schema of a domain block.
+func domainResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The domain that this Service will respond to",
+				ValidateFunc: validateDomainName,
+			},
+
+			"comment": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"is_wildcard": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the domain is a wildcard, such as *.example.com",
+			},
+		},
+	}
+}
+
+// conditionPriorityAuto is the priority of a condition configured without
+// one. It cannot be configured, as priorities must not be negative, so an
+// explicit priority of 0 stays distinct from an omitted one.
+const conditionPriorityAuto = -1
+
+// conditionResource is the schema of a condition block.
+func conditionResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateNotMaintenanceModeName,
+			},
+			"statement": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The statement used to determine if the condition is met",
+				StateFunc: func(v interface{}) string {
+					value := v.(string)
+					// Trim newlines and spaces, to match Fastly API
+					return strings.TrimSpace(value)
+				},
+			},
+			"priority": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      conditionPriorityAuto,
+				Description:  "A number used to determine the order in which multiple conditions execute. Lower numbers execute first. Omit to have one assigned automatically",
+				ValidateFunc: validateNonNegativeInt,
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Type of the condition, either `REQUEST`, `RESPONSE`, or `CACHE`",
+				StateFunc: func(v interface{}) string {
+					// Some Fastly endpoints return the type lowercased
+					return strings.ToUpper(v.(string))
+				},
+			},
+			"comment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "A freeform descriptive note, for example to explain the statement",
+			},
+			"effective_priority": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The priority the condition runs at, whether configured or assigned automatically",
+			},
+		},
+	}
+}
+
+// backendResource is the schema of a backend block.
+func backendResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			// required fields
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "A name for this Backend",
+			},
+			"address": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "An IPv4, hostname, or IPv6 address for the Backend",
+			},
+			// Optional fields, defaults where they exist
+			"auto_loadbalance": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Should this Backend be load balanced",
+			},
+			"between_bytes_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     10000,
+				Description: "How long to wait between bytes in milliseconds",
+			},
+			"between_bytes_timeout_seconds": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "How long to wait between bytes in seconds, instead of between_bytes_timeout",
+				ValidateFunc: validatePositiveInt,
+			},
+			"connect_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1000,
+				Description: "How long to wait for a timeout in milliseconds",
+			},
+			"connect_timeout_seconds": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "How long to wait for a timeout in seconds, instead of connect_timeout",
+				ValidateFunc: validatePositiveInt,
+			},
+			"error_threshold": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Number of errors to allow before the Backend is marked as down",
+			},
+			"first_byte_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     15000,
+				Description: "How long to wait for the first bytes in milliseconds",
+			},
+			"first_byte_timeout_seconds": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "How long to wait for the first bytes in seconds, instead of first_byte_timeout",
+				ValidateFunc: validatePositiveInt,
+			},
+			"healthcheck": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The healthcheck name that should be used for this Backend",
+			},
+			"healthcheck_disabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Stop probing this Backend with its healthcheck, without removing the healthcheck setting",
+			},
+			"max_conn": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     200,
+				Description: "Maximum number of connections for this Backend",
+			},
+			"port": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     80,
+				Description: "The port number Backend responds on. Default 80",
+			},
+			"request_condition": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "Name of a condition, which if met, will select this backend during a request.",
+			},
+			"shield": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The POP of the shield designated to reduce inbound load.",
+			},
+			"ssl_check_cert": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Be strict on checking SSL certs",
+			},
+			"ssl_hostname": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  "SSL certificate hostname",
+				ValidateFunc: validateSSLHostname,
+			},
+			"ssl_cert_hostname": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "SSL certificate hostname for cert verification",
+			},
+			"ssl_sni_hostname": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "SSL certificate hostname for SNI verification",
+			},
+			"ssl_ca_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  "CA certificate chain in PEM format used to verify the Backend's certificate",
+				StateFunc:    func(v interface{}) string { return normalizePEMCertificates(v.(string)) },
+				ValidateFunc: validatePEMCertificates,
+			},
+			"ssl_client_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  "Client certificate in PEM format presented to the Backend for mutual TLS",
+				ValidateFunc: validatePEMCertificates,
+			},
+			"ssl_client_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Sensitive:    true,
+				Description:  "Private key in PEM format for ssl_client_cert",
+				ValidateFunc: validatePEMPrivateKey,
+			},
+			// TODO: Provide the remaining SSL fields from https://docs.fastly.com/api/config#backend
+			"use_ssl": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether or not to use SSL to reach the Backend",
+			},
+			"override_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The hostname to send in the Host header to this Backend. Defaults to ssl_cert_hostname when use_ssl is set",
+			},
+			"comment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "An optional comment about the Backend",
+			},
+			"weight": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     100,
+				Description: "The portion of traffic to send to a specific origins. Each origin receives weight/total of the traffic.",
+			},
+		},
+	}
+}
+
+func resourceServiceV1Create(d *schema.ResourceData, meta interface{}) error {
+	if err := validateServiceV1(d, meta); err != nil {
+		return err
+	}
+
+	// Claim the domains before the service exists, then hand the claim over
+	// to the service ID once it does
+	domains := &meta.(*FastlyClient).domains
+	owner := "new:" + d.Get("name").(string)
+	if err := domains.claim(owner, serviceV1DomainLabel(d), serviceV1Domains(d)); err != nil {
 		return err
 	}
+	defer domains.release(owner)
 
 	conn := meta.(*FastlyClient).conn
+	if id := d.Get("service_id_reuse").(string); id != "" {
+		reused, err := reusableServiceV1(id, d.Get("adopt_existing").(bool), meta)
+		if err != nil {
+			return err
+		}
+		if reused != nil {
+			log.Printf("[INFO] Reusing Fastly Service (%s) instead of creating a new one", reused.ID)
+			_, err := conn.UpdateService(&gofastly.UpdateServiceInput{
+				ID:      reused.ID,
+				Name:    d.Get("name").(string),
+				Comment: meta.(*FastlyClient).managementMarker,
+			})
+			if err != nil {
+				return err
+			}
+
+			d.SetId(reused.ID)
+			domains.release(owner)
+			return resourceServiceV1Update(d, meta)
+		}
+	}
+
+	service, err := conn.CreateService(&gofastly.CreateServiceInput{
+		Name:    d.Get("name").(string),
+		Comment: meta.(*FastlyClient).managementMarker,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(service.ID)
+	domains.release(owner)
+	return resourceServiceV1Update(d, meta)
+}
+
+func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
+	if err := validateServiceV1(d, meta); err != nil {
+		// Nothing was applied, so keep the previous state rather than recording
+		// the rejected configuration
+		d.Partial(true)
+		return err
+	}
+
+	if err := meta.(*FastlyClient).domains.claim(d.Id(), serviceV1DomainLabel(d), serviceV1Domains(d)); err != nil {
+		d.Partial(true)
+		return err
+	}
+
+	conn := meta.(*FastlyClient).conn
+
+	// Check the account before changing anything, so a provider configured
+	// with the wrong API key cannot modify a service it was not meant to
+	if expected := d.Get("expected_customer_id").(string); expected != "" {
+		s, err := conn.GetServiceDetails(&gofastly.GetServiceInput{
+			ID: d.Id(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := checkCustomerID(d.Id(), s.CustomerID, expected); err != nil {
+			d.Partial(true)
+			return err
+		}
+	}
 
 	// Update Name. No new verions is required for this
 	if d.HasChange("name") {
@@ -921,29 +1711,46 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 	// DefaultTTL, a new Version must be created first, and updates posted to that
 	// Version. Loop these attributes and determine if we need to create a new version first
 	var needsChange bool
-	for _, v := range []string{
-		"domain",
-		"backend",
-		"default_host",
-		"default_ttl",
-		"header",
-		"gzip",
-		"healthcheck",
-		"s3logging",
-		"papertrail",
-		"response_object",
-		"condition",
-		"request_setting",
-		"cache_setting",
-		"vcl",
-	} {
+	for _, v := range append([]string{"default_host", "default_ttl"}, serviceV1SetBlocks...) {
 		if d.HasChange(v) {
 			needsChange = true
 		}
 	}
+	// The maintenance page only matters while it is served
+	maintenanceChange := d.HasChange("maintenance_mode") ||
+		(d.Get("maintenance_mode").(bool) && d.HasChange("maintenance_content"))
+	if maintenanceChange {
+		needsChange = true
+	}
 
+	var activatedVersion int
 	if needsChange {
+		adopt := d.Get("adopt_existing").(bool)
 		latestVersion := d.Get("active_version").(int)
+		if !d.IsNewResource() {
+			// Clone whatever is active now. A version activated outside Terraform
+			// since the last refresh would otherwise be silently rolled back.
+			s, err := conn.GetServiceDetails(&gofastly.GetServiceInput{
+				ID: d.Id(),
+			})
+			if err != nil {
+				return err
+			}
+			if s.ActiveVersion.Number != latestVersion {
+				log.Printf("[WARN] Active version of Fastly Service (%s) is %d, not %d as in state; it was activated outside Terraform",
+					d.Id(), s.ActiveVersion.Number, latestVersion)
+				latestVersion = s.ActiveVersion.Number
+			}
+			if latestVersion == 0 {
+				// Nothing is active, for example after a failed first activation.
+				// Version 1 may be locked by then, so clone the latest version.
+				for _, v := range s.Versions {
+					if v.Number > latestVersion {
+						latestVersion = v.Number
+					}
+				}
+			}
+		}
 		if latestVersion == 0 {
 			// If the service was just created, there is an empty Version 1 available
 			// that is unlocked and can be updated
@@ -965,8 +1772,20 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			// New versions are not immediately found in the API, or are not
 			// immediately mutable, so we need to sleep a few and let Fastly ready
 			// itself. Typically, 7 seconds is enough
-			log.Print("[DEBUG] Sleeping 7 seconds to allow Fastly Version to be available")
-			time.Sleep(7 * time.Second)
+			log.Printf("[DEBUG] Sleeping %s to allow Fastly Version to be available", versionAvailableDelay)
+			time.Sleep(versionAvailableDelay)
+		}
+
+		if err := updateServiceV1VersionComment(d, conn, latestVersion); err != nil {
+			return err
+		}
+
+		// Summarize blocks without changes; blocks with changes are summarized
+		// as they are applied below
+		for _, block := range serviceV1SetBlocks {
+			if !d.HasChange(block) {
+				log.Printf("[INFO] %s: no changes", block)
+			}
 		}
 
 		// update general settings
@@ -990,8 +1809,14 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			}
 		}
 
-		// Conditions need to be updated first, as they can be referenced by other
-		// configuraiton objects (Backends, Request Headers, etc)
+		// Objects can reference each other within a version (a backend names
+		// its healthcheck, a header its conditions), and Fastly rejects deleting
+		// an object that is still referenced. Each block below therefore only
+		// queues its deletes and creates. The blocks are listed in dependency
+		// order: creates run in that order, so conditions exist before anything
+		// that uses them, and deletes run in the reverse order, so referencing
+		// objects are gone before the objects they reference.
+		var deletes, creates []func() error
 
 		// Find difference in Conditions
 		if d.HasChange("condition") {
@@ -1013,99 +1838,126 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			ncs := nc.(*schema.Set)
 			removeConditions := ocs.Difference(ncs).List()
 			addConditions := ncs.Difference(ocs).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("condition", removeConditions, addConditions))
 
-			// DELETE old Conditions
-			for _, cRaw := range removeConditions {
+			// Conditions that are not being replaced keep the priority they were
+			// given, so newly assigned priorities cannot collide with them.
+			kept := make(map[string]int)
+			for _, cRaw := range ocs.Intersection(ncs).List() {
 				cf := cRaw.(map[string]interface{})
-				opts := gofastly.DeleteConditionInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    cf["name"].(string),
-				}
+				kept[cf["name"].(string)] = cf["effective_priority"].(int)
+			}
+			priorities := assignConditionPriorities(ncs.List(), kept)
 
-				log.Printf("[DEBUG] Fastly Conditions Removal opts: %#v", opts)
-				err := conn.DeleteCondition(&opts)
-				if err != nil {
-					return err
+			// DELETE old Conditions
+			deletes = append(deletes, func() error {
+				for _, cRaw := range removeConditions {
+					cf := cRaw.(map[string]interface{})
+					name := cf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Condition removal: %s", name)
+					err := adapter.DeleteCondition(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return conditionDeleteError(conn, d.Id(), latestVersion, name, err)
+					}
 				}
-			}
+				return nil
+			})
 
 			// POST new Conditions
-			for _, cRaw := range addConditions {
-				cf := cRaw.(map[string]interface{})
-				opts := gofastly.CreateConditionInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    cf["name"].(string),
-					Type:    cf["type"].(string),
-					// need to trim leading/tailing spaces, incase the config has HEREDOC
-					// formatting and contains a trailing new line
-					Statement: strings.TrimSpace(cf["statement"].(string)),
-					Priority:  cf["priority"].(int),
+			creates = append(creates, func() error {
+				for _, cRaw := range addConditions {
+					cf := cRaw.(map[string]interface{})
+					opts, err := buildCondition(cf)
+					if err != nil {
+						return err
+					}
+					opts.Priority = priorities[opts.Name]
+
+					log.Printf("[DEBUG] Create Conditions Opts: %#v", opts)
+					err = adapter.CreateCondition(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "condition", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
+
+					// go-fastly's CreateConditionInput has no comment
+					if comment := cf["comment"].(string); comment != "" {
+						log.Printf("[DEBUG] condition: setting comment of %q", opts.Name)
+						if err := updateConditionComment(conn, d.Id(), latestVersion, opts.Name, comment); err != nil {
+							return err
+						}
+					}
 				}
 
-				log.Printf("[DEBUG] Create Conditions Opts: %#v", opts)
-				_, err := conn.CreateCondition(&opts)
-				if err != nil {
-					return err
+				// Logging endpoints are created right after, and Fastly rejects
+				// those naming a condition it cannot see yet
+				for _, name := range newLoggingConditions(d, addConditions) {
+					if err := waitForCondition(conn, d.Id(), latestVersion, name); err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
-		// Find differences in domains
-		if d.HasChange("domain") {
-			od, nd := d.GetChange("domain")
-			if od == nil {
-				od = new(schema.Set)
-			}
-			if nd == nil {
-				nd = new(schema.Set)
-			}
-
-			ods := od.(*schema.Set)
-			nds := nd.(*schema.Set)
+		// Find differences in domains, in whichever form they are configured
+		if d.HasChange("domain") || d.HasChange("domains") {
+			ods, nds := serviceV1DomainChange(d)
 
 			remove := ods.Difference(nds).List()
 			add := nds.Difference(ods).List()
+			block := "domain"
+			if d.HasChange("domains") {
+				block = "domains"
+			}
+			log.Printf("[INFO] %s", summarizeSetChanges(block, remove, add))
 
 			// Delete removed domains
-			for _, dRaw := range remove {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.DeleteDomainInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
-				}
+			deletes = append(deletes, func() error {
+				for _, dRaw := range remove {
+					df := dRaw.(map[string]interface{})
+					opts := gofastly.DeleteDomainInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    df["name"].(string),
+					}
 
-				log.Printf("[DEBUG] Fastly Domain removal opts: %#v", opts)
-				err := conn.DeleteDomain(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Fastly Domain removal opts: %#v", opts)
+					err := conn.DeleteDomain(&opts)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
 			// POST new Domains
-			for _, dRaw := range add {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.CreateDomainInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
-				}
+			creates = append(creates, func() error {
+				for _, dRaw := range add {
+					df := dRaw.(map[string]interface{})
+					opts := gofastly.CreateDomainInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    df["name"].(string),
+					}
 
-				if v, ok := df["comment"]; ok {
-					opts.Comment = v.(string)
-				}
+					if v, ok := df["comment"]; ok {
+						opts.Comment = v.(string)
+					}
 
-				log.Printf("[DEBUG] Fastly Domain Addition opts: %#v", opts)
-				_, err := conn.CreateDomain(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Fastly Domain Addition opts: %#v", opts)
+					_, err := conn.CreateDomain(&opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "domain", opts.Name), &opts)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
-		// Healthchecks need to be updated BEFORE backends
+		// Healthchecks are created before backends and deleted after them
 		if d.HasChange("healthcheck") {
 			oh, nh := d.GetChange("healthcheck")
 			if oh == nil {
@@ -1119,49 +1971,73 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			nhs := nh.(*schema.Set)
 			removeHealthCheck := ohs.Difference(nhs).List()
 			addHealthCheck := nhs.Difference(ohs).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("healthcheck", removeHealthCheck, addHealthCheck))
 
 			// DELETE old healthcheck configurations
-			for _, hRaw := range removeHealthCheck {
-				hf := hRaw.(map[string]interface{})
-				opts := gofastly.DeleteHealthCheckInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    hf["name"].(string),
-				}
+			deletes = append(deletes, func() error {
+				for _, hRaw := range removeHealthCheck {
+					hf := hRaw.(map[string]interface{})
+					opts := gofastly.DeleteHealthCheckInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    hf["name"].(string),
+					}
 
-				log.Printf("[DEBUG] Fastly Healthcheck removal opts: %#v", opts)
-				err := conn.DeleteHealthCheck(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Fastly Healthcheck removal opts: %#v", opts)
+					err := conn.DeleteHealthCheck(&opts)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
 			// POST new/updated Healthcheck
-			for _, hRaw := range addHealthCheck {
-				hf := hRaw.(map[string]interface{})
-
-				opts := gofastly.CreateHealthCheckInput{
-					Service:          d.Id(),
-					Version:          latestVersion,
-					Name:             hf["name"].(string),
-					Host:             hf["host"].(string),
-					Path:             hf["path"].(string),
-					CheckInterval:    uint(hf["check_interval"].(int)),
-					ExpectedResponse: uint(hf["expected_response"].(int)),
-					HTTPVersion:      hf["http_version"].(string),
-					Initial:          uint(hf["initial"].(int)),
-					Method:           hf["method"].(string),
-					Threshold:        uint(hf["threshold"].(int)),
-					Timeout:          uint(hf["timeout"].(int)),
-					Window:           uint(hf["window"].(int)),
-				}
+			creates = append(creates, func() error {
+				for _, hRaw := range addHealthCheck {
+					hf := hRaw.(map[string]interface{})
+
+					opts := gofastly.CreateHealthCheckInput{
+						Service:          d.Id(),
+						Version:          latestVersion,
+						Name:             hf["name"].(string),
+						Host:             hf["host"].(string),
+						Path:             hf["path"].(string),
+						CheckInterval:    uint(hf["check_interval"].(int)),
+						ExpectedResponse: uint(hf["expected_response"].(int)),
+						HTTPVersion:      hf["http_version"].(string),
+						Initial:          uint(hf["initial"].(int)),
+						Method:           hf["method"].(string),
+						Threshold:        uint(hf["threshold"].(int)),
+						Timeout:          uint(hf["timeout"].(int)),
+						Window:           uint(hf["window"].(int)),
+					}
 
-				log.Printf("[DEBUG] Create Healthcheck Opts: %#v", opts)
-				_, err := conn.CreateHealthCheck(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create Healthcheck Opts: %#v", opts)
+					_, err := conn.CreateHealthCheck(&opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "healthcheck", opts.Name), &opts)
+					if err != nil {
+						return err
+					}
+
+					// go-fastly's CreateHealthCheckInput has no comment
+					if comment := hf["comment"].(string); comment != "" {
+						log.Printf("[DEBUG] healthcheck: setting comment of %q", opts.Name)
+						if err := updateHealthCheckComment(conn, d.Id(), latestVersion, opts.Name, comment); err != nil {
+							return err
+						}
+					}
+
+					// nor headers
+					if headers := healthCheckHeaders(hf["headers"]); len(headers) > 0 {
+						log.Printf("[DEBUG] healthcheck: setting headers of %q", opts.Name)
+						if err := updateHealthCheckHeaders(conn, d.Id(), latestVersion, opts.Name, headers); err != nil {
+							return err
+						}
+					}
 				}
-			}
+				return nil
+			})
 		}
 
 		// find difference in backends
@@ -1179,53 +2055,127 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			removeBackends := obs.Difference(nbs).List()
 			addBackends := nbs.Difference(obs).List()
 
+			// Backends whose only change is healthcheck_disabled are updated in
+			// place rather than recreated. Detaching a healthcheck is queued with
+			// the deletes, attaching one with the creates.
+			toggleBackends, removeBackends, addBackends := splitBackendHealthCheckToggles(removeBackends, addBackends)
+			log.Printf("[INFO] %s", summarizeSetChanges("backend", removeBackends, addBackends))
+			var detachBackends, attachBackends []map[string]interface{}
+			for _, bf := range toggleBackends {
+				if bf["healthcheck_disabled"].(bool) {
+					detachBackends = append(detachBackends, bf)
+				} else {
+					attachBackends = append(attachBackends, bf)
+				}
+			}
+
 			// DELETE old Backends
-			for _, bRaw := range removeBackends {
-				bf := bRaw.(map[string]interface{})
-				opts := gofastly.DeleteBackendInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    bf["name"].(string),
+			deletes = append(deletes, func() error {
+				for _, bf := range detachBackends {
+					log.Printf("[INFO] backend: removing healthcheck from %q", bf["name"].(string))
+					err := updateBackendHealthCheck(conn, d.Id(), latestVersion, bf["name"].(string), "")
+					if err != nil {
+						return err
+					}
 				}
 
-				log.Printf("[DEBUG] Fastly Backend removal opts: %#v", opts)
-				err := conn.DeleteBackend(&opts)
-				if err != nil {
-					return err
+				return forEachParallel(removeBackends, func(bRaw interface{}) error {
+					bf := bRaw.(map[string]interface{})
+					name := bf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Backend removal: %s", name)
+					return adapter.DeleteBackend(conn, d.Id(), latestVersion, name)
+				})
+			})
+
+			// Find and post new Backends
+			creates = append(creates, func() error {
+				for _, bf := range attachBackends {
+					healthcheck := bf["healthcheck"].(string)
+					log.Printf("[INFO] backend: updating healthcheck of %q to %q", bf["name"].(string), healthcheck)
+					err := updateBackendHealthCheck(conn, d.Id(), latestVersion, bf["name"].(string), healthcheck)
+					if err != nil {
+						return err
+					}
 				}
+
+				return forEachParallel(addBackends, func(dRaw interface{}) error {
+					df := dRaw.(map[string]interface{})
+					opts, err := buildBackend(df)
+					if err != nil {
+						return err
+					}
+
+					log.Printf("[DEBUG] Create Backend Opts: %s", redactSensitive("backend", df, opts))
+					err = adapter.CreateBackend(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "backend", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
+
+					// go-fastly's CreateBackendInput has no override_host or comment
+					override := &backendOverride{
+						OverrideHost: backendOverrideHost(df),
+						Comment:      df["comment"].(string),
+					}
+					if override.OverrideHost != "" || override.Comment != "" {
+						log.Printf("[DEBUG] backend: setting override_host of %q to %q, comment to %q", opts.Name, override.OverrideHost, override.Comment)
+						return updateBackendOverride(conn, d.Id(), latestVersion, opts.Name, override)
+					}
+					return nil
+				})
+			})
+		}
+
+		// Pools are updated in place rather than recreated, so that their IDs,
+		// and the servers attached to them, survive the change.
+		if d.HasChange("pool") {
+			op, np := d.GetChange("pool")
+			if op == nil {
+				op = new(schema.Set)
+			}
+			if np == nil {
+				np = new(schema.Set)
 			}
 
-			// Find and post new Backends
-			for _, dRaw := range addBackends {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.CreateBackendInput{
-					Service:             d.Id(),
-					Version:             latestVersion,
-					Name:                df["name"].(string),
-					Address:             df["address"].(string),
-					AutoLoadbalance:     gofastly.CBool(df["auto_loadbalance"].(bool)),
-					SSLCheckCert:        gofastly.CBool(df["ssl_check_cert"].(bool)),
-					SSLHostname:         df["ssl_hostname"].(string),
-					SSLCertHostname:     df["ssl_cert_hostname"].(string),
-					SSLSNIHostname:      df["ssl_sni_hostname"].(string),
-					Shield:              df["shield"].(string),
-					Port:                uint(df["port"].(int)),
-					BetweenBytesTimeout: uint(df["between_bytes_timeout"].(int)),
-					ConnectTimeout:      uint(df["connect_timeout"].(int)),
-					ErrorThreshold:      uint(df["error_threshold"].(int)),
-					FirstByteTimeout:    uint(df["first_byte_timeout"].(int)),
-					MaxConn:             uint(df["max_conn"].(int)),
-					Weight:              uint(df["weight"].(int)),
-					RequestCondition:    df["request_condition"].(string),
-					HealthCheck:         df["healthcheck"].(string),
+			removePools, addPools, changePools := diffByKey(op.(*schema.Set).List(), np.(*schema.Set).List(), poolKey, "pool_id")
+			log.Printf("[INFO] pool: removing %d, adding %d, updating %d", len(removePools), len(addPools), len(changePools))
+
+			// DELETE old pools
+			deletes = append(deletes, func() error {
+				for _, pf := range removePools {
+					name := pf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Pool removal: %s", name)
+					if err := deletePool(conn, d.Id(), latestVersion, name); err != nil {
+						return err
+					}
 				}
+				return nil
+			})
 
-				log.Printf("[DEBUG] Create Backend Opts: %#v", opts)
-				_, err := conn.CreateBackend(&opts)
-				if err != nil {
-					return err
+			// POST new and PUT updated pools
+			creates = append(creates, func() error {
+				for _, pc := range changePools {
+					opts := buildPool(pc.New)
+
+					log.Printf("[DEBUG] Update Pool Opts: %#v", opts)
+					if err := updatePool(conn, d.Id(), latestVersion, pc.Old["name"].(string), opts); err != nil {
+						return err
+					}
 				}
-			}
+				for _, pf := range addPools {
+					opts := buildPool(pf)
+
+					log.Printf("[DEBUG] Create Pool Opts: %#v", opts)
+					err := createPool(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "pool", opts.Name), opts)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 		}
 
 		if d.HasChange("header") {
@@ -1242,39 +2192,83 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			remove := ohs.Difference(nhs).List()
 			add := nhs.Difference(ohs).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("header", remove, add))
 
 			// Delete removed headers
-			for _, dRaw := range remove {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.DeleteHeaderInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
-				}
+			deletes = append(deletes, func() error {
+				return forEachParallel(remove, func(dRaw interface{}) error {
+					df := dRaw.(map[string]interface{})
+					name := df["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Header removal: %s", name)
+					return adapter.DeleteHeader(conn, d.Id(), latestVersion, name)
+				})
+			})
 
-				log.Printf("[DEBUG] Fastly Header removal opts: %#v", opts)
-				err := conn.DeleteHeader(&opts)
-				if err != nil {
-					return err
-				}
+			// POST new Headers
+			creates = append(creates, func() error {
+				return forEachParallel(add, func(dRaw interface{}) error {
+					opts, err := buildHeader(dRaw.(map[string]interface{}))
+					if err != nil {
+						log.Printf("[DEBUG] Error building Header: %s", err)
+						return err
+					}
+
+					log.Printf("[DEBUG] Fastly Header Addition opts: %#v", opts)
+					err = adapter.CreateHeader(conn, d.Id(), latestVersion, opts)
+					return adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "header", opts.Name), opts.Form(d.Id(), latestVersion))
+				})
+			})
+		}
+
+		if d.HasChange("surrogate_key") {
+			osk, nsk := d.GetChange("surrogate_key")
+			if osk == nil {
+				osk = new(schema.Set)
+			}
+			if nsk == nil {
+				nsk = new(schema.Set)
 			}
 
-			// POST new Headers
-			for _, dRaw := range add {
-				opts, err := buildHeader(dRaw.(map[string]interface{}))
-				if err != nil {
-					log.Printf("[DEBUG] Error building Header: %s", err)
-					return err
+			osks := osk.(*schema.Set)
+			nsks := nsk.(*schema.Set)
+
+			remove := osks.Difference(nsks).List()
+			add := nsks.Difference(osks).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("surrogate_key", remove, add))
+
+			// Delete the headers of removed surrogate keys
+			deletes = append(deletes, func() error {
+				for _, sRaw := range remove {
+					sf := sRaw.(map[string]interface{})
+					name := surrogateKeyHeaderPrefix + sf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Surrogate-Key header removal: %s", name)
+					err := adapter.DeleteHeader(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
 				}
-				opts.Service = d.Id()
-				opts.Version = latestVersion
+				return nil
+			})
 
-				log.Printf("[DEBUG] Fastly Header Addition opts: %#v", opts)
-				_, err = conn.CreateHeader(opts)
-				if err != nil {
-					return err
+			// POST the headers of new surrogate keys
+			creates = append(creates, func() error {
+				for _, sRaw := range add {
+					opts, err := buildSurrogateKeyHeader(sRaw)
+					if err != nil {
+						return err
+					}
+
+					log.Printf("[DEBUG] Fastly Surrogate-Key header addition opts: %#v", opts)
+					err = adapter.CreateHeader(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "header", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
 		// Find differences in Gzips
@@ -1293,63 +2287,295 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			remove := ogs.Difference(ngs).List()
 			add := ngs.Difference(ogs).List()
 
+			// Gzips kept under the same name are updated in place, so compression
+			// is not missing from the version if a later step fails
+			update, remove, add := splitGzipUpdates(remove, add)
+			log.Printf("[INFO] %s", summarizeSetChanges("gzip", remove, add))
+
 			// Delete removed gzip rules
-			for _, dRaw := range remove {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.DeleteGzipInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
+			deletes = append(deletes, func() error {
+				for _, dRaw := range remove {
+					df := dRaw.(map[string]interface{})
+					opts := gofastly.DeleteGzipInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    df["name"].(string),
+					}
+
+					log.Printf("[DEBUG] Fastly Gzip removal opts: %#v", opts)
+					err := conn.DeleteGzip(&opts)
+					if err != nil {
+						return err
+					}
 				}
+				return nil
+			})
 
-				log.Printf("[DEBUG] Fastly Gzip removal opts: %#v", opts)
-				err := conn.DeleteGzip(&opts)
-				if err != nil {
-					return err
+			// PUT updated and POST new Gzips
+			creates = append(creates, func() error {
+				for _, opts := range update {
+					opts.Service = d.Id()
+					opts.Version = latestVersion
+
+					log.Printf("[DEBUG] Fastly Gzip update opts: %#v", opts)
+					_, err := conn.UpdateGzip(opts)
+					if err != nil {
+						return err
+					}
+				}
+
+				for _, dRaw := range add {
+					opts, err := buildGzip(dRaw)
+					if err != nil {
+						return err
+					}
+					opts.Service = d.Id()
+					opts.Version = latestVersion
+
+					log.Printf("[DEBUG] Fastly Gzip Addition opts: %#v", opts)
+					_, err = conn.CreateGzip(opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "gzip", opts.Name), opts)
+					if err != nil {
+						return err
+					}
 				}
+				return nil
+			})
+		}
+
+		// find difference in s3logging
+		if d.HasChange("s3logging") {
+			os, ns := d.GetChange("s3logging")
+			if os == nil {
+				os = new(schema.Set)
+			}
+			if ns == nil {
+				ns = new(schema.Set)
 			}
 
-			// POST new Gzips
-			for _, dRaw := range add {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.CreateGzipInput{
-					Service:        d.Id(),
-					Version:        latestVersion,
-					Name:           df["name"].(string),
-					CacheCondition: df["cache_condition"].(string),
+			oss := os.(*schema.Set)
+			nss := ns.(*schema.Set)
+			removeS3Logging := oss.Difference(nss).List()
+			addS3Logging := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("s3logging", removeS3Logging, addS3Logging))
+
+			// DELETE old S3 Log configurations
+			deletes = append(deletes, func() error {
+				for _, sRaw := range removeS3Logging {
+					sf := sRaw.(map[string]interface{})
+					name := sf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly S3 Logging removal: %s", name)
+					err := adapter.DeleteS3(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
 				}
+				return nil
+			})
 
-				if v, ok := df["content_types"]; ok {
-					if len(v.(*schema.Set).List()) > 0 {
-						var cl []string
-						for _, c := range v.(*schema.Set).List() {
-							cl = append(cl, c.(string))
+			// POST new/updated S3 Logging
+			creates = append(creates, func() error {
+				s3Defaults := meta.(*FastlyClient).loggingDefaults["s3logging"]
+				for _, sRaw := range addS3Logging {
+					sf := withLoggingDefaults(sRaw.(map[string]interface{}), s3Defaults)
+
+					// Fastly API will not error if these are omitted, so we throw an error
+					// if any of these are empty
+					for _, sk := range []string{"s3_access_key", "s3_secret_key"} {
+						if sf[sk].(string) == "" {
+							return fmt.Errorf("[ERR] No %s found for S3 Log stream setup for Service (%s)", sk, d.Id())
 						}
-						opts.ContentTypes = strings.Join(cl, " ")
+					}
+
+					opts, err := buildS3(sf)
+					if err != nil {
+						return err
+					}
+
+					log.Printf("[DEBUG] Create S3 Logging Opts: %s", redactSensitive("s3logging", sf, opts))
+					err = adapter.CreateS3(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/s3", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+
+		// find difference in Papertrail
+		if d.HasChange("papertrail") {
+			os, ns := d.GetChange("papertrail")
+			if os == nil {
+				os = new(schema.Set)
+			}
+			if ns == nil {
+				ns = new(schema.Set)
+			}
+
+			oss := os.(*schema.Set)
+			nss := ns.(*schema.Set)
+			removePapertrail := oss.Difference(nss).List()
+			addPapertrail := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("papertrail", removePapertrail, addPapertrail))
+
+			// DELETE old papertrail configurations
+			deletes = append(deletes, func() error {
+				for _, pRaw := range removePapertrail {
+					pf := pRaw.(map[string]interface{})
+					name := pf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Papertrail removal: %s", name)
+					err := adapter.DeletePapertrail(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			// POST new/updated Papertrail
+			creates = append(creates, func() error {
+				for _, pRaw := range addPapertrail {
+					pf := pRaw.(map[string]interface{})
+
+					opts, err := buildPapertrail(pf)
+					if err != nil {
+						return err
+					}
+
+					log.Printf("[DEBUG] Create Papertrail Opts: %#v", opts)
+					err = adapter.CreatePapertrail(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/papertrail", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+
+		// find difference in Sumologic
+		if d.HasChange("sumologic") {
+			os, ns := d.GetChange("sumologic")
+			if os == nil {
+				os = new(schema.Set)
+			}
+			if ns == nil {
+				ns = new(schema.Set)
+			}
+
+			oss := os.(*schema.Set)
+			nss := ns.(*schema.Set)
+			removeSumologic := oss.Difference(nss).List()
+			addSumologic := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("sumologic", removeSumologic, addSumologic))
+
+			// DELETE old sumologic configurations
+			deletes = append(deletes, func() error {
+				for _, pRaw := range removeSumologic {
+					sf := pRaw.(map[string]interface{})
+					name := sf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Sumologic removal: %s", name)
+					err := adapter.DeleteSumologic(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
 					}
 				}
+				return nil
+			})
+
+			// POST new/updated Sumologic
+			creates = append(creates, func() error {
+				for _, pRaw := range addSumologic {
+					sf := pRaw.(map[string]interface{})
+					opts, err := buildSumologic(sf)
+					if err != nil {
+						return err
+					}
 
-				if v, ok := df["extensions"]; ok {
-					if len(v.(*schema.Set).List()) > 0 {
-						var el []string
-						for _, e := range v.(*schema.Set).List() {
-							el = append(el, e.(string))
+					log.Printf("[DEBUG] Create Sumologic Opts: %s", redactSensitive("sumologic", sf, opts))
+					err = adapter.CreateSumologic(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/sumologic", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
+					if opts.Format == "" {
+						if err := updateSumologicFormat(conn, d.Id(), latestVersion, opts.Name, ""); err != nil {
+							return err
 						}
-						opts.Extensions = strings.Join(el, " ")
 					}
 				}
+				return nil
+			})
+		}
 
-				log.Printf("[DEBUG] Fastly Gzip Addition opts: %#v", opts)
-				_, err := conn.CreateGzip(&opts)
-				if err != nil {
-					return err
+		// find difference in gcslogging
+		if d.HasChange("gcslogging") {
+			os, ns := d.GetChange("gcslogging")
+			if os == nil {
+				os = new(schema.Set)
+			}
+			if ns == nil {
+				ns = new(schema.Set)
+			}
+
+			oss := os.(*schema.Set)
+			nss := ns.(*schema.Set)
+			removeGcslogging := oss.Difference(nss).List()
+			addGcslogging := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("gcslogging", removeGcslogging, addGcslogging))
+
+			// DELETE old gcslogging configurations
+			deletes = append(deletes, func() error {
+				for _, pRaw := range removeGcslogging {
+					sf := pRaw.(map[string]interface{})
+					name := sf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly gcslogging removal: %s", name)
+					err := adapter.DeleteGCS(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			// POST new/updated gcslogging
+			creates = append(creates, func() error {
+				gcsDefaults := meta.(*FastlyClient).loggingDefaults["gcslogging"]
+				for _, pRaw := range addGcslogging {
+					sf := withLoggingDefaults(pRaw.(map[string]interface{}), gcsDefaults)
+
+					// As with S3, Fastly accepts an endpoint without credentials, so
+					// catch a missing default here
+					for _, sk := range []string{"email", "secret_key"} {
+						if sf[sk].(string) == "" {
+							return fmt.Errorf("[ERR] No %s found for GCS Log stream setup for Service (%s)", sk, d.Id())
+						}
+					}
+
+					opts, err := buildGCS(sf)
+					if err != nil {
+						return err
+					}
+
+					log.Printf("[DEBUG] Create GCS Opts: %s", redactSensitive("gcslogging", sf, opts))
+					err = adapter.CreateGCS(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/gcs", opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
-		// find difference in s3logging
-		if d.HasChange("s3logging") {
-			os, ns := d.GetChange("s3logging")
+		// find difference in Loki
+		if d.HasChange("loki") {
+			os, ns := d.GetChange("loki")
 			if os == nil {
 				os = new(schema.Set)
 			}
@@ -1359,65 +2585,48 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			oss := os.(*schema.Set)
 			nss := ns.(*schema.Set)
-			removeS3Logging := oss.Difference(nss).List()
-			addS3Logging := nss.Difference(oss).List()
-
-			// DELETE old S3 Log configurations
-			for _, sRaw := range removeS3Logging {
-				sf := sRaw.(map[string]interface{})
-				opts := gofastly.DeleteS3Input{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    sf["name"].(string),
-				}
-
-				log.Printf("[DEBUG] Fastly S3 Logging removal opts: %#v", opts)
-				err := conn.DeleteS3(&opts)
-				if err != nil {
-					return err
-				}
-			}
-
-			// POST new/updated S3 Logging
-			for _, sRaw := range addS3Logging {
-				sf := sRaw.(map[string]interface{})
-
-				// Fastly API will not error if these are omitted, so we throw an error
-				// if any of these are empty
-				for _, sk := range []string{"s3_access_key", "s3_secret_key"} {
-					if sf[sk].(string) == "" {
-						return fmt.Errorf("[ERR] No %s found for S3 Log stream setup for Service (%s)", sk, d.Id())
+			removeLoki := oss.Difference(nss).List()
+			addLoki := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("loki", removeLoki, addLoki))
+
+			// DELETE old Loki configurations
+			deletes = append(deletes, func() error {
+				for _, lRaw := range removeLoki {
+					lf := lRaw.(map[string]interface{})
+					name := lf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Loki removal: %s", name)
+					err := adapter.DeleteLoki(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
 					}
 				}
+				return nil
+			})
 
-				opts := gofastly.CreateS3Input{
-					Service:           d.Id(),
-					Version:           latestVersion,
-					Name:              sf["name"].(string),
-					BucketName:        sf["bucket_name"].(string),
-					AccessKey:         sf["s3_access_key"].(string),
-					SecretKey:         sf["s3_secret_key"].(string),
-					Period:            uint(sf["period"].(int)),
-					GzipLevel:         uint(sf["gzip_level"].(int)),
-					Domain:            sf["domain"].(string),
-					Path:              sf["path"].(string),
-					Format:            sf["format"].(string),
-					FormatVersion:     uint(sf["format_version"].(int)),
-					TimestampFormat:   sf["timestamp_format"].(string),
-					ResponseCondition: sf["response_condition"].(string),
-				}
+			// POST new/updated Loki
+			creates = append(creates, func() error {
+				for _, lRaw := range addLoki {
+					lf := lRaw.(map[string]interface{})
+					opts, err := buildLoki(lf)
+					if err != nil {
+						return err
+					}
 
-				log.Printf("[DEBUG] Create S3 Logging Opts: %#v", opts)
-				_, err := conn.CreateS3(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create Loki Opts: %s", redactSensitive("loki", lf, opts))
+					err = adapter.CreateLoki(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/"+loggingBlockEndpoints["loki"], opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
-		// find difference in Papertrail
-		if d.HasChange("papertrail") {
-			os, ns := d.GetChange("papertrail")
+		// find difference in HTTPS logging
+		if d.HasChange("httpslogging") {
+			os, ns := d.GetChange("httpslogging")
 			if os == nil {
 				os = new(schema.Set)
 			}
@@ -1427,50 +2636,48 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			oss := os.(*schema.Set)
 			nss := ns.(*schema.Set)
-			removePapertrail := oss.Difference(nss).List()
-			addPapertrail := nss.Difference(oss).List()
-
-			// DELETE old papertrail configurations
-			for _, pRaw := range removePapertrail {
-				pf := pRaw.(map[string]interface{})
-				opts := gofastly.DeletePapertrailInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    pf["name"].(string),
-				}
-
-				log.Printf("[DEBUG] Fastly Papertrail removal opts: %#v", opts)
-				err := conn.DeletePapertrail(&opts)
-				if err != nil {
-					return err
+			removeHTTPSLogging := oss.Difference(nss).List()
+			addHTTPSLogging := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("httpslogging", removeHTTPSLogging, addHTTPSLogging))
+
+			// DELETE old HTTPS logging configurations
+			deletes = append(deletes, func() error {
+				for _, hRaw := range removeHTTPSLogging {
+					hf := hRaw.(map[string]interface{})
+					name := hf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly HTTPS logging removal: %s", name)
+					err := adapter.DeleteHTTPS(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
-			// POST new/updated Papertrail
-			for _, pRaw := range addPapertrail {
-				pf := pRaw.(map[string]interface{})
-
-				opts := gofastly.CreatePapertrailInput{
-					Service:           d.Id(),
-					Version:           latestVersion,
-					Name:              pf["name"].(string),
-					Address:           pf["address"].(string),
-					Port:              uint(pf["port"].(int)),
-					Format:            pf["format"].(string),
-					ResponseCondition: pf["response_condition"].(string),
-				}
+			// POST new/updated HTTPS logging
+			creates = append(creates, func() error {
+				for _, hRaw := range addHTTPSLogging {
+					hf := hRaw.(map[string]interface{})
+					opts, err := buildHTTPSLogging(hf)
+					if err != nil {
+						return err
+					}
 
-				log.Printf("[DEBUG] Create Papertrail Opts: %#v", opts)
-				_, err := conn.CreatePapertrail(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create HTTPS logging Opts: %s", redactSensitive("httpslogging", hf, opts))
+					err = adapter.CreateHTTPS(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/"+loggingBlockEndpoints["httpslogging"], opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
-		// find difference in Sumologic
-		if d.HasChange("sumologic") {
-			os, ns := d.GetChange("sumologic")
+		// find difference in OpenStack logging
+		if d.HasChange("openstacklogging") {
+			os, ns := d.GetChange("openstacklogging")
 			if os == nil {
 				os = new(schema.Set)
 			}
@@ -1480,50 +2687,48 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			oss := os.(*schema.Set)
 			nss := ns.(*schema.Set)
-			removeSumologic := oss.Difference(nss).List()
-			addSumologic := nss.Difference(oss).List()
-
-			// DELETE old sumologic configurations
-			for _, pRaw := range removeSumologic {
-				sf := pRaw.(map[string]interface{})
-				opts := gofastly.DeleteSumologicInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    sf["name"].(string),
-				}
-
-				log.Printf("[DEBUG] Fastly Sumologic removal opts: %#v", opts)
-				err := conn.DeleteSumologic(&opts)
-				if err != nil {
-					return err
+			removeOpenstackLogging := oss.Difference(nss).List()
+			addOpenstackLogging := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("openstacklogging", removeOpenstackLogging, addOpenstackLogging))
+
+			// DELETE old OpenStack logging configurations
+			deletes = append(deletes, func() error {
+				for _, oRaw := range removeOpenstackLogging {
+					of := oRaw.(map[string]interface{})
+					name := of["name"].(string)
+
+					log.Printf("[DEBUG] Fastly OpenStack logging removal: %s", name)
+					err := adapter.DeleteOpenStack(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
-			// POST new/updated Sumologic
-			for _, pRaw := range addSumologic {
-				sf := pRaw.(map[string]interface{})
-				opts := gofastly.CreateSumologicInput{
-					Service:           d.Id(),
-					Version:           latestVersion,
-					Name:              sf["name"].(string),
-					URL:               sf["url"].(string),
-					Format:            sf["format"].(string),
-					FormatVersion:     sf["format_version"].(int),
-					ResponseCondition: sf["response_condition"].(string),
-					MessageType:       sf["message_type"].(string),
-				}
+			// POST new/updated OpenStack logging
+			creates = append(creates, func() error {
+				for _, oRaw := range addOpenstackLogging {
+					of := oRaw.(map[string]interface{})
+					opts, err := buildOpenstackLogging(of)
+					if err != nil {
+						return err
+					}
 
-				log.Printf("[DEBUG] Create Sumologic Opts: %#v", opts)
-				_, err := conn.CreateSumologic(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create OpenStack logging Opts: %s", redactSensitive("openstacklogging", of, opts))
+					err = adapter.CreateOpenStack(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/"+loggingBlockEndpoints["openstacklogging"], opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
-		// find difference in gcslogging
-		if d.HasChange("gcslogging") {
-			os, ns := d.GetChange("gcslogging")
+		// find difference in Log Shuttle logging
+		if d.HasChange("logshuttlelogging") {
+			os, ns := d.GetChange("logshuttlelogging")
 			if os == nil {
 				os = new(schema.Set)
 			}
@@ -1533,45 +2738,43 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			oss := os.(*schema.Set)
 			nss := ns.(*schema.Set)
-			removeGcslogging := oss.Difference(nss).List()
-			addGcslogging := nss.Difference(oss).List()
-
-			// DELETE old gcslogging configurations
-			for _, pRaw := range removeGcslogging {
-				sf := pRaw.(map[string]interface{})
-				opts := gofastly.DeleteGCSInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    sf["name"].(string),
-				}
-
-				log.Printf("[DEBUG] Fastly gcslogging removal opts: %#v", opts)
-				err := conn.DeleteGCS(&opts)
-				if err != nil {
-					return err
+			removeLogShuttleLogging := oss.Difference(nss).List()
+			addLogShuttleLogging := nss.Difference(oss).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("logshuttlelogging", removeLogShuttleLogging, addLogShuttleLogging))
+
+			// DELETE old Log Shuttle logging configurations
+			deletes = append(deletes, func() error {
+				for _, lRaw := range removeLogShuttleLogging {
+					lf := lRaw.(map[string]interface{})
+					name := lf["name"].(string)
+
+					log.Printf("[DEBUG] Fastly Log Shuttle logging removal: %s", name)
+					err := adapter.DeleteLogShuttle(conn, d.Id(), latestVersion, name)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
-			// POST new/updated gcslogging
-			for _, pRaw := range addGcslogging {
-				sf := pRaw.(map[string]interface{})
-				opts := gofastly.CreateGCSInput{
-					Service:           d.Id(),
-					Version:           latestVersion,
-					Name:              sf["name"].(string),
-					User:              sf["email"].(string),
-					Bucket:            sf["bucket_name"].(string),
-					SecretKey:         sf["secret_key"].(string),
-					Format:            sf["format"].(string),
-					ResponseCondition: sf["response_condition"].(string),
-				}
+			// POST new/updated Log Shuttle logging
+			creates = append(creates, func() error {
+				for _, lRaw := range addLogShuttleLogging {
+					lf := lRaw.(map[string]interface{})
+					opts, err := buildLogShuttleLogging(lf)
+					if err != nil {
+						return err
+					}
 
-				log.Printf("[DEBUG] Create GCS Opts: %#v", opts)
-				_, err := conn.CreateGCS(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create Log Shuttle logging Opts: %s", redactSensitive("logshuttlelogging", lf, opts))
+					err = adapter.CreateLogShuttle(conn, d.Id(), latestVersion, opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "logging/"+loggingBlockEndpoints["logshuttlelogging"], opts.Name), opts.Form(d.Id(), latestVersion))
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 		}
 
 		// find difference in Response Object
@@ -1588,44 +2791,69 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			nrs := nr.(*schema.Set)
 			removeResponseObject := ors.Difference(nrs).List()
 			addResponseObject := nrs.Difference(ors).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("response_object", removeResponseObject, addResponseObject))
 
 			// DELETE old response object configurations
-			for _, rRaw := range removeResponseObject {
-				rf := rRaw.(map[string]interface{})
-				opts := gofastly.DeleteResponseObjectInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    rf["name"].(string),
-				}
+			deletes = append(deletes, func() error {
+				for _, rRaw := range removeResponseObject {
+					rf := rRaw.(map[string]interface{})
+					opts := gofastly.DeleteResponseObjectInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    rf["name"].(string),
+					}
 
-				log.Printf("[DEBUG] Fastly Response Object removal opts: %#v", opts)
-				err := conn.DeleteResponseObject(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Fastly Response Object removal opts: %#v", opts)
+					err := conn.DeleteResponseObject(&opts)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
 			// POST new/updated Response Object
-			for _, rRaw := range addResponseObject {
-				rf := rRaw.(map[string]interface{})
-
-				opts := gofastly.CreateResponseObjectInput{
-					Service:          d.Id(),
-					Version:          latestVersion,
-					Name:             rf["name"].(string),
-					Status:           uint(rf["status"].(int)),
-					Response:         rf["response"].(string),
-					Content:          rf["content"].(string),
-					ContentType:      rf["content_type"].(string),
-					RequestCondition: rf["request_condition"].(string),
-					CacheCondition:   rf["cache_condition"].(string),
-				}
+			creates = append(creates, func() error {
+				for _, rRaw := range addResponseObject {
+					rf := rRaw.(map[string]interface{})
+
+					opts := gofastly.CreateResponseObjectInput{
+						Service:          d.Id(),
+						Version:          latestVersion,
+						Name:             rf["name"].(string),
+						Status:           uint(rf["status"].(int)),
+						Response:         rf["response"].(string),
+						Content:          rf["content"].(string),
+						ContentType:      rf["content_type"].(string),
+						RequestCondition: rf["request_condition"].(string),
+						CacheCondition:   rf["cache_condition"].(string),
+					}
 
-				log.Printf("[DEBUG] Create Response Object Opts: %#v", opts)
-				_, err := conn.CreateResponseObject(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create Response Object Opts: %#v", opts)
+					_, err := conn.CreateResponseObject(&opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "response_object", opts.Name), &opts)
+					if err != nil {
+						return err
+					}
 				}
+				return nil
+			})
+		}
+
+		// Maintenance mode is generated configuration, replaced as a whole
+		// whenever it is toggled or its content changes
+		if maintenanceChange {
+			wasOn, isOn := d.GetChange("maintenance_mode")
+			log.Printf("[INFO] maintenance_mode: %t -> %t", wasOn.(bool), isOn.(bool))
+			if wasOn.(bool) {
+				deletes = append(deletes, func() error {
+					return deleteMaintenanceMode(conn, d.Id(), latestVersion)
+				})
+			}
+			if isOn.(bool) {
+				creates = append(creates, func() error {
+					return createMaintenanceMode(conn, adopt, d.Id(), latestVersion, d.Get("maintenance_content").(string))
+				})
 			}
 		}
 
@@ -1643,39 +2871,55 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 			nrs := ns.(*schema.Set)
 			removeRequestSettings := ors.Difference(nrs).List()
 			addRequestSettings := nrs.Difference(ors).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("request_setting", removeRequestSettings, addRequestSettings))
 
 			// DELETE old Request Settings configurations
-			for _, sRaw := range removeRequestSettings {
-				sf := sRaw.(map[string]interface{})
-				opts := gofastly.DeleteRequestSettingInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    sf["name"].(string),
-				}
+			deletes = append(deletes, func() error {
+				for _, sRaw := range removeRequestSettings {
+					sf := sRaw.(map[string]interface{})
+					opts := gofastly.DeleteRequestSettingInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    sf["name"].(string),
+					}
 
-				log.Printf("[DEBUG] Fastly Request Setting removal opts: %#v", opts)
-				err := conn.DeleteRequestSetting(&opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Fastly Request Setting removal opts: %#v", opts)
+					err := conn.DeleteRequestSetting(&opts)
+					if err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			})
 
 			// POST new/updated Request Setting
-			for _, sRaw := range addRequestSettings {
-				opts, err := buildRequestSetting(sRaw.(map[string]interface{}))
-				if err != nil {
-					log.Printf("[DEBUG] Error building Requset Setting: %s", err)
-					return err
-				}
-				opts.Service = d.Id()
-				opts.Version = latestVersion
+			creates = append(creates, func() error {
+				for _, sRaw := range addRequestSettings {
+					opts, err := buildRequestSetting(sRaw.(map[string]interface{}))
+					if err != nil {
+						log.Printf("[DEBUG] Error building Requset Setting: %s", err)
+						return err
+					}
+					opts.Service = d.Id()
+					opts.Version = latestVersion
 
-				log.Printf("[DEBUG] Create Request Setting Opts: %#v", opts)
-				_, err = conn.CreateRequestSetting(opts)
-				if err != nil {
-					return err
+					log.Printf("[DEBUG] Create Request Setting Opts: %#v", opts)
+					_, err = conn.CreateRequestSetting(opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "request_settings", opts.Name), opts)
+					if err != nil {
+						return err
+					}
+
+					// go-fastly omits a zero max_stale_age, which would leave
+					// Fastly's default in place
+					if opts.MaxStaleAge == 0 {
+						if err := updateRequestSettingMaxStaleAge(conn, d.Id(), latestVersion, opts.Name, 0); err != nil {
+							return fmt.Errorf("[ERR] Error setting max_stale_age of Request Setting (%s): %s", opts.Name, err)
+						}
+					}
 				}
-			}
+				return nil
+			})
 		}
 
 		// Find differences in VCLs
@@ -1695,53 +2939,85 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			remove := oldVCLSet.Difference(newVCLSet).List()
 			add := newVCLSet.Difference(oldVCLSet).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("vcl", remove, add))
 
-			// Delete removed VCL configurations
-			for _, dRaw := range remove {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.DeleteVCLInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
-				}
-
-				log.Printf("[DEBUG] Fastly VCL Removal opts: %#v", opts)
-				err := conn.DeleteVCL(&opts)
-				if err != nil {
+			// Only the main flag moved. The cloned version already has every
+			// VCL, so make the new main one main instead of uploading them again.
+			if mainVCL, ok := vclMainFlip(remove, add); ok {
+				log.Printf("[INFO] vcl: only main changed, activating %q", mainVCL)
+				creates = append(creates, func() error {
+					opts := gofastly.ActivateVCLInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    mainVCL,
+					}
+					log.Printf("[DEBUG] Fastly VCL activation opts: %#v", opts)
+					_, err := conn.ActivateVCL(&opts)
 					return err
-				}
+				})
+				remove, add = nil, nil
 			}
-			// POST new VCL configurations
-			for _, dRaw := range add {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.CreateVCLInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
-					Content: df["content"].(string),
+
+			// Delete removed VCL configurations
+			deletes = append(deletes, func() error {
+				for _, dRaw := range remove {
+					df := dRaw.(map[string]interface{})
+					opts := gofastly.DeleteVCLInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    df["name"].(string),
+					}
+
+					log.Printf("[DEBUG] Fastly VCL Removal opts: %#v", opts)
+					err := conn.DeleteVCL(&opts)
+					if err != nil {
+						return err
+					}
 				}
+				return nil
+			})
 
-				log.Printf("[DEBUG] Fastly VCL Addition opts: %#v", opts)
-				_, err := conn.CreateVCL(&opts)
-				if err != nil {
-					return err
+			// POST new VCL configurations. Included VCLs must exist before the
+			// main VCL that includes them, so the main is uploaded and activated
+			// last.
+			creates = append(creates, func() error {
+				var mainVCL string
+				for _, dRaw := range sortVCLsForUpload(add) {
+					df := dRaw.(map[string]interface{})
+					opts := gofastly.CreateVCLInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    df["name"].(string),
+						Content: df["content"].(string),
+					}
+
+					log.Printf("[DEBUG] Fastly VCL Addition opts: %#v", opts)
+					_, err := conn.CreateVCL(&opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "vcl", opts.Name), &opts)
+					if err != nil {
+						return err
+					}
+
+					// if this new VCL is the main
+					if df["main"].(bool) {
+						mainVCL = df["name"].(string)
+					}
 				}
 
-				// if this new VCL is the main
-				if df["main"].(bool) {
+				if mainVCL != "" {
 					opts := gofastly.ActivateVCLInput{
 						Service: d.Id(),
 						Version: latestVersion,
-						Name:    df["name"].(string),
+						Name:    mainVCL,
 					}
 					log.Printf("[DEBUG] Fastly VCL activation opts: %#v", opts)
 					_, err := conn.ActivateVCL(&opts)
 					if err != nil {
 						return err
 					}
-
 				}
-			}
+				return nil
+			})
 		}
 
 		// Find differences in Cache Settings
@@ -1759,79 +3035,301 @@ func resourceServiceV1Update(d *schema.ResourceData, meta interface{}) error {
 
 			remove := ocs.Difference(ncs).List()
 			add := ncs.Difference(ocs).List()
+			log.Printf("[INFO] %s", summarizeSetChanges("cache_setting", remove, add))
 
 			// Delete removed Cache Settings
-			for _, dRaw := range remove {
-				df := dRaw.(map[string]interface{})
-				opts := gofastly.DeleteCacheSettingInput{
-					Service: d.Id(),
-					Version: latestVersion,
-					Name:    df["name"].(string),
+			deletes = append(deletes, func() error {
+				for _, dRaw := range remove {
+					df := dRaw.(map[string]interface{})
+					opts := gofastly.DeleteCacheSettingInput{
+						Service: d.Id(),
+						Version: latestVersion,
+						Name:    df["name"].(string),
+					}
+
+					log.Printf("[DEBUG] Fastly Cache Settings removal opts: %#v", opts)
+					err := conn.DeleteCacheSetting(&opts)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			// POST new Cache Settings
+			creates = append(creates, func() error {
+				for _, dRaw := range add {
+					opts, err := buildCacheSetting(dRaw.(map[string]interface{}))
+					if err != nil {
+						log.Printf("[DEBUG] Error building Cache Setting: %s", err)
+						return err
+					}
+					opts.Service = d.Id()
+					opts.Version = latestVersion
+
+					log.Printf("[DEBUG] Fastly Cache Settings Addition opts: %#v", opts)
+					_, err = conn.CreateCacheSetting(opts)
+					err = adoptOnDuplicate(conn, adopt, err, versionObjectPath(d.Id(), latestVersion, "cache_settings", opts.Name), opts)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+
+		for i := len(deletes) - 1; i >= 0; i-- {
+			if err := deletes[i](); err != nil {
+				return err
+			}
+		}
+		for _, create := range creates {
+			if err := create(); err != nil {
+				return err
+			}
+		}
+
+		d.Set("cloned_version", latestVersion)
+
+		if err := validateServiceV1Version(d, conn, latestVersion); err != nil {
+			abandonServiceV1Version(d, conn, latestVersion, err)
+			return err
+		}
+
+		if d.Get("activate").(bool) {
+			if err := activateServiceV1Version(d, conn, latestVersion); err != nil {
+				return err
+			}
+			activatedVersion = latestVersion
+		} else {
+			log.Printf("[INFO] Skipping activation of Fastly Service (%s), Version (%v), as activate is false", d.Id(), latestVersion)
+		}
+	} else {
+		// Comments can be changed on locked versions, so a new comment alone
+		// is set on the last built version rather than cloning it
+		if version := d.Get("cloned_version").(int); version != 0 && d.HasChange("version_comment") {
+			if err := updateServiceV1VersionComment(d, conn, version); err != nil {
+				return err
+			}
+		}
+
+		if d.HasChange("activate") && d.Get("activate").(bool) {
+			// Nothing else changed, so activate the version an earlier apply built
+			// with activate = false, unless it was activated outside Terraform.
+			if pending := d.Get("cloned_version").(int); pending > d.Get("active_version").(int) {
+				if err := activateServiceV1Version(d, conn, pending); err != nil {
+					return err
 				}
+				activatedVersion = pending
+			}
+		}
+	}
+
+	// Once a version is live, state must point at it even if the refresh below
+	// fails. Otherwise the next apply clones the old version and re-applies old
+	// configuration over the new one. The next refresh fills in the rest.
+	if err := resourceServiceV1Read(d, meta); err != nil {
+		if activatedVersion == 0 {
+			return err
+		}
+		log.Printf("[WARN] Activated version (%d) of Fastly Service (%s), but refreshing it failed: %s", activatedVersion, d.Id(), err)
+	}
+
+	return nil
+}
+
+// updateServiceV1VersionComment sets the comment of a version to
+// version_comment.
+func updateServiceV1VersionComment(d *schema.ResourceData, conn *gofastly.Client, version int) error {
+	comment := d.Get("version_comment").(string)
+	if comment == "" {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Setting the comment of Fastly Service (%s), Version (%v) to %q", d.Id(), version, comment)
+	_, err := conn.UpdateVersion(&gofastly.UpdateVersionInput{
+		Service: d.Id(),
+		Version: version,
+		Comment: comment,
+	})
+	if err != nil {
+		return fmt.Errorf("[ERR] Error setting the comment of Version (%v) for (%s): %s", version, d.Id(), err)
+	}
+	return nil
+}
+
+// validateServiceV1Version checks a built version with Fastly and runs the
+// preflight backend check, before it is activated or left for manual
+// activation.
+func validateServiceV1Version(d *schema.ResourceData, conn *gofastly.Client, version int) error {
+	log.Printf("[DEBUG] Validating Fastly Service (%s), Version (%v)", d.Id(), version)
+	var valid bool
+	var msg string
+	err := retryVersionNotReady("validating", func() (err error) {
+		valid, msg, err = conn.ValidateVersion(&gofastly.ValidateVersionInput{
+			Service: d.Id(),
+			Version: version,
+		})
+		return err
+	})
 
-				log.Printf("[DEBUG] Fastly Cache Settings removal opts: %#v", opts)
-				err := conn.DeleteCacheSetting(&opts)
-				if err != nil {
-					return err
-				}
-			}
+	if err != nil {
+		return fmt.Errorf("[ERR] Error checking validation: %s", err)
+	}
 
-			// POST new Cache Settings
-			for _, dRaw := range add {
-				opts, err := buildCacheSetting(dRaw.(map[string]interface{}))
-				if err != nil {
-					log.Printf("[DEBUG] Error building Cache Setting: %s", err)
-					return err
-				}
-				opts.Service = d.Id()
-				opts.Version = latestVersion
+	if !valid {
+		return fmt.Errorf("[ERR] Invalid configuration for Fastly Service (%s): %s", d.Id(), msg)
+	}
 
-				log.Printf("[DEBUG] Fastly Cache Settings Addition opts: %#v", opts)
-				_, err = conn.CreateCacheSetting(opts)
-				if err != nil {
-					return err
-				}
-			}
-		}
+	if err := preflightCheckBackends(d); err != nil {
+		return fmt.Errorf("[ERR] Preflight check failed for Fastly Service (%s), Version (%v) was not activated: %s", d.Id(), version, err)
+	}
+	return nil
+}
 
-		// validate version
-		log.Printf("[DEBUG] Validating Fastly Service (%s), Version (%v)", d.Id(), latestVersion)
-		valid, msg, err := conn.ValidateVersion(&gofastly.ValidateVersionInput{
+// abandonServiceV1Version gives up on a version that failed validation or the
+// preflight check. Fastly cannot delete versions, so the draft stays in the
+// service's history, commented with the reason. It is dropped from
+// cloned_version so that neither a later apply with activate = true nor a
+// refresh mistakes it for a pending version.
+func abandonServiceV1Version(d *schema.ResourceData, conn *gofastly.Client, version int, reason error) {
+	o, _ := d.GetChange("cloned_version")
+	d.Set("cloned_version", o)
+
+	log.Printf("[DEBUG] Abandoning Fastly Service (%s), Version (%v)", d.Id(), version)
+	_, err := conn.UpdateVersion(&gofastly.UpdateVersionInput{
+		Service: d.Id(),
+		Version: version,
+		Comment: fmt.Sprintf("Abandoned by Terraform: %s", reason),
+	})
+	if err != nil {
+		log.Printf("[WARN] Error commenting abandoned Version (%v) of Fastly Service (%s): %s", version, d.Id(), err)
+	}
+}
+
+// activateServiceV1Version activates a validated version and records it as
+// the active_version.
+func activateServiceV1Version(d *schema.ResourceData, conn *gofastly.Client, version int) error {
+	log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%v)", d.Id(), version)
+	err := retryVersionNotReady("activating", func() error {
+		_, err := conn.ActivateVersion(&gofastly.ActivateVersionInput{
 			Service: d.Id(),
-			Version: latestVersion,
+			Version: version,
 		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("[ERR] Error activating Fastly Service (%s), Version (%d): %s\n\n"+
+			"Version %d was built and validated but is left inactive; inspect it in the Fastly UI. "+
+			"The next apply clones the active version again.", d.Id(), version, err, version)
+	}
 
-		if err != nil {
-			return fmt.Errorf("[ERR] Error checking validation: %s", err)
-		}
+	// Only if the version is valid and activated do we set the active_version.
+	// This prevents us from getting stuck in cloning an invalid version
+	d.Set("active_version", version)
+	return nil
+}
+
+// versionNotReadyAttempts and versionNotReadyDelay bound the retries of
+// validation and activation while a new version is still being processed.
+// The delay doubles after each attempt, for about a minute in total.
+var (
+	versionNotReadyAttempts = 6
+	versionNotReadyDelay    = 2 * time.Second
+)
 
-		if !valid {
-			return fmt.Errorf("[ERR] Invalid configuration for Fastly Service (%s): %s", d.Id(), msg)
+// retryVersionNotReady calls f until it succeeds or fails with an error other
+// than versionNotReady, backing off exponentially.
+func retryVersionNotReady(action string, f func() error) error {
+	delay := versionNotReadyDelay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || !versionNotReady(err) || attempt >= versionNotReadyAttempts {
+			return err
 		}
 
-		log.Printf("[DEBUG] Activating Fastly Service (%s), Version (%v)", d.Id(), latestVersion)
-		_, err = conn.ActivateVersion(&gofastly.ActivateVersionInput{
-			Service: d.Id(),
-			Version: latestVersion,
-		})
-		if err != nil {
-			return fmt.Errorf("[ERR] Error activating version (%d): %s", latestVersion, err)
+		log.Printf("[DEBUG] Error %s version, which may not be ready yet (attempt %d), retrying in %s: %s", action, attempt, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// versionNotReady reports whether err is one Fastly returns while a new
+// version is still being processed: the version not being found yet, a
+// conflicting operation in progress, rate limiting or a temporarily
+// unavailable API. Rejected configurations are not among them.
+func versionNotReady(err error) bool {
+	httpErr, ok := err.(*gofastly.HTTPError)
+	if !ok {
+		return false
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusNotFound, http.StatusConflict, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// serviceV1TLSActivationIDs returns the sorted IDs of the TLS activations of
+// the given domains. An API token without access to TLS yields no IDs rather
+// than an error.
+func serviceV1TLSActivationIDs(conn *gofastly.Client, domains []*gofastly.Domain) ([]string, error) {
+	names := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		names = append(names, domain.Name)
+	}
+
+	domainIDs, err := listTLSActivationIDs(conn, names)
+	if httpErr, ok := err.(*gofastly.HTTPError); ok &&
+		(httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden) {
+		log.Printf("[WARN] Not allowed to list TLS activations, leaving tls_activation_ids empty: %s", err)
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, id := range domainIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
 		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
 
-		// Only if the version is valid and activated do we set the active_version.
-		// This prevents us from getting stuck in cloning an invalid version
-		d.Set("active_version", latestVersion)
+// serviceV1VersionComment returns the comment of a version of s. An empty
+// comment reads as the default, so services built by hand or by earlier
+// releases import without a diff.
+func serviceV1VersionComment(s *gofastly.ServiceDetail, version int) string {
+	comment := ""
+	if s.ActiveVersion.Number == version {
+		comment = s.ActiveVersion.Comment
+	} else {
+		for _, v := range s.Versions {
+			if v.Number == version {
+				comment = v.Comment
+			}
+		}
 	}
 
-	return resourceServiceV1Read(d, meta)
+	if comment == "" {
+		return defaultVersionComment
+	}
+	return comment
 }
 
 func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 	conn := meta.(*FastlyClient).conn
 
-	// Find the Service. Discard the service because we need the ServiceDetails,
-	// not just a Service record
-	_, err := findService(d.Id(), meta)
+	// Find the Service. Only its timestamps are used, because the rest comes
+	// from the ServiceDetails, which does not decode them
+	service, err := findService(d.Id(), meta)
 	if err != nil {
 		switch err {
 		case fastlyNoServiceFoundErr:
@@ -1843,6 +3341,13 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	// Keep the prior state of every block to report drift against, unless
+	// there is none yet, as on import
+	var prior map[string][]interface{}
+	if meta.(*FastlyClient).driftReport && d.Get("active_version").(int) != 0 {
+		prior = snapshotServiceV1Blocks(d)
+	}
+
 	s, err := conn.GetServiceDetails(&gofastly.GetServiceInput{
 		ID: d.Id(),
 	})
@@ -1853,20 +3358,55 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 
 	d.Set("name", s.Name)
 	d.Set("active_version", s.ActiveVersion.Number)
+	// A version activated since the last build, by Terraform or by hand,
+	// supersedes the one Terraform built
+	if d.Get("cloned_version").(int) < s.ActiveVersion.Number {
+		d.Set("cloned_version", s.ActiveVersion.Number)
+	}
+	d.Set("customer_id", s.CustomerID)
+	d.Set("created_at", service.CreatedAt)
+	d.Set("updated_at", service.UpdatedAt)
+	d.Set("is_managed_elsewhere", !strings.Contains(s.Comment, meta.(*FastlyClient).managementMarker))
+
+	if err := checkCustomerID(d.Id(), s.CustomerID, d.Get("expected_customer_id").(string)); err != nil {
+		return err
+	}
 
+	// Until a version is activated, read the version Terraform built with
+	// activate = false, so a service awaiting its first activation converges.
 	// If CreateService succeeds, but initial updates to the Service fail, we'll
-	// have an empty ActiveService version (no version is active, so we can't
-	// query for information on it)
-	if s.ActiveVersion.Number != 0 {
+	// have neither (no version to query for information on).
+	version := s.ActiveVersion.Number
+	if version == 0 {
+		version = d.Get("cloned_version").(int)
+	}
+	if version != 0 {
+		d.Set("version_comment", serviceV1VersionComment(s, version))
+
+		// The generated VCL is large, so only keep it in state on request
+		generatedVCL := ""
+		if d.Get("include_generated_vcl").(bool) {
+			log.Printf("[DEBUG] Refreshing generated VCL for (%s)", d.Id())
+			vcl, err := conn.GetGeneratedVCL(&gofastly.GetGeneratedVCLInput{
+				Service: d.Id(),
+				Version: version,
+			})
+			if err != nil {
+				return fmt.Errorf("[ERR] Error looking up generated VCL for (%s), version (%v): %s", d.Id(), version, err)
+			}
+			generatedVCL = vcl.Content
+		}
+		d.Set("generated_vcl", generatedVCL)
+
 		settingsOpts := gofastly.GetSettingsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		}
 		if settings, err := conn.GetSettings(&settingsOpts); err == nil {
 			d.Set("default_host", settings.DefaultHost)
 			d.Set("default_ttl", settings.DefaultTTL)
 		} else {
-			return fmt.Errorf("[ERR] Error looking up Version settings for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Version settings for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		// TODO: update go-fastly to support an ActiveVersion struct, which contains
@@ -1875,67 +3415,124 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("[DEBUG] Refreshing Domains for (%s)", d.Id())
 		domainList, err := conn.ListDomains(&gofastly.ListDomainsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Domains for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Domains for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		// Refresh Domains into the form that is configured. Comments cannot be
+		// configured in the domains list, so they are not read back there.
+		if d.Get("domains").(*schema.Set).Len() > 0 {
+			if err := d.Set("domains", flattenDomainNames(domainList)); err != nil {
+				log.Printf("[WARN] Error setting Domains for (%s): %s", d.Id(), err)
+			}
+		} else {
+			dl := flattenDomains(domainList)
+
+			if err := d.Set("domain", dl); err != nil {
+				log.Printf("[WARN] Error setting Domains for (%s): %s", d.Id(), err)
+			}
 		}
 
-		// Refresh Domains
-		dl := flattenDomains(domainList)
+		if err := meta.(*FastlyClient).domains.claim(d.Id(), serviceV1DomainLabel(d), serviceV1Domains(d)); err != nil {
+			log.Printf("[WARN] %s", err)
+		}
 
-		if err := d.Set("domain", dl); err != nil {
-			log.Printf("[WARN] Error setting Domains for (%s): %s", d.Id(), err)
+		log.Printf("[DEBUG] Refreshing TLS activations for (%s)", d.Id())
+		// The IDs are informational, so a failed lookup keeps the last known
+		// IDs rather than failing the refresh.
+		if tlsIDs, err := serviceV1TLSActivationIDs(conn, domainList); err != nil {
+			log.Printf("[WARN] Error looking up TLS activations for (%s), keeping the previous tls_activation_ids: %s", d.Id(), err)
+		} else if err := d.Set("tls_activation_ids", tlsIDs); err != nil {
+			log.Printf("[WARN] Error setting TLS activation IDs for (%s): %s", d.Id(), err)
 		}
 
 		// Refresh Backends
 		log.Printf("[DEBUG] Refreshing Backends for (%s)", d.Id())
-		backendList, err := conn.ListBackends(&gofastly.ListBackendsInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		backendList, err := adapter.ListBackends(conn, d.Id(), version)
+
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Backends for (%s), version (%v): %s", d.Id(), version, err)
+		}
 
+		overrides, err := listBackendOverrides(conn, d.Id(), version)
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Backends for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Backend override hosts and comments for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
-		bl := flattenBackends(backendList)
+		priorBackends := priorElementsByName(d, "backend")
+		bl := flattenBackends(backendList, overrides, priorBackends)
+
+		// Fastly has no notion of a disabled healthcheck; those backends simply
+		// have none. Keep the configured healthcheck name from state for them.
+		for _, b := range bl {
+			prior, ok := priorBackends[b["name"].(string)]
+			if !ok || !prior["healthcheck_disabled"].(bool) || b["healthcheck"].(string) != "" {
+				continue
+			}
+			b["healthcheck"] = prior["healthcheck"]
+			b["healthcheck_disabled"] = true
+		}
 
 		if err := d.Set("backend", bl); err != nil {
 			log.Printf("[WARN] Error setting Backends for (%s): %s", d.Id(), err)
 		}
 
+		// refresh pools
+		log.Printf("[DEBUG] Refreshing Pools for (%s)", d.Id())
+		poolList, err := listPools(conn, d.Id(), version)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Pools for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		if err := d.Set("pool", flattenPools(poolList)); err != nil {
+			log.Printf("[WARN] Error setting Pools for (%s): %s", d.Id(), err)
+		}
+
 		// refresh headers
 		log.Printf("[DEBUG] Refreshing Headers for (%s)", d.Id())
-		headerList, err := conn.ListHeaders(&gofastly.ListHeadersInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		headerList, err := adapter.ListHeaders(conn, d.Id(), version)
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Headers for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Headers for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
+		headerList, surrogateKeyList := splitSurrogateKeyHeaders(headerList)
 		hl := flattenHeaders(headerList)
 
 		if err := d.Set("header", hl); err != nil {
 			log.Printf("[WARN] Error setting Headers for (%s): %s", d.Id(), err)
 		}
 
+		skl := flattenSurrogateKeys(surrogateKeyList)
+
+		if err := d.Set("surrogate_key", skl); err != nil {
+			log.Printf("[WARN] Error setting Surrogate Keys for (%s): %s", d.Id(), err)
+		}
+
 		// refresh gzips
 		log.Printf("[DEBUG] Refreshing Gzips for (%s)", d.Id())
 		gzipsList, err := conn.ListGzips(&gofastly.ListGzipsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Gzips for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Gzips for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		gl := flattenGzips(gzipsList)
 
+		// allow_empty only exists in Terraform
+		priorGzips := priorElementsByName(d, "gzip")
+		for _, g := range gl {
+			if prior, ok := priorGzips[g["name"].(string)]; ok {
+				g["allow_empty"] = prior["allow_empty"]
+			}
+		}
+
 		if err := d.Set("gzip", gl); err != nil {
 			log.Printf("[WARN] Error setting Gzips for (%s): %s", d.Id(), err)
 		}
@@ -1944,14 +3541,19 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("[DEBUG] Refreshing Healthcheck for (%s)", d.Id())
 		healthcheckList, err := conn.ListHealthChecks(&gofastly.ListHealthChecksInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Healthcheck for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Healthcheck for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
-		hcl := flattenHealthchecks(healthcheckList)
+		healthcheckExtras, err := listHealthCheckExtras(conn, d.Id(), version)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Healthcheck comments and headers for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		hcl := flattenHealthchecks(healthcheckList, healthcheckExtras)
 
 		if err := d.Set("healthcheck", hcl); err != nil {
 			log.Printf("[WARN] Error setting Healthcheck for (%s): %s", d.Id(), err)
@@ -1959,16 +3561,14 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 
 		// refresh S3 Logging
 		log.Printf("[DEBUG] Refreshing S3 Logging for (%s)", d.Id())
-		s3List, err := conn.ListS3s(&gofastly.ListS3sInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		s3List, err := adapter.ListS3s(conn, d.Id(), version)
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up S3 Logging for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up S3 Logging for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		sl := flattenS3s(s3List)
+		omitLoggingDefaults(sl, priorElementsByName(d, "s3logging"), meta.(*FastlyClient).loggingDefaults["s3logging"])
 
 		if err := d.Set("s3logging", sl); err != nil {
 			log.Printf("[WARN] Error setting S3 Logging for (%s): %s", d.Id(), err)
@@ -1976,13 +3576,10 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 
 		// refresh Papertrail Logging
 		log.Printf("[DEBUG] Refreshing Papertrail for (%s)", d.Id())
-		papertrailList, err := conn.ListPapertrails(&gofastly.ListPapertrailsInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		papertrailList, err := adapter.ListPapertrails(conn, d.Id(), version)
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Papertrail for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Papertrail for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		pl := flattenPapertrails(papertrailList)
@@ -1993,48 +3590,103 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 
 		// refresh Sumologic Logging
 		log.Printf("[DEBUG] Refreshing Sumologic for (%s)", d.Id())
-		sumologicList, err := conn.ListSumologics(&gofastly.ListSumologicsInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		sumologicList, err := adapter.ListSumologics(conn, d.Id(), version)
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Sumologic for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Sumologic for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		sul := flattenSumologics(sumologicList)
+
+		// region only exists in Terraform
+		priorSumologics := priorElementsByName(d, "sumologic")
+		for _, sl := range sul {
+			if prior, ok := priorSumologics[sl["name"].(string)]; ok && prior["region"].(string) != "" {
+				sl["region"] = prior["region"]
+			}
+		}
 		if err := d.Set("sumologic", sul); err != nil {
 			log.Printf("[WARN] Error setting Sumologic for (%s): %s", d.Id(), err)
 		}
 
 		// refresh GCS Logging
 		log.Printf("[DEBUG] Refreshing GCS for (%s)", d.Id())
-		GCSList, err := conn.ListGCSs(&gofastly.ListGCSsInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		GCSList, err := adapter.ListGCSs(conn, d.Id(), version)
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up GCS for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up GCS for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		gcsl := flattenGCS(GCSList)
-		if err := d.Set("gcs", gcsl); err != nil {
+		omitLoggingDefaults(gcsl, priorElementsByName(d, "gcslogging"), meta.(*FastlyClient).loggingDefaults["gcslogging"])
+		if err := d.Set("gcslogging", gcsl); err != nil {
 			log.Printf("[WARN] Error setting gcs for (%s): %s", d.Id(), err)
 		}
 
+		// refresh Loki Logging
+		log.Printf("[DEBUG] Refreshing Loki for (%s)", d.Id())
+		lokiList, err := adapter.ListLokis(conn, d.Id(), version)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Loki for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		ll := flattenLoki(lokiList)
+		if err := d.Set("loki", ll); err != nil {
+			log.Printf("[WARN] Error setting Loki for (%s): %s", d.Id(), err)
+		}
+
+		// refresh HTTPS Logging
+		log.Printf("[DEBUG] Refreshing HTTPS logging for (%s)", d.Id())
+		httpsLoggingList, err := adapter.ListHTTPS(conn, d.Id(), version)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up HTTPS logging for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		hll := flattenHTTPSLogging(httpsLoggingList)
+		if err := d.Set("httpslogging", hll); err != nil {
+			log.Printf("[WARN] Error setting HTTPS logging for (%s): %s", d.Id(), err)
+		}
+
+		// refresh OpenStack Logging
+		log.Printf("[DEBUG] Refreshing OpenStack logging for (%s)", d.Id())
+		openstackLoggingList, err := adapter.ListOpenStacks(conn, d.Id(), version)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up OpenStack logging for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		oll := flattenOpenstackLogging(openstackLoggingList)
+		if err := d.Set("openstacklogging", oll); err != nil {
+			log.Printf("[WARN] Error setting OpenStack logging for (%s): %s", d.Id(), err)
+		}
+
+		// refresh Log Shuttle Logging
+		log.Printf("[DEBUG] Refreshing Log Shuttle logging for (%s)", d.Id())
+		logShuttleLoggingList, err := adapter.ListLogShuttles(conn, d.Id(), version)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Log Shuttle logging for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		lsl := flattenLogShuttleLogging(logShuttleLoggingList)
+		if err := d.Set("logshuttlelogging", lsl); err != nil {
+			log.Printf("[WARN] Error setting Log Shuttle logging for (%s): %s", d.Id(), err)
+		}
+
 		// refresh Response Objects
 		log.Printf("[DEBUG] Refreshing Response Object for (%s)", d.Id())
 		responseObjectList, err := conn.ListResponseObjects(&gofastly.ListResponseObjectsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Response Object for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Response Object for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
-		rol := flattenResponseObjects(responseObjectList)
+		rol, maintenance := removeMaintenanceMode(flattenResponseObjects(responseObjectList))
+		d.Set("maintenance_mode", maintenance != nil)
+		if maintenance != nil {
+			d.Set("maintenance_content", maintenance["content"])
+		}
 
 		if err := d.Set("response_object", rol); err != nil {
 			log.Printf("[WARN] Error setting Response Object for (%s): %s", d.Id(), err)
@@ -2042,16 +3694,27 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 
 		// refresh Conditions
 		log.Printf("[DEBUG] Refreshing Conditions for (%s)", d.Id())
-		conditionList, err := conn.ListConditions(&gofastly.ListConditionsInput{
-			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
-		})
+		conditionList, err := adapter.ListConditions(conn, d.Id(), version)
+
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Conditions for (%s), version (%v): %s", d.Id(), version, err)
+		}
 
+		comments, err := listConditionComments(conn, d.Id(), version)
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Conditions for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Condition comments for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
-		cl := flattenConditions(conditionList)
+		cl, _ := removeMaintenanceMode(flattenConditions(conditionList, comments))
+
+		// Fastly always reports a priority. Keep it out of the configured
+		// priority for conditions whose priority was assigned automatically.
+		priorConditions := priorElementsByName(d, "condition")
+		for _, c := range cl {
+			if prior, ok := priorConditions[c["name"].(string)]; ok && prior["priority"].(int) == conditionPriorityAuto {
+				c["priority"] = conditionPriorityAuto
+			}
+		}
 
 		if err := d.Set("condition", cl); err != nil {
 			log.Printf("[WARN] Error setting Conditions for (%s): %s", d.Id(), err)
@@ -2061,15 +3724,30 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("[DEBUG] Refreshing Request Settings for (%s)", d.Id())
 		rsList, err := conn.ListRequestSettings(&gofastly.ListRequestSettingsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Request Settings for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Request Settings for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		rl := flattenRequestSettings(rsList)
 
+		// Keep hash keys in the form they were configured in
+		priorRequestSettings := priorElementsByName(d, "request_setting")
+		for _, r := range rl {
+			prior, ok := priorRequestSettings[r["name"].(string)]
+			if !ok {
+				continue
+			}
+			if l, ok := prior["hash_keys_list"].(*schema.Set); ok && l.Len() > 0 {
+				if hk, ok := r["hash_keys"].(string); ok {
+					r["hash_keys_list"] = splitHashKeys(hk)
+				}
+				delete(r, "hash_keys")
+			}
+		}
+
 		if err := d.Set("request_setting", rl); err != nil {
 			log.Printf("[WARN] Error setting Request Settings for (%s): %s", d.Id(), err)
 		}
@@ -2078,10 +3756,14 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("[DEBUG] Refreshing VCLs for (%s)", d.Id())
 		vclList, err := conn.ListVCLs(&gofastly.ListVCLsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up VCLs for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up VCLs for (%s), version (%v): %s", d.Id(), version, err)
+		}
+
+		if err := fillVCLContent(conn, d.Id(), version, vclList); err != nil {
+			return fmt.Errorf("[ERR] Error looking up VCL content for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		vl := flattenVCLs(vclList)
@@ -2094,10 +3776,10 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("[DEBUG] Refreshing Cache Settings for (%s)", d.Id())
 		cslList, err := conn.ListCacheSettings(&gofastly.ListCacheSettingsInput{
 			Service: d.Id(),
-			Version: s.ActiveVersion.Number,
+			Version: version,
 		})
 		if err != nil {
-			return fmt.Errorf("[ERR] Error looking up Cache Settings for (%s), version (%v): %s", d.Id(), s.ActiveVersion.Number, err)
+			return fmt.Errorf("[ERR] Error looking up Cache Settings for (%s), version (%v): %s", d.Id(), version, err)
 		}
 
 		csl := flattenCacheSettings(cslList)
@@ -2106,6 +3788,9 @@ func resourceServiceV1Read(d *schema.ResourceData, meta interface{}) error {
 			log.Printf("[WARN] Error setting Cache Settings for (%s): %s", d.Id(), err)
 		}
 
+		if prior != nil {
+			logServiceV1Drift(d, prior)
+		}
 	} else {
 		log.Printf("[DEBUG] Active Version for Service (%s) is empty, no state to refresh", d.Id())
 	}
@@ -2147,6 +3832,8 @@ func resourceServiceV1Delete(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	meta.(*FastlyClient).domains.release(d.Id())
+
 	_, err = findService(d.Id(), meta)
 	if err != nil {
 		switch err {
@@ -2168,37 +3855,262 @@ func flattenDomains(list []*gofastly.Domain) []map[string]interface{} {
 	dl := make([]map[string]interface{}, 0, len(list))
 
 	for _, d := range list {
-		dl = append(dl, map[string]interface{}{
-			"name":    d.Name,
-			"comment": d.Comment,
-		})
+		nd := map[string]interface{}{
+			"name":        d.Name,
+			"is_wildcard": strings.HasPrefix(d.Name, "*."),
+		}
+		if d.Comment != "" {
+			nd["comment"] = d.Comment
+		}
+		dl = append(dl, nd)
 	}
 
 	return dl
 }
 
-func flattenBackends(backendList []*gofastly.Backend) []map[string]interface{} {
+// flattenDomainNames converts domains to the domains list, dropping comments.
+func flattenDomainNames(list []*gofastly.Domain) []string {
+	names := make([]string, 0, len(list))
+	for _, d := range list {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// serviceV1DomainChange returns the domains of a service before and after the
+// pending change as domain block elements, whichever of domain and domains
+// configures them. Domains from the domains list have an empty comment.
+func serviceV1DomainChange(d *schema.ResourceData) (old, new *schema.Set) {
+	domainSet := func(blocks, names interface{}) *schema.Set {
+		s := schema.NewSet(schema.HashResource(domainResource()), nil)
+		if blocks, ok := blocks.(*schema.Set); ok {
+			for _, dRaw := range blocks.List() {
+				s.Add(dRaw)
+			}
+		}
+		if names, ok := names.(*schema.Set); ok {
+			for _, n := range names.List() {
+				s.Add(map[string]interface{}{"name": n.(string), "comment": ""})
+			}
+		}
+		return s
+	}
+
+	oldBlocks, newBlocks := d.GetChange("domain")
+	oldNames, newNames := d.GetChange("domains")
+	return domainSet(oldBlocks, oldNames), domainSet(newBlocks, newNames)
+}
+
+// priorElementsByName indexes the elements of a set block in the current state
+// by name. Read uses it to carry forward settings that only exist in
+// Terraform and cannot be read back from Fastly.
+func priorElementsByName(d *schema.ResourceData, block string) map[string]map[string]interface{} {
+	prior := make(map[string]map[string]interface{})
+	if set, ok := d.Get(block).(*schema.Set); ok {
+		for _, eRaw := range set.List() {
+			ef := eRaw.(map[string]interface{})
+			if name, ok := ef["name"].(string); ok {
+				prior[name] = ef
+			}
+		}
+	}
+	return prior
+}
+
+// assignConditionPriorities returns the priority each condition should be
+// created with. Explicit priorities, including 0, are used as given.
+// Conditions without one keep their priority from kept if present, and are
+// otherwise numbered sequentially per condition type, in name order, skipping
+// any priority already taken within that type.
+func assignConditionPriorities(conditions []interface{}, kept map[string]int) map[string]int {
+	priorities := make(map[string]int)
+	used := make(map[string]map[int]bool)
+	var pending []map[string]interface{}
+
+	for _, cRaw := range conditions {
+		cf := cRaw.(map[string]interface{})
+		name := cf["name"].(string)
+		condType := strings.ToUpper(cf["type"].(string))
+		if used[condType] == nil {
+			used[condType] = make(map[int]bool)
+		}
+
+		p := cf["priority"].(int)
+		if p == conditionPriorityAuto {
+			var ok bool
+			if p, ok = kept[name]; !ok {
+				pending = append(pending, cf)
+				continue
+			}
+		}
+		priorities[name] = p
+		used[condType][p] = true
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i]["name"].(string) < pending[j]["name"].(string)
+	})
+	for _, cf := range pending {
+		condType := strings.ToUpper(cf["type"].(string))
+		p := 1
+		for used[condType][p] {
+			p++
+		}
+		priorities[cf["name"].(string)] = p
+		used[condType][p] = true
+	}
+
+	return priorities
+}
+
+// withLoggingDefaults returns a copy of a logging endpoint's settings with any
+// empty credential field filled in from the provider defaults for its block.
+func withLoggingDefaults(ef map[string]interface{}, defaults map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(ef))
+	for k, v := range ef {
+		out[k] = v
+	}
+	for k, v := range defaults {
+		if s, ok := out[k].(string); ok && s == "" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// omitLoggingDefaults blanks credential fields read back from Fastly that
+// were left empty in state and hold the provider default, so that relying on
+// the provider default doesn't show up as a diff.
+func omitLoggingDefaults(list []map[string]interface{}, prior map[string]map[string]interface{}, defaults map[string]string) {
+	for _, ef := range list {
+		name, _ := ef["name"].(string)
+		p, ok := prior[name]
+		if !ok {
+			continue
+		}
+		for k, v := range defaults {
+			if s, _ := p[k].(string); s == "" && v != "" && ef[k] == v {
+				ef[k] = ""
+			}
+		}
+	}
+}
+
+// splitBackendHealthCheckToggles separates backends whose only change is
+// healthcheck_disabled from the lists of backends to remove and add. The
+// toggled backends are returned with their new configuration.
+func splitBackendHealthCheckToggles(remove, add []interface{}) (toggle []map[string]interface{}, newRemove, newAdd []interface{}) {
+	removed := make(map[string]map[string]interface{})
+	for _, bRaw := range remove {
+		bf := bRaw.(map[string]interface{})
+		removed[bf["name"].(string)] = bf
+	}
+
+	toggled := make(map[string]bool)
+	for _, bRaw := range add {
+		bf := bRaw.(map[string]interface{})
+		old, ok := removed[bf["name"].(string)]
+		if !ok || !onlyHealthCheckDisabledDiffers(old, bf) {
+			newAdd = append(newAdd, bRaw)
+			continue
+		}
+		toggle = append(toggle, bf)
+		toggled[bf["name"].(string)] = true
+	}
+
+	for _, bRaw := range remove {
+		if !toggled[bRaw.(map[string]interface{})["name"].(string)] {
+			newRemove = append(newRemove, bRaw)
+		}
+	}
+
+	return toggle, newRemove, newAdd
+}
+
+func onlyHealthCheckDisabledDiffers(old, new map[string]interface{}) bool {
+	if old["healthcheck_disabled"] == new["healthcheck_disabled"] {
+		return false
+	}
+	for k, v := range new {
+		if k != "healthcheck_disabled" && !reflect.DeepEqual(old[k], v) {
+			return false
+		}
+	}
+	return len(old) == len(new)
+}
+
+// flattenBackends converts backends to their schema representation.
+// overrides holds each backend's override_host and comment by name, which
+// go-fastly does not decode. An override_host that only resolved from the
+// default, per the prior state, is left unset so it does not show as drift.
+func flattenBackends(backendList []*adapter.Backend, overrides map[string]*backendOverride, prior map[string]map[string]interface{}) []map[string]interface{} {
 	var bl []map[string]interface{}
 	for _, b := range backendList {
+		override := overrides[b.Name]
+		if override == nil {
+			override = &backendOverride{}
+		}
+
 		// Convert Backend to a map for saving to state.
 		nb := map[string]interface{}{
 			"name":                  b.Name,
 			"address":               b.Address,
 			"auto_loadbalance":      b.AutoLoadbalance,
-			"between_bytes_timeout": int(b.BetweenBytesTimeout),
-			"connect_timeout":       int(b.ConnectTimeout),
-			"error_threshold":       int(b.ErrorThreshold),
-			"first_byte_timeout":    int(b.FirstByteTimeout),
-			"max_conn":              int(b.MaxConn),
-			"port":                  int(b.Port),
+			"between_bytes_timeout": b.BetweenBytesTimeout,
+			"connect_timeout":       b.ConnectTimeout,
+			"error_threshold":       b.ErrorThreshold,
+			"first_byte_timeout":    b.FirstByteTimeout,
+			"max_conn":              b.MaxConn,
+			"port":                  b.Port,
 			"shield":                b.Shield,
 			"ssl_check_cert":        b.SSLCheckCert,
 			"ssl_hostname":          b.SSLHostname,
 			"ssl_cert_hostname":     b.SSLCertHostname,
 			"ssl_sni_hostname":      b.SSLSNIHostname,
-			"weight":                int(b.Weight),
+			"ssl_ca_cert":           b.SSLCACert,
+			"ssl_client_cert":       b.SSLClientCert,
+			"ssl_client_key":        b.SSLClientKey,
+			"use_ssl":               b.UseSSL,
+			"override_host":         override.OverrideHost,
+			"comment":               override.Comment,
+			"weight":                b.Weight,
 			"request_condition":     b.RequestCondition,
 			"healthcheck":           b.HealthCheck,
+			"healthcheck_disabled":  false,
+		}
+
+		// Read timeouts back in seconds where they were configured that way,
+		// unless they were changed since to a value that is not whole seconds.
+		if p, ok := prior[b.Name]; ok {
+			for timeout, inSeconds := range backendTimeoutsInSeconds {
+				ms := nb[timeout].(int)
+				if seconds, _ := p[inSeconds].(int); seconds > 0 && ms%1000 == 0 {
+					nb[inSeconds] = ms / 1000
+					nb[timeout] = backendResource().Schema[timeout].Default
+				}
+			}
+		}
+
+		// Keep the configured client key should Fastly not return it.
+		if p, ok := prior[b.Name]; ok && b.SSLClientKey == "" && b.SSLClientCert != "" {
+			if key, ok := p["ssl_client_key"].(string); ok {
+				nb["ssl_client_key"] = key
+			}
+		}
+
+		if nb["comment"] == "" {
+			delete(nb, "comment")
+		}
+
+		if p, ok := prior[b.Name]; ok && p["override_host"] == "" {
+			defaulted := make(map[string]interface{})
+			for k, v := range nb {
+				defaulted[k] = v
+			}
+			defaulted["override_host"] = ""
+			if nb["override_host"] == backendOverrideHost(defaulted) {
+				nb["override_host"] = ""
+			}
 		}
 
 		bl = append(bl, nb)
@@ -2227,19 +4139,133 @@ func findService(id string, meta interface{}) (*gofastly.Service, error) {
 		return nil, fmt.Errorf("[WARN] Error listing services (%s): %s", id, err)
 	}
 
-	for _, s := range l {
-		if s.ID == id {
-			log.Printf("[DEBUG] Found Service (%s)", id)
-			return s, nil
+	for _, s := range l {
+		if s.ID == id {
+			log.Printf("[DEBUG] Found Service (%s)", id)
+			return s, nil
+		}
+	}
+
+	return nil, fastlyNoServiceFoundErr
+}
+
+// checkCustomerID returns an error if expected is set and the service belongs
+// to a different customer.
+func checkCustomerID(id, customerID, expected string) error {
+	if expected == "" || customerID == expected {
+		return nil
+	}
+	return fmt.Errorf("[ERR] Fastly Service (%s) belongs to customer (%s), not expected_customer_id (%s). Check the API key the provider is configured with", id, customerID, expected)
+}
+
+// reusableServiceV1 returns the service service_id_reuse names, if it exists
+// and only has the unused version 1 a new service starts with, or nil if it
+// does not exist. A service that was ever configured and activated is an
+// error: taking it over would replace whatever it serves. So is a version 1
+// that already holds objects, such as one built with activate = false, unless
+// adopt is set to take those objects over.
+func reusableServiceV1(id string, adopt bool, meta interface{}) (*gofastly.Service, error) {
+	s, err := findService(id, meta)
+	if err == fastlyNoServiceFoundErr {
+		log.Printf("[INFO] service_id_reuse: Fastly Service (%s) not found, creating a new service", id)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range s.Versions {
+		if v.Active {
+			return nil, fmt.Errorf("service_id_reuse: Fastly Service (%s) has active version (%d), so it cannot be reused as a new service. "+
+				"Import it with terraform import to manage it, or remove service_id_reuse to create a new service", id, v.Number)
+		}
+	}
+	if len(s.Versions) > 1 || (len(s.Versions) == 1 && (s.Versions[0].Number != 1 || s.Versions[0].Locked)) {
+		return nil, fmt.Errorf("service_id_reuse: Fastly Service (%s) has been configured before, so it cannot be reused as a new service. "+
+			"Import it with terraform import to manage it, or remove service_id_reuse to create a new service", id)
+	}
+
+	objects, err := listVersionObjects(meta.(*FastlyClient).conn, id, 1)
+	if err != nil {
+		return nil, fmt.Errorf("service_id_reuse: Error looking up the objects of Fastly Service (%s), version 1: %s", id, err)
+	}
+	if len(objects) > 0 {
+		if !adopt {
+			return nil, fmt.Errorf("service_id_reuse: version 1 of Fastly Service (%s) already holds %s, so it cannot be reused as a new service. "+
+				"Set adopt_existing to take those objects over, import the service with terraform import, or remove service_id_reuse to create a new service",
+				id, strings.Join(objects, ", "))
+		}
+		log.Printf("[INFO] service_id_reuse: adopting the %d existing objects of Fastly Service (%s), version 1", len(objects), id)
+	}
+	return s, nil
+}
+
+// versionObjectTypes are the API path segments of the objects, other than
+// logging endpoints, that the resource creates on a service version.
+var versionObjectTypes = []string{
+	"domain",
+	"backend",
+	"pool",
+	"healthcheck",
+	"condition",
+	"header",
+	"gzip",
+	"cache_settings",
+	"request_settings",
+	"response_object",
+	"vcl",
+}
+
+// listVersionObjects lists the objects of the types the resource manages that
+// a service version holds, such as `backend "origin"`.
+func listVersionObjects(conn *gofastly.Client, service string, version int) ([]string, error) {
+	var objects []string
+	list := func(kind, path string) error {
+		resp, err := conn.Get(path, nil)
+		if err != nil {
+			return err
+		}
+		var named []struct {
+			Name string `mapstructure:"name"`
+		}
+		if err := decodeFastlyJSON(&named, resp.Body); err != nil {
+			return err
+		}
+		for _, o := range named {
+			objects = append(objects, fmt.Sprintf("%s %q", kind, o.Name))
+		}
+		return nil
+	}
+
+	for _, objectType := range versionObjectTypes {
+		if err := list(objectType, fmt.Sprintf("/service/%s/version/%d/%s", service, version, objectType)); err != nil {
+			return nil, err
+		}
+	}
+	for _, block := range loggingBlocks {
+		if err := list(block, fmt.Sprintf("/service/%s/version/%d/logging/%s", service, version, loggingBlockEndpoints[block])); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil, fastlyNoServiceFoundErr
+	return objects, nil
 }
 
-func flattenHeaders(headerList []*gofastly.Header) []map[string]interface{} {
+// headerDefaultPriority is the priority given to a header block that does not
+// set one.
+const headerDefaultPriority = 100
+
+func flattenHeaders(headerList []*adapter.Header) []map[string]interface{} {
 	var hl []map[string]interface{}
 	for _, h := range headerList {
+		// A priority of 0 is never sent (the field is omitempty), so the API
+		// reporting 0 means the header was created without one. Store the
+		// schema default instead so the next plan does not show a diff.
+		priority := h.Priority
+		if priority == 0 {
+			priority = headerDefaultPriority
+		}
+
 		// Convert Header to a map for saving to state.
 		nh := map[string]interface{}{
 			"name":               h.Name,
@@ -2250,7 +4276,7 @@ func flattenHeaders(headerList []*gofastly.Header) []map[string]interface{} {
 			"source":             h.Source,
 			"regex":              h.Regex,
 			"substitution":       h.Substitution,
-			"priority":           int(h.Priority),
+			"priority":           priority,
 			"request_condition":  h.RequestCondition,
 			"cache_condition":    h.CacheCondition,
 			"response_condition": h.ResponseCondition,
@@ -2267,13 +4293,423 @@ func flattenHeaders(headerList []*gofastly.Header) []map[string]interface{} {
 	return hl
 }
 
-func buildHeader(headerMap interface{}) (*gofastly.CreateHeaderInput, error) {
+// surrogateKeyHeaderPrefix starts the name of each header a surrogate_key
+// block manages, which keeps them apart from the header block.
+const surrogateKeyHeaderPrefix = "surrogate_key: "
+
+// splitSurrogateKeyHeaders separates the headers generated for surrogate_key
+// blocks from all other headers.
+func splitSurrogateKeyHeaders(headerList []*adapter.Header) (headers, surrogateKeys []*adapter.Header) {
+	for _, h := range headerList {
+		if strings.HasPrefix(h.Name, surrogateKeyHeaderPrefix) {
+			surrogateKeys = append(surrogateKeys, h)
+		} else {
+			headers = append(headers, h)
+		}
+	}
+	return headers, surrogateKeys
+}
+
+func flattenSurrogateKeys(headerList []*adapter.Header) []map[string]interface{} {
+	var skl []map[string]interface{}
+	for _, h := range headerList {
+		skl = append(skl, map[string]interface{}{
+			"name":            strings.TrimPrefix(h.Name, surrogateKeyHeaderPrefix),
+			"key_template":    h.Source,
+			"cache_condition": h.CacheCondition,
+		})
+	}
+	return skl
+}
+
+// buildSurrogateKeyHeader builds the header that sets Surrogate-Key on the
+// backend response. Fastly reads the keys as the object is cached, so the
+// header must be set in the cache phase.
+func buildSurrogateKeyHeader(surrogateKeyMap interface{}) (*adapter.Header, error) {
+	sf := surrogateKeyMap.(map[string]interface{})
+	opts := adapter.Header{
+		Name:           surrogateKeyHeaderPrefix + sf["name"].(string),
+		Action:         "set",
+		Type:           "cache",
+		Destination:    "http.Surrogate-Key",
+		Source:         sf["key_template"].(string),
+		Priority:       headerDefaultPriority,
+		CacheCondition: sf["cache_condition"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildCondition(conditionMap interface{}) (*adapter.Condition, error) {
+	cf := conditionMap.(map[string]interface{})
+	opts := adapter.Condition{
+		Name: cf["name"].(string),
+		Type: strings.ToUpper(cf["type"].(string)),
+		// need to trim leading/tailing spaces, incase the config has HEREDOC
+		// formatting and contains a trailing new line
+		Statement: strings.TrimSpace(cf["statement"].(string)),
+		Priority:  cf["priority"].(int),
+	}
+
+	return &opts, nil
+}
+
+// conditionHash hashes a condition as Fastly stores it, with its type
+// uppercased and its statement trimmed, so a lowercase type in configuration or
+// in an API response does not make the condition look replaced on every plan.
+func conditionHash(v interface{}) int {
+	m := v.(map[string]interface{})
+	normalized := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		normalized[k] = v
+	}
+	if t, ok := m["type"].(string); ok {
+		normalized["type"] = strings.ToUpper(t)
+	}
+	if s, ok := m["statement"].(string); ok {
+		normalized["statement"] = strings.TrimSpace(s)
+	}
+
+	var buf bytes.Buffer
+	schema.SerializeResourceForHash(&buf, normalized, conditionResource())
+	return hashcode.String(buf.String())
+}
+
+// backendHash is schema.HashResource with ssl_ca_cert normalized first, so
+// that configuration and state hash alike however the CA chain is laid out.
+// Every other field stays in the hash as is: Terraform only diffs the
+// elements of a set whose hashes changed.
+func backendHash(v interface{}) int {
+	m := v.(map[string]interface{})
+	if ca, ok := m["ssl_ca_cert"].(string); ok && ca != "" {
+		normalized := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			normalized[k] = v
+		}
+		normalized["ssl_ca_cert"] = normalizePEMCertificates(ca)
+		m = normalized
+	}
+	return schema.HashResource(backendResource())(m)
+}
+
+// normalizePEMCertificates puts a PEM certificate chain in a canonical form:
+// each certificate re-encoded from its DER bytes, without headers or extra
+// whitespace, and sorted by subject. Chains that differ only in layout or
+// order then compare equal. Input that does not parse is returned unchanged
+// for validatePEMCertificates to report.
+func normalizePEMCertificates(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+
+	certs, err := parsePEMCertificates(s)
+	if err != nil {
+		return s
+	}
+
+	sort.SliceStable(certs, func(i, j int) bool {
+		si, sj := certs[i].Subject.String(), certs[j].Subject.String()
+		if si != sj {
+			return si < sj
+		}
+		return bytes.Compare(certs[i].Raw, certs[j].Raw) < 0
+	})
+
+	var buf bytes.Buffer
+	for _, c := range certs {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+	return buf.String()
+}
+
+// parsePEMCertificates parses every block of a PEM certificate chain, failing
+// on anything that is not a certificate.
+func parsePEMCertificates(s string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("block %d is a %s, not a CERTIFICATE", len(certs)+1, block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("block %d is not a valid certificate: %s", len(certs)+1, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return nil, fmt.Errorf("unexpected content after certificate %d", len(certs))
+	}
+	return certs, nil
+}
+
+// poolKey identifies a pool by name.
+func poolKey(m map[string]interface{}) string {
+	return m["name"].(string)
+}
+
+func buildPool(poolMap map[string]interface{}) *pool {
+	return &pool{
+		Name:             poolMap["name"].(string),
+		Comment:          poolMap["comment"].(string),
+		Type:             poolMap["type"].(string),
+		Healthcheck:      poolMap["healthcheck"].(string),
+		MaxConnDefault:   uint(poolMap["max_conn_default"].(int)),
+		ConnectTimeout:   uint(poolMap["connect_timeout"].(int)),
+		FirstByteTimeout: uint(poolMap["first_byte_timeout"].(int)),
+		Quorum:           uint(poolMap["quorum"].(int)),
+	}
+}
+
+func flattenPools(poolList []*pool) []map[string]interface{} {
+	var pl []map[string]interface{}
+	for _, p := range poolList {
+		// Convert Pools to a map for saving to state.
+		np := map[string]interface{}{
+			"name":               p.Name,
+			"comment":            p.Comment,
+			"type":               p.Type,
+			"healthcheck":        p.Healthcheck,
+			"max_conn_default":   int(p.MaxConnDefault),
+			"connect_timeout":    int(p.ConnectTimeout),
+			"first_byte_timeout": int(p.FirstByteTimeout),
+			"quorum":             int(p.Quorum),
+			"pool_id":            p.ID,
+		}
+		if p.Comment == "" {
+			delete(np, "comment")
+		}
+		pl = append(pl, np)
+	}
+
+	return pl
+}
+
+func buildBackend(backendMap interface{}) (*adapter.Backend, error) {
+	df := backendMap.(map[string]interface{})
+	healthcheck := df["healthcheck"].(string)
+	if df["healthcheck_disabled"].(bool) {
+		healthcheck = ""
+	}
+
+	opts := adapter.Backend{
+		Name:                df["name"].(string),
+		Address:             df["address"].(string),
+		AutoLoadbalance:     df["auto_loadbalance"].(bool),
+		SSLCheckCert:        df["ssl_check_cert"].(bool),
+		SSLHostname:         df["ssl_hostname"].(string),
+		SSLCertHostname:     df["ssl_cert_hostname"].(string),
+		SSLSNIHostname:      df["ssl_sni_hostname"].(string),
+		SSLCACert:           normalizePEMCertificates(df["ssl_ca_cert"].(string)),
+		SSLClientCert:       df["ssl_client_cert"].(string),
+		SSLClientKey:        df["ssl_client_key"].(string),
+		UseSSL:              df["use_ssl"].(bool),
+		Shield:              df["shield"].(string),
+		Port:                df["port"].(int),
+		BetweenBytesTimeout: backendTimeout(df, "between_bytes_timeout"),
+		ConnectTimeout:      backendTimeout(df, "connect_timeout"),
+		ErrorThreshold:      df["error_threshold"].(int),
+		FirstByteTimeout:    backendTimeout(df, "first_byte_timeout"),
+		MaxConn:             df["max_conn"].(int),
+		Weight:              df["weight"].(int),
+		RequestCondition:    df["request_condition"].(string),
+		HealthCheck:         healthcheck,
+	}
+
+	return &opts, nil
+}
+
+// backendTimeoutsInSeconds maps each backend timeout in milliseconds to the
+// field that sets it in seconds instead.
+var backendTimeoutsInSeconds = map[string]string{
+	"between_bytes_timeout": "between_bytes_timeout_seconds",
+	"connect_timeout":       "connect_timeout_seconds",
+	"first_byte_timeout":    "first_byte_timeout_seconds",
+}
+
+// backendTimeout returns a backend timeout in milliseconds, from its field in
+// seconds if that is set.
+func backendTimeout(df map[string]interface{}, timeout string) int {
+	if seconds, _ := df[backendTimeoutsInSeconds[timeout]].(int); seconds > 0 {
+		return seconds * 1000
+	}
+	return df[timeout].(int)
+}
+
+// backendOverrideHost resolves the override_host a backend is created with.
+// An explicit override_host wins; otherwise SSL backends use their
+// ssl_cert_hostname, the name SNI and certificate checks expect.
+func backendOverrideHost(df map[string]interface{}) string {
+	if host := df["override_host"].(string); host != "" {
+		return host
+	}
+	if df["use_ssl"].(bool) {
+		return df["ssl_cert_hostname"].(string)
+	}
+	return ""
+}
+
+func buildS3(s3Map interface{}) (*adapter.S3, error) {
+	sf := s3Map.(map[string]interface{})
+	opts := adapter.S3{
+		Name:              sf["name"].(string),
+		BucketName:        sf["bucket_name"].(string),
+		AccessKey:         sf["s3_access_key"].(string),
+		SecretKey:         sf["s3_secret_key"].(string),
+		Period:            sf["period"].(int),
+		GzipLevel:         sf["gzip_level"].(int),
+		Domain:            sf["domain"].(string),
+		Path:              sf["path"].(string),
+		Format:            sf["format"].(string),
+		FormatVersion:     sf["format_version"].(int),
+		TimestampFormat:   sf["timestamp_format"].(string),
+		ResponseCondition: sf["response_condition"].(string),
+		PublicKey:         sf["public_key"].(string),
+		FileMaxBytes:      sf["file_max_bytes"].(int),
+	}
+
+	return &opts, nil
+}
+
+func buildPapertrail(papertrailMap interface{}) (*adapter.Papertrail, error) {
+	pf := papertrailMap.(map[string]interface{})
+	opts := adapter.Papertrail{
+		Name:              pf["name"].(string),
+		Address:           pf["address"].(string),
+		Port:              pf["port"].(int),
+		Format:            pf["format"].(string),
+		ResponseCondition: pf["response_condition"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildSumologic(sumologicMap interface{}) (*adapter.Sumologic, error) {
+	sf := sumologicMap.(map[string]interface{})
+	opts := adapter.Sumologic{
+		Name:              sf["name"].(string),
+		URL:               sf["url"].(string),
+		Format:            sf["format"].(string),
+		FormatVersion:     sf["format_version"].(int),
+		ResponseCondition: sf["response_condition"].(string),
+		MessageType:       sf["message_type"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildGCS(gcsMap interface{}) (*adapter.GCS, error) {
+	sf := gcsMap.(map[string]interface{})
+	opts := adapter.GCS{
+		Name:              sf["name"].(string),
+		User:              sf["email"].(string),
+		Bucket:            sf["bucket_name"].(string),
+		SecretKey:         sf["secret_key"].(string),
+		Path:              sf["path"].(string),
+		Period:            sf["period"].(int),
+		GzipLevel:         sf["gzip_level"].(int),
+		Format:            sf["format"].(string),
+		FormatVersion:     sf["format_version"].(int),
+		TimestampFormat:   sf["timestamp_format"].(string),
+		ResponseCondition: sf["response_condition"].(string),
+		PublicKey:         sf["public_key"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildLoki(lokiMap interface{}) (*adapter.Loki, error) {
+	lf := lokiMap.(map[string]interface{})
+	opts := adapter.Loki{
+		Name:              lf["name"].(string),
+		URL:               lf["url"].(string),
+		AuthToken:         lf["auth_token"].(string),
+		TenantID:          lf["tenant_id"].(string),
+		Index:             lf["index"].(string),
+		Format:            lf["format"].(string),
+		FormatVersion:     lf["format_version"].(int),
+		ResponseCondition: lf["response_condition"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildHTTPSLogging(httpsMap interface{}) (*adapter.HTTPS, error) {
+	hf := httpsMap.(map[string]interface{})
+	opts := adapter.HTTPS{
+		Name:              hf["name"].(string),
+		URL:               hf["url"].(string),
+		RequestMaxEntries: hf["request_max_entries"].(int),
+		RequestMaxBytes:   hf["request_max_bytes"].(int),
+		ContentType:       hf["content_type"].(string),
+		HeaderName:        hf["header_name"].(string),
+		HeaderValue:       hf["header_value"].(string),
+		Method:            hf["method"].(string),
+		JSONFormat:        hf["json_format"].(string),
+		TLSCACert:         hf["tls_ca_cert"].(string),
+		TLSClientCert:     hf["tls_client_cert"].(string),
+		TLSClientKey:      hf["tls_client_key"].(string),
+		TLSHostname:       hf["tls_hostname"].(string),
+		Format:            hf["format"].(string),
+		FormatVersion:     hf["format_version"].(int),
+		ResponseCondition: hf["response_condition"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildOpenstackLogging(openstackMap interface{}) (*adapter.OpenStack, error) {
+	of := openstackMap.(map[string]interface{})
+	opts := adapter.OpenStack{
+		Name:              of["name"].(string),
+		URL:               of["url"].(string),
+		User:              of["user"].(string),
+		BucketName:        of["bucket_name"].(string),
+		AccessKey:         of["access_key"].(string),
+		Path:              of["path"].(string),
+		Period:            of["period"].(int),
+		GzipLevel:         of["gzip_level"].(int),
+		Format:            of["format"].(string),
+		FormatVersion:     of["format_version"].(int),
+		ResponseCondition: of["response_condition"].(string),
+		MessageType:       of["message_type"].(string),
+		TimestampFormat:   of["timestamp_format"].(string),
+		PublicKey:         of["public_key"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildLogShuttleLogging(logShuttleMap interface{}) (*adapter.LogShuttle, error) {
+	lf := logShuttleMap.(map[string]interface{})
+	opts := adapter.LogShuttle{
+		Name:              lf["name"].(string),
+		URL:               lf["url"].(string),
+		Token:             lf["token"].(string),
+		Format:            lf["format"].(string),
+		FormatVersion:     lf["format_version"].(int),
+		ResponseCondition: lf["response_condition"].(string),
+	}
+
+	return &opts, nil
+}
+
+func buildHeader(headerMap interface{}) (*adapter.Header, error) {
 	df := headerMap.(map[string]interface{})
-	opts := gofastly.CreateHeaderInput{
+	opts := adapter.Header{
 		Name:              df["name"].(string),
-		IgnoreIfSet:       gofastly.CBool(df["ignore_if_set"].(bool)),
+		IgnoreIfSet:       df["ignore_if_set"].(bool),
 		Destination:       df["destination"].(string),
-		Priority:          uint(df["priority"].(int)),
+		Priority:          df["priority"].(int),
 		Source:            df["source"].(string),
 		Regex:             df["regex"].(string),
 		Substitution:      df["substitution"].(string),
@@ -2284,28 +4720,14 @@ func buildHeader(headerMap interface{}) (*gofastly.CreateHeaderInput, error) {
 
 	act := strings.ToLower(df["action"].(string))
 	switch act {
-	case "set":
-		opts.Action = gofastly.HeaderActionSet
-	case "append":
-		opts.Action = gofastly.HeaderActionAppend
-	case "delete":
-		opts.Action = gofastly.HeaderActionDelete
-	case "regex":
-		opts.Action = gofastly.HeaderActionRegex
-	case "regex_repeat":
-		opts.Action = gofastly.HeaderActionRegexRepeat
+	case "set", "append", "delete", "regex", "regex_repeat":
+		opts.Action = act
 	}
 
 	ty := strings.ToLower(df["type"].(string))
 	switch ty {
-	case "request":
-		opts.Type = gofastly.HeaderTypeRequest
-	case "fetch":
-		opts.Type = gofastly.HeaderTypeFetch
-	case "cache":
-		opts.Type = gofastly.HeaderTypeCache
-	case "response":
-		opts.Type = gofastly.HeaderTypeResponse
+	case "request", "fetch", "cache", "response":
+		opts.Type = ty
 	}
 
 	return &opts, nil
@@ -2336,6 +4758,158 @@ func buildCacheSetting(cacheMap interface{}) (*gofastly.CreateCacheSettingInput,
 	return &opts, nil
 }
 
+// DefaultGzipContentTypes are the content types Fastly recommends compressing
+// with a gzip block.
+var DefaultGzipContentTypes = []string{
+	"text/html",
+	"application/x-javascript",
+	"text/css",
+	"application/javascript",
+	"text/javascript",
+	"application/json",
+	"application/vnd.ms-fontobject",
+	"application/x-font-opentype",
+	"application/x-font-truetype",
+	"application/x-font-ttf",
+	"application/xml",
+	"font/eot",
+	"font/opentype",
+	"font/otf",
+	"image/svg+xml",
+	"image/vnd.microsoft.icon",
+	"text/plain",
+	"text/xml",
+}
+
+// DefaultGzipExtensions are the file extensions, without a leading '.', Fastly
+// recommends compressing with a gzip block.
+var DefaultGzipExtensions = []string{
+	"css",
+	"js",
+	"html",
+	"eot",
+	"ico",
+	"otf",
+	"ttf",
+	"json",
+	"svg",
+}
+
+func buildGzip(gzipMap interface{}) (*gofastly.CreateGzipInput, error) {
+	df := gzipMap.(map[string]interface{})
+	opts := gofastly.CreateGzipInput{
+		Name:           df["name"].(string),
+		CacheCondition: df["cache_condition"].(string),
+	}
+
+	if v, ok := df["content_types"]; ok {
+		if len(v.(*schema.Set).List()) > 0 {
+			var cl []string
+			for _, c := range v.(*schema.Set).List() {
+				cl = append(cl, c.(string))
+			}
+			opts.ContentTypes = strings.Join(cl, " ")
+		}
+	}
+
+	if v, ok := df["extensions"]; ok {
+		if len(v.(*schema.Set).List()) > 0 {
+			var el []string
+			for _, e := range v.(*schema.Set).List() {
+				el = append(el, e.(string))
+			}
+			opts.Extensions = strings.Join(el, " ")
+		}
+	}
+
+	return &opts, nil
+}
+
+// gzipHash hashes a gzip block by its name, its sorted content_types and
+// extensions, its cache_condition and allow_empty, so blocks that share a name
+// but differ in any of these are distinct set elements.
+func gzipHash(v interface{}) int {
+	m := v.(map[string]interface{})
+	parts := []string{
+		m["name"].(string),
+		strings.Join(sortedStrings(m["content_types"]), " "),
+		strings.Join(sortedStrings(m["extensions"]), " "),
+	}
+	if v, ok := m["cache_condition"].(string); ok {
+		parts = append(parts, v)
+	} else {
+		parts = append(parts, "")
+	}
+	if v, ok := m["allow_empty"].(bool); ok {
+		parts = append(parts, fmt.Sprintf("%t", v))
+	} else {
+		parts = append(parts, "false")
+	}
+
+	return hashcode.String(strings.Join(parts, "\n"))
+}
+
+// sortedStrings returns the sorted elements of a set or list of strings.
+func sortedStrings(v interface{}) []string {
+	var raw []interface{}
+	switch l := v.(type) {
+	case *schema.Set:
+		raw = l.List()
+	case []interface{}:
+		raw = l
+	}
+
+	out := make([]string, 0, len(raw))
+	for _, s := range raw {
+		out = append(out, s.(string))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// splitGzipUpdates pairs gzips removed and added under the same name and
+// returns the updates that turn one into the other. UpdateGzip omits empty
+// fields, so a gzip that clears a field is still deleted and recreated. Pairs
+// that only differ in Terraform-only fields need no update at all.
+func splitGzipUpdates(remove, add []interface{}) (update []*gofastly.UpdateGzipInput, newRemove, newAdd []interface{}) {
+	removed := make(map[string]*gofastly.CreateGzipInput)
+	for _, gRaw := range remove {
+		old, _ := buildGzip(gRaw)
+		removed[old.Name] = old
+	}
+
+	paired := make(map[string]bool)
+	for _, gRaw := range add {
+		new, _ := buildGzip(gRaw)
+		old, ok := removed[new.Name]
+		if !ok || (old.ContentTypes != "" && new.ContentTypes == "") ||
+			(old.Extensions != "" && new.Extensions == "") ||
+			(old.CacheCondition != "" && new.CacheCondition == "") {
+			newAdd = append(newAdd, gRaw)
+			continue
+		}
+
+		paired[new.Name] = true
+		if *old == *new {
+			continue
+		}
+		update = append(update, &gofastly.UpdateGzipInput{
+			Name:           new.Name,
+			ContentTypes:   new.ContentTypes,
+			Extensions:     new.Extensions,
+			CacheCondition: new.CacheCondition,
+		})
+	}
+
+	for _, gRaw := range remove {
+		if !paired[gRaw.(map[string]interface{})["name"].(string)] {
+			newRemove = append(newRemove, gRaw)
+		}
+	}
+
+	return update, newRemove, newAdd
+}
+
 func flattenGzips(gzipsList []*gofastly.Gzip) []map[string]interface{} {
 	var gl []map[string]interface{}
 	for _, g := range gzipsList {
@@ -2376,9 +4950,17 @@ func flattenGzips(gzipsList []*gofastly.Gzip) []map[string]interface{} {
 	return gl
 }
 
-func flattenHealthchecks(healthcheckList []*gofastly.HealthCheck) []map[string]interface{} {
+// flattenHealthchecks converts healthchecks to state. extras holds the comment
+// and headers of each healthcheck by name, as go-fastly's HealthCheck does not
+// decode them.
+func flattenHealthchecks(healthcheckList []*gofastly.HealthCheck, extras map[string]*healthCheckExtra) []map[string]interface{} {
 	var hl []map[string]interface{}
 	for _, h := range healthcheckList {
+		extra := extras[h.Name]
+		if extra == nil {
+			extra = &healthCheckExtra{}
+		}
+
 		// Convert HealthChecks to a map for saving to state.
 		nh := map[string]interface{}{
 			"name":              h.Name,
@@ -2392,6 +4974,11 @@ func flattenHealthchecks(healthcheckList []*gofastly.HealthCheck) []map[string]i
 			"threshold":         h.Threshold,
 			"timeout":           h.Timeout,
 			"window":            h.Window,
+			"comment":           extra.Comment,
+		}
+
+		if len(extra.Headers) > 0 {
+			nh["headers"] = flattenHealthCheckHeaders(extra.Headers)
 		}
 
 		// prune any empty values that come from the default string value in structs
@@ -2407,7 +4994,32 @@ func flattenHealthchecks(healthcheckList []*gofastly.HealthCheck) []map[string]i
 	return hl
 }
 
-func flattenS3s(s3List []*gofastly.S3) []map[string]interface{} {
+// healthCheckHeaders converts the headers of a healthcheck to the
+// "Name: value" list Fastly takes, sorted by name.
+func healthCheckHeaders(headers interface{}) []string {
+	m, _ := headers.(map[string]interface{})
+	l := make([]string, 0, len(m))
+	for name, value := range m {
+		l = append(l, fmt.Sprintf("%s: %s", name, value))
+	}
+	sort.Strings(l)
+	return l
+}
+
+// flattenHealthCheckHeaders is the inverse of healthCheckHeaders.
+func flattenHealthCheckHeaders(headers []string) map[string]interface{} {
+	m := make(map[string]interface{}, len(headers))
+	for _, h := range headers {
+		i := strings.Index(h, ":")
+		if i == -1 {
+			continue
+		}
+		m[strings.TrimSpace(h[:i])] = strings.TrimSpace(h[i+1:])
+	}
+	return m
+}
+
+func flattenS3s(s3List []*adapter.S3) []map[string]interface{} {
 	var sl []map[string]interface{}
 	for _, s := range s3List {
 		// Convert S3s to a map for saving to state.
@@ -2424,6 +5036,10 @@ func flattenS3s(s3List []*gofastly.S3) []map[string]interface{} {
 			"format_version":     s.FormatVersion,
 			"timestamp_format":   s.TimestampFormat,
 			"response_condition": s.ResponseCondition,
+			"public_key":         s.PublicKey,
+		}
+		if s.FileMaxBytes != 0 {
+			ns["file_max_bytes"] = s.FileMaxBytes
 		}
 
 		// prune any empty values that come from the default string value in structs
@@ -2439,7 +5055,7 @@ func flattenS3s(s3List []*gofastly.S3) []map[string]interface{} {
 	return sl
 }
 
-func flattenPapertrails(papertrailList []*gofastly.Papertrail) []map[string]interface{} {
+func flattenPapertrails(papertrailList []*adapter.Papertrail) []map[string]interface{} {
 	var pl []map[string]interface{}
 	for _, p := range papertrailList {
 		// Convert Papertrails to a map for saving to state.
@@ -2464,7 +5080,7 @@ func flattenPapertrails(papertrailList []*gofastly.Papertrail) []map[string]inte
 	return pl
 }
 
-func flattenSumologics(sumologicList []*gofastly.Sumologic) []map[string]interface{} {
+func flattenSumologics(sumologicList []*adapter.Sumologic) []map[string]interface{} {
 	var l []map[string]interface{}
 	for _, p := range sumologicList {
 		// Convert Sumologic to a map for saving to state.
@@ -2474,7 +5090,7 @@ func flattenSumologics(sumologicList []*gofastly.Sumologic) []map[string]interfa
 			"format":             p.Format,
 			"response_condition": p.ResponseCondition,
 			"message_type":       p.MessageType,
-			"format_version":     int(p.FormatVersion),
+			"format_version":     p.FormatVersion,
 		}
 
 		// prune any empty values that come from the default string value in structs
@@ -2490,7 +5106,7 @@ func flattenSumologics(sumologicList []*gofastly.Sumologic) []map[string]interfa
 	return l
 }
 
-func flattenGCS(gcsList []*gofastly.GCS) []map[string]interface{} {
+func flattenGCS(gcsList []*adapter.GCS) []map[string]interface{} {
 	var GCSList []map[string]interface{}
 	for _, currentGCS := range gcsList {
 		// Convert gcs to a map for saving to state.
@@ -2500,10 +5116,13 @@ func flattenGCS(gcsList []*gofastly.GCS) []map[string]interface{} {
 			"bucket_name":        currentGCS.Bucket,
 			"secret_key":         currentGCS.SecretKey,
 			"path":               currentGCS.Path,
-			"period":             int(currentGCS.Period),
-			"gzip_level":         int(currentGCS.GzipLevel),
+			"period":             currentGCS.Period,
+			"gzip_level":         currentGCS.GzipLevel,
 			"response_condition": currentGCS.ResponseCondition,
 			"format":             currentGCS.Format,
+			"format_version":     currentGCS.FormatVersion,
+			"timestamp_format":   currentGCS.TimestampFormat,
+			"public_key":         currentGCS.PublicKey,
 		}
 
 		// prune any empty values that come from the default string value in structs
@@ -2519,6 +5138,130 @@ func flattenGCS(gcsList []*gofastly.GCS) []map[string]interface{} {
 	return GCSList
 }
 
+func flattenLoki(lokiList []*adapter.Loki) []map[string]interface{} {
+	var ll []map[string]interface{}
+	for _, l := range lokiList {
+		// Convert Loki to a map for saving to state.
+		nl := map[string]interface{}{
+			"name":               l.Name,
+			"url":                l.URL,
+			"auth_token":         l.AuthToken,
+			"tenant_id":          l.TenantID,
+			"index":              l.Index,
+			"format":             l.Format,
+			"format_version":     l.FormatVersion,
+			"response_condition": l.ResponseCondition,
+		}
+
+		// prune any empty values that come from the default string value in structs
+		for k, v := range nl {
+			if v == "" {
+				delete(nl, k)
+			}
+		}
+
+		ll = append(ll, nl)
+	}
+
+	return ll
+}
+
+func flattenHTTPSLogging(httpsList []*adapter.HTTPS) []map[string]interface{} {
+	var hl []map[string]interface{}
+	for _, h := range httpsList {
+		// Convert HTTPS logging to a map for saving to state.
+		nh := map[string]interface{}{
+			"name":                h.Name,
+			"url":                 h.URL,
+			"request_max_entries": h.RequestMaxEntries,
+			"request_max_bytes":   h.RequestMaxBytes,
+			"content_type":        h.ContentType,
+			"header_name":         h.HeaderName,
+			"header_value":        h.HeaderValue,
+			"method":              h.Method,
+			"json_format":         h.JSONFormat,
+			"tls_ca_cert":         h.TLSCACert,
+			"tls_client_cert":     h.TLSClientCert,
+			"tls_client_key":      h.TLSClientKey,
+			"tls_hostname":        h.TLSHostname,
+			"format":              h.Format,
+			"format_version":      h.FormatVersion,
+			"response_condition":  h.ResponseCondition,
+		}
+
+		// prune any empty values that come from the default string value in structs
+		for k, v := range nh {
+			if v == "" {
+				delete(nh, k)
+			}
+		}
+
+		hl = append(hl, nh)
+	}
+
+	return hl
+}
+
+func flattenOpenstackLogging(openstackList []*adapter.OpenStack) []map[string]interface{} {
+	var ol []map[string]interface{}
+	for _, o := range openstackList {
+		// Convert OpenStack logging to a map for saving to state.
+		no := map[string]interface{}{
+			"name":               o.Name,
+			"url":                o.URL,
+			"user":               o.User,
+			"bucket_name":        o.BucketName,
+			"access_key":         o.AccessKey,
+			"path":               o.Path,
+			"period":             o.Period,
+			"gzip_level":         o.GzipLevel,
+			"format":             o.Format,
+			"format_version":     o.FormatVersion,
+			"response_condition": o.ResponseCondition,
+			"message_type":       o.MessageType,
+			"timestamp_format":   o.TimestampFormat,
+			"public_key":         o.PublicKey,
+		}
+
+		// prune any empty values that come from the default string value in structs
+		for k, v := range no {
+			if v == "" {
+				delete(no, k)
+			}
+		}
+
+		ol = append(ol, no)
+	}
+
+	return ol
+}
+
+func flattenLogShuttleLogging(logShuttleList []*adapter.LogShuttle) []map[string]interface{} {
+	var ll []map[string]interface{}
+	for _, l := range logShuttleList {
+		// Convert Log Shuttle logging to a map for saving to state.
+		nl := map[string]interface{}{
+			"name":               l.Name,
+			"url":                l.URL,
+			"token":              l.Token,
+			"format":             l.Format,
+			"format_version":     l.FormatVersion,
+			"response_condition": l.ResponseCondition,
+		}
+
+		// prune any empty values that come from the default string value in structs
+		for k, v := range nl {
+			if v == "" {
+				delete(nl, k)
+			}
+		}
+
+		ll = append(ll, nl)
+	}
+
+	return ll
+}
+
 func flattenResponseObjects(responseObjectList []*gofastly.ResponseObject) []map[string]interface{} {
 	var rol []map[string]interface{}
 	for _, ro := range responseObjectList {
@@ -2546,15 +5289,19 @@ func flattenResponseObjects(responseObjectList []*gofastly.ResponseObject) []map
 	return rol
 }
 
-func flattenConditions(conditionList []*gofastly.Condition) []map[string]interface{} {
+// flattenConditions converts conditions to state. comments holds the comment
+// of each condition by name, as go-fastly's Condition does not decode it.
+func flattenConditions(conditionList []*adapter.Condition, comments map[string]string) []map[string]interface{} {
 	var cl []map[string]interface{}
 	for _, c := range conditionList {
 		// Convert Conditions to a map for saving to state.
 		nc := map[string]interface{}{
-			"name":      c.Name,
-			"statement": c.Statement,
-			"type":      c.Type,
-			"priority":  c.Priority,
+			"name":               c.Name,
+			"statement":          c.Statement,
+			"type":               strings.ToUpper(c.Type),
+			"priority":           c.Priority,
+			"effective_priority": c.Priority,
+			"comment":            comments[c.Name],
 		}
 
 		// prune any empty values that come from the default string value in structs
@@ -2596,10 +5343,41 @@ func flattenRequestSettings(rsList []*gofastly.RequestSetting) []map[string]inte
 			}
 		}
 
-		rl = append(rl, nrs)
+		rl = append(rl, nrs)
+	}
+
+	return rl
+}
+
+// suppressZeroMaxStaleAge treats a max_stale_age of 0 and one missing from
+// state as the same, as both disable serving stale content.
+func suppressZeroMaxStaleAge(k, old, new string, d *schema.ResourceData) bool {
+	return (old == "" || old == "0") && (new == "" || new == "0")
+}
+
+// joinHashKeys returns the comma separated hash_keys for a hash_keys_list,
+// sorted so that the order of the set does not matter.
+func joinHashKeys(keys []interface{}) string {
+	var l []string
+	for _, k := range keys {
+		if k := strings.TrimSpace(k.(string)); k != "" {
+			l = append(l, k)
+		}
 	}
+	sort.Strings(l)
+	return strings.Join(l, ",")
+}
 
-	return rl
+// splitHashKeys is the inverse of joinHashKeys, for reading hash_keys back
+// into hash_keys_list.
+func splitHashKeys(hashKeys string) []string {
+	var l []string
+	for _, k := range strings.Split(hashKeys, ",") {
+		if k := strings.TrimSpace(k); k != "" {
+			l = append(l, k)
+		}
+	}
+	return l
 }
 
 func buildRequestSetting(requestSettingMap interface{}) (*gofastly.CreateRequestSettingInput, error) {
@@ -2617,6 +5395,10 @@ func buildRequestSetting(requestSettingMap interface{}) (*gofastly.CreateRequest
 		RequestCondition: df["request_condition"].(string),
 	}
 
+	if l, ok := df["hash_keys_list"].(*schema.Set); ok && l.Len() > 0 {
+		opts.HashKeys = joinHashKeys(l.List())
+	}
+
 	act := strings.ToLower(df["action"].(string))
 	switch act {
 	case "lookup":
@@ -2667,6 +5449,29 @@ func flattenCacheSettings(csList []*gofastly.CacheSetting) []map[string]interfac
 	return csl
 }
 
+// fillVCLContent fetches the content of each VCL that was listed without it.
+// Storing the hash of empty content would show the VCL as changed on every
+// plan.
+func fillVCLContent(conn *gofastly.Client, service string, version int, vclList []*gofastly.VCL) error {
+	for i, vcl := range vclList {
+		if vcl.Content != "" {
+			continue
+		}
+
+		log.Printf("[DEBUG] VCL (%s) was listed without content, fetching it", vcl.Name)
+		full, err := conn.GetVCL(&gofastly.GetVCLInput{
+			Service: service,
+			Version: version,
+			Name:    vcl.Name,
+		})
+		if err != nil {
+			return err
+		}
+		vclList[i] = full
+	}
+	return nil
+}
+
 func flattenVCLs(vclList []*gofastly.VCL) []map[string]interface{} {
 	var vl []map[string]interface{}
 	for _, vcl := range vclList {
@@ -2690,6 +5495,820 @@ func flattenVCLs(vclList []*gofastly.VCL) []map[string]interface{} {
 	return vl
 }
 
+// validateServiceV1 runs the checks that compare several fields, or several
+// blocks, against each other. helper/schema has no hook for these at plan time,
+// so they run at the start of every apply. Checks of a single field belong in
+// its ValidateFunc instead, and checks that need the raw configuration in
+// provider.ValidateResource. Errors are returned together. Warnings can only be
+// logged, which the "Checks at Apply Time" section of the resource docs says,
+// so each new warning is listed there too.
+func validateServiceV1(d *schema.ResourceData, meta interface{}) error {
+	var errs *multierror.Error
+	if err := validateVCLs(d); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	ws, es := validateBackendTLS(d, meta.(*FastlyClient).strictTLS)
+	for _, w := range ws {
+		log.Printf("[WARN] %s", w)
+	}
+	errs = multierror.Append(errs, es...)
+
+	for _, check := range []func(*schema.ResourceData) ([]string, []error){
+		validateDomainsPresent,
+		validateHealthcheckTimeouts,
+		validateSumologicRegions,
+		validateEmptyGzips,
+		validateConditionReferences,
+		validateBackendRequestConditions,
+		validateBackendClientCerts,
+		validateBackendSNIHostnames,
+		validateBackendErrorThresholds,
+		validateProtectedBackends,
+		validateDefaultHosts,
+		validateForceSSL,
+		validateRequestSettingActions,
+		validateRequestSettingHashKeys,
+		validateResponseObjectBodies,
+	} {
+		ws, es := check(d)
+		for _, w := range ws {
+			log.Printf("[WARN] %s", w)
+		}
+		errs = multierror.Append(errs, es...)
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// validateDomainsPresent ensures the service has a domain, set through either
+// domain blocks or the domains list.
+func validateDomainsPresent(d *schema.ResourceData) (ws []string, es []error) {
+	if len(serviceV1Domains(d)) == 0 {
+		es = append(es, fmt.Errorf("at least one domain must be set, with a domain block or the domains list"))
+	}
+	return
+}
+
+// validateHealthcheckTimeouts ensures each healthcheck gives up before the
+// next check is due.
+func validateHealthcheckTimeouts(d *schema.ResourceData) (ws []string, es []error) {
+	for _, hRaw := range d.Get("healthcheck").(*schema.Set).List() {
+		hf := hRaw.(map[string]interface{})
+		if hf["timeout"].(int) >= hf["check_interval"].(int) {
+			es = append(es, fmt.Errorf(
+				"healthcheck %q: timeout (%d) must be less than check_interval (%d)",
+				hf["name"].(string), hf["timeout"].(int), hf["check_interval"].(int)))
+		}
+	}
+	return
+}
+
+// validateEmptyGzips rejects gzip configurations that match nothing, which
+// usually come from a dynamic block with empty inputs. allow_empty opts out.
+func validateEmptyGzips(d *schema.ResourceData) (ws []string, es []error) {
+	for _, gRaw := range d.Get("gzip").(*schema.Set).List() {
+		gf := gRaw.(map[string]interface{})
+		if gf["allow_empty"].(bool) || gf["cache_condition"].(string) != "" {
+			continue
+		}
+		if gf["content_types"].(*schema.Set).Len() == 0 && gf["extensions"].(*schema.Set).Len() == 0 {
+			es = append(es, fmt.Errorf(
+				"gzip %q: sets no content_types, extensions or cache_condition, so it compresses nothing; set allow_empty = true if this is intended",
+				gf["name"].(string)))
+		}
+	}
+	return
+}
+
+// loggingBlocks are the set blocks that configure logging endpoints.
+var loggingBlocks = []string{
+	"s3logging",
+	"papertrail",
+	"sumologic",
+	"gcslogging",
+	"loki",
+	"httpslogging",
+	"openstacklogging",
+	"logshuttlelogging",
+}
+
+// loggingBlockEndpoints maps each of the loggingBlocks to its API path
+// segment.
+var loggingBlockEndpoints = map[string]string{
+	"s3logging":         "s3",
+	"papertrail":        "papertrail",
+	"sumologic":         "sumologic",
+	"gcslogging":        "gcs",
+	"loki":              "grafanacloudlogs",
+	"httpslogging":      "https",
+	"openstacklogging":  "openstack",
+	"logshuttlelogging": "logshuttle",
+}
+
+// newLoggingConditions returns the names of the added conditions that logging
+// endpoints added in the same apply reference.
+func newLoggingConditions(d *schema.ResourceData, addConditions []interface{}) []string {
+	added := make(map[string]bool)
+	for _, cRaw := range addConditions {
+		added[cRaw.(map[string]interface{})["name"].(string)] = true
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, block := range loggingBlocks {
+		if !d.HasChange(block) {
+			continue
+		}
+		o, n := d.GetChange(block)
+		if o == nil {
+			o = new(schema.Set)
+		}
+		if n == nil {
+			n = new(schema.Set)
+		}
+		for _, eRaw := range n.(*schema.Set).Difference(o.(*schema.Set)).List() {
+			name := eRaw.(map[string]interface{})["response_condition"].(string)
+			if added[name] && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// conditionDeleteError explains a failure to delete a condition by naming the
+// objects on the version that still reference it, which Fastly's error does
+// not. If they cannot be listed, or none do, err is returned unchanged.
+func conditionDeleteError(conn *gofastly.Client, service string, version int, name string, err error) error {
+	refs, listErr := listConditionReferences(conn, service, version, name)
+	if listErr != nil {
+		log.Printf("[WARN] Error listing references to condition (%s): %s", name, listErr)
+		return err
+	}
+	if len(refs) == 0 {
+		return err
+	}
+	return fmt.Errorf("condition %q is still referenced by %s: %s", name, strings.Join(refs, ", "), err)
+}
+
+// listConditionReferences lists the objects on a service version whose request,
+// cache or response condition is the named condition, such as
+// `backend "origin-eu"`.
+func listConditionReferences(conn *gofastly.Client, service string, version int, name string) ([]string, error) {
+	var refs []string
+	ref := func(kind, object string, conditions ...string) {
+		for _, c := range conditions {
+			if c == name {
+				refs = append(refs, fmt.Sprintf("%s %q", kind, object))
+				return
+			}
+		}
+	}
+
+	backends, err := adapter.ListBackends(conn, service, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range backends {
+		ref("backend", b.Name, b.RequestCondition)
+	}
+
+	headers, err := adapter.ListHeaders(conn, service, version)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range headers {
+		ref("header", h.Name, h.RequestCondition, h.CacheCondition, h.ResponseCondition)
+	}
+
+	cacheSettings, err := conn.ListCacheSettings(&gofastly.ListCacheSettingsInput{Service: service, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cacheSettings {
+		ref("cache_setting", c.Name, c.CacheCondition)
+	}
+
+	gzips, err := conn.ListGzips(&gofastly.ListGzipsInput{Service: service, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range gzips {
+		ref("gzip", g.Name, g.CacheCondition)
+	}
+
+	responseObjects, err := conn.ListResponseObjects(&gofastly.ListResponseObjectsInput{Service: service, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range responseObjects {
+		ref("response_object", r.Name, r.RequestCondition, r.CacheCondition)
+	}
+
+	requestSettings, err := conn.ListRequestSettings(&gofastly.ListRequestSettingsInput{Service: service, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range requestSettings {
+		ref("request_setting", r.Name, r.RequestCondition)
+	}
+
+	for _, block := range loggingBlocks {
+		endpoints, err := adapter.ListLoggingConditions(conn, service, version, loggingBlockEndpoints[block])
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range endpoints {
+			ref(block, e.Name, e.ResponseCondition)
+		}
+	}
+
+	return refs, nil
+}
+
+// waitForCondition polls until a condition created on a version can be read,
+// for up to conditionReadyAttempts attempts.
+func waitForCondition(conn *gofastly.Client, service string, version int, name string) error {
+	for attempt := 1; ; attempt++ {
+		_, err := adapter.GetCondition(conn, service, version, name)
+		if err == nil {
+			return nil
+		}
+		if httpErr, ok := err.(*gofastly.HTTPError); !ok || !httpErr.IsNotFound() || attempt >= conditionReadyAttempts {
+			return fmt.Errorf("[ERR] Error waiting for condition (%s) on Version (%v) for (%s): %s", name, version, service, err)
+		}
+
+		log.Printf("[DEBUG] Condition (%s) is not available yet, retrying in %s", name, conditionReadyDelay)
+		time.Sleep(conditionReadyDelay)
+	}
+}
+
+// conditionReferences lists the fields that name a condition, along with the
+// condition type Fastly requires them to reference.
+var conditionReferences = []struct {
+	block, field, conditionType string
+}{
+	{"backend", "request_condition", "REQUEST"},
+	{"gzip", "cache_condition", "CACHE"},
+	{"header", "request_condition", "REQUEST"},
+	{"header", "cache_condition", "CACHE"},
+	{"header", "response_condition", "RESPONSE"},
+	{"surrogate_key", "cache_condition", "CACHE"},
+	{"s3logging", "response_condition", "RESPONSE"},
+	{"papertrail", "response_condition", "RESPONSE"},
+	{"sumologic", "response_condition", "RESPONSE"},
+	{"gcslogging", "response_condition", "RESPONSE"},
+	{"loki", "response_condition", "RESPONSE"},
+	{"httpslogging", "response_condition", "RESPONSE"},
+	{"openstacklogging", "response_condition", "RESPONSE"},
+	{"logshuttlelogging", "response_condition", "RESPONSE"},
+	{"response_object", "request_condition", "REQUEST"},
+	{"response_object", "cache_condition", "CACHE"},
+	{"request_setting", "request_condition", "REQUEST"},
+	{"cache_setting", "cache_condition", "CACHE"},
+}
+
+// validateConditionReferences ensures every condition named by another block
+// is defined on the service and has the type that block expects.
+func validateConditionReferences(d *schema.ResourceData) (ws []string, es []error) {
+	conditions := make(map[string]string)
+	for _, cRaw := range d.Get("condition").(*schema.Set).List() {
+		cf := cRaw.(map[string]interface{})
+		conditions[cf["name"].(string)] = cf["type"].(string)
+	}
+
+	for _, ref := range conditionReferences {
+		for _, eRaw := range d.Get(ref.block).(*schema.Set).List() {
+			ef := eRaw.(map[string]interface{})
+			name := ef[ref.field].(string)
+			if name == "" {
+				continue
+			}
+
+			conditionType, ok := conditions[name]
+			switch {
+			case !ok:
+				es = append(es, fmt.Errorf("%s %q: %s %q is not a defined condition",
+					ref.block, ef["name"].(string), ref.field, name))
+			case !strings.EqualFold(conditionType, ref.conditionType):
+				es = append(es, fmt.Errorf("%s %q: %s %q must be a %s condition, not %s",
+					ref.block, ef["name"].(string), ref.field, name, ref.conditionType, conditionType))
+			}
+		}
+	}
+	return
+}
+
+// validateBackendClientCerts checks that a backend's client certificate and
+// key for mutual TLS are set together and belong to each other.
+func validateBackendClientCerts(d *schema.ResourceData) (ws []string, es []error) {
+	for _, bRaw := range d.Get("backend").(*schema.Set).List() {
+		bf := bRaw.(map[string]interface{})
+		cert, key := bf["ssl_client_cert"].(string), bf["ssl_client_key"].(string)
+		switch {
+		case cert == "" && key == "":
+		case cert == "" || key == "":
+			es = append(es, fmt.Errorf(
+				"backend %q: ssl_client_cert and ssl_client_key must be set together", bf["name"].(string)))
+		default:
+			if _, err := tls.X509KeyPair([]byte(cert), []byte(key)); err != nil {
+				es = append(es, fmt.Errorf(
+					"backend %q: ssl_client_key does not match ssl_client_cert: %s", bf["name"].(string), err))
+			}
+		}
+	}
+	return
+}
+
+// validateBackendSNIHostnames warns about backends that verify the origin
+// certificate against ssl_cert_hostname but send no SNI hostname. Origins that
+// serve several certificates may then present the wrong one and fail the
+// handshake. ssl_hostname sets both, so it counts as an SNI hostname.
+func validateBackendSNIHostnames(d *schema.ResourceData) (ws []string, es []error) {
+	for _, bRaw := range d.Get("backend").(*schema.Set).List() {
+		bf := bRaw.(map[string]interface{})
+		certHostname := bf["ssl_cert_hostname"].(string)
+		if !bf["ssl_check_cert"].(bool) || certHostname == "" ||
+			bf["ssl_sni_hostname"].(string) != "" || bf["ssl_hostname"].(string) != "" {
+			continue
+		}
+		ws = append(ws, fmt.Sprintf(
+			"backend %q: ssl_cert_hostname is %q but ssl_sni_hostname is not set, so the TLS handshake may fail; consider setting ssl_sni_hostname = %q",
+			bf["name"].(string), certHostname, certHostname))
+	}
+	return
+}
+
+// validateBackendErrorThresholds warns about backends that both count errors
+// and have a healthcheck. Either one can mark the backend down, by different
+// rules, so the backend may go down when the healthcheck still passes.
+func validateBackendErrorThresholds(d *schema.ResourceData) (ws []string, es []error) {
+	for _, bRaw := range d.Get("backend").(*schema.Set).List() {
+		bf := bRaw.(map[string]interface{})
+		if bf["error_threshold"].(int) == 0 || bf["healthcheck"].(string) == "" {
+			continue
+		}
+		ws = append(ws, fmt.Sprintf(
+			"backend %q: error_threshold %d and healthcheck %q both mark the backend down, independently of each other; consider relying on the healthcheck alone",
+			bf["name"].(string), bf["error_threshold"].(int), bf["healthcheck"].(string)))
+	}
+	return
+}
+
+// validateSumologicRegions warns about sumologic endpoints whose url posts to
+// a different Sumo Logic deployment than their region. The url is sensitive,
+// so only the deployment it was matched to is reported.
+func validateSumologicRegions(d *schema.ResourceData) (ws []string, es []error) {
+	for _, sRaw := range d.Get("sumologic").(*schema.Set).List() {
+		sf := sRaw.(map[string]interface{})
+		region := sf["region"].(string)
+		if region == "" {
+			continue
+		}
+		if urlRegion, ok := sumologicURLRegion(sf["url"].(string)); ok && urlRegion != region {
+			ws = append(ws, fmt.Sprintf(
+				"sumologic %q: region is %s, but url posts to the %s deployment",
+				sf["name"].(string), region, urlRegion))
+		}
+	}
+	return
+}
+
+// validateBackendTimeouts checks that each backend timeout is set either in
+// milliseconds or in seconds. The millisecond fields have defaults, so this
+// reads the raw configuration, where a field left out is absent.
+func validateBackendTimeouts(c *terraform.ResourceConfig) (es []error) {
+	count, _ := c.Get("backend.#")
+	n, _ := count.(int)
+	for i := 0; i < n; i++ {
+		name, _ := c.Get(fmt.Sprintf("backend.%d.name", i))
+		for timeout, inSeconds := range backendTimeoutsInSeconds {
+			if c.IsSet(fmt.Sprintf("backend.%d.%s", i, timeout)) && c.IsSet(fmt.Sprintf("backend.%d.%s", i, inSeconds)) {
+				es = append(es, fmt.Errorf(
+					"backend %q: only one of %s and %s can be set", name, timeout, inSeconds))
+			}
+		}
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].Error() < es[j].Error() })
+	return
+}
+
+// validateBackendRequestConditions warns about request conditions Fastly will
+// never evaluate. Backends in the auto load balancing pool are chosen by
+// weight, so their request_condition is ignored.
+func validateBackendRequestConditions(d *schema.ResourceData) (ws []string, es []error) {
+	for _, bRaw := range d.Get("backend").(*schema.Set).List() {
+		bf := bRaw.(map[string]interface{})
+		if bf["auto_loadbalance"].(bool) && bf["request_condition"].(string) != "" {
+			ws = append(ws, fmt.Sprintf(
+				"backend %q: request_condition %q is ignored by Fastly because auto_loadbalance is true",
+				bf["name"].(string), bf["request_condition"].(string)))
+		}
+	}
+	return
+}
+
+// validateBackendTLS reports backends that connect over TLS, because use_ssl is
+// set or the port is 443, without fully verifying the origin: either
+// ssl_check_cert is false, or no hostname is given to check the certificate
+// against. Each problem is reported once, listing the backends. With strict
+// set the problems are errors, otherwise warnings.
+func validateBackendTLS(d *schema.ResourceData, strict bool) (ws []string, es []error) {
+	var unchecked, noHostname []string
+	for _, bRaw := range d.Get("backend").(*schema.Set).List() {
+		bf := bRaw.(map[string]interface{})
+		if !bf["use_ssl"].(bool) && bf["port"].(int) != 443 {
+			continue
+		}
+
+		name := bf["name"].(string)
+		if !bf["ssl_check_cert"].(bool) {
+			unchecked = append(unchecked, name)
+		}
+		if bf["ssl_cert_hostname"].(string) == "" && bf["ssl_hostname"].(string) == "" {
+			noHostname = append(noHostname, name)
+		}
+	}
+
+	var problems []string
+	if len(unchecked) > 0 {
+		sort.Strings(unchecked)
+		problems = append(problems, fmt.Sprintf(
+			"backends %q use TLS with ssl_check_cert = false, so the origin certificate is not verified", unchecked))
+	}
+	if len(noHostname) > 0 {
+		sort.Strings(noHostname)
+		problems = append(problems, fmt.Sprintf(
+			"backends %q use TLS without ssl_cert_hostname, so the origin certificate is not matched to a hostname", noHostname))
+	}
+
+	for _, p := range problems {
+		if strict {
+			es = append(es, fmt.Errorf("%s (strict_tls is set)", p))
+		} else {
+			ws = append(ws, p)
+		}
+	}
+	return
+}
+
+// validateProtectedBackends refuses to remove a backend listed in
+// protected_backends. The list from the previous apply is used, so a backend
+// has to be unprotected in one apply before it can be removed in another.
+// Renaming a backend removes the old name, so it is refused too.
+func validateProtectedBackends(d *schema.ResourceData) (ws []string, es []error) {
+	op, _ := d.GetChange("protected_backends")
+	protected := op.([]interface{})
+	if len(protected) == 0 {
+		return
+	}
+
+	ob, nb := d.GetChange("backend")
+	remaining := make(map[string]bool)
+	for _, bRaw := range nb.(*schema.Set).List() {
+		remaining[bRaw.(map[string]interface{})["name"].(string)] = true
+	}
+
+	existing := make(map[string]bool)
+	for _, bRaw := range ob.(*schema.Set).List() {
+		existing[bRaw.(map[string]interface{})["name"].(string)] = true
+	}
+
+	for _, nRaw := range protected {
+		name := nRaw.(string)
+		if existing[name] && !remaining[name] {
+			es = append(es, fmt.Errorf(
+				"backend %q is listed in protected_backends and cannot be removed or renamed; remove it from protected_backends and apply first",
+				name))
+		}
+	}
+	return
+}
+
+// serviceV1SetBlocks are the versioned set blocks of a service, in the order
+// Update applies them.
+var serviceV1SetBlocks = []string{
+	"condition",
+	"domain",
+	"domains",
+	"healthcheck",
+	"backend",
+	"pool",
+	"header",
+	"surrogate_key",
+	"gzip",
+	"s3logging",
+	"papertrail",
+	"sumologic",
+	"gcslogging",
+	"loki",
+	"httpslogging",
+	"openstacklogging",
+	"logshuttlelogging",
+	"response_object",
+	"request_setting",
+	"vcl",
+	"cache_setting",
+}
+
+// summarizeSetChanges describes the elements being removed from and added to
+// a set block by name, for example "header: removing [a, b], adding [c]".
+func summarizeSetChanges(block string, remove, add []interface{}) string {
+	if len(remove) == 0 && len(add) == 0 {
+		return fmt.Sprintf("%s: no changes", block)
+	}
+
+	var parts []string
+	if len(remove) > 0 {
+		parts = append(parts, fmt.Sprintf("removing [%s]", strings.Join(setElementNames(remove), ", ")))
+	}
+	if len(add) > 0 {
+		parts = append(parts, fmt.Sprintf("adding [%s]", strings.Join(setElementNames(add), ", ")))
+	}
+	return fmt.Sprintf("%s: %s", block, strings.Join(parts, ", "))
+}
+
+// maxParallelRequests bounds how many API calls for objects of one type run at
+// once. Services with dozens of backends or headers otherwise spend most of an
+// update deleting and creating them one at a time.
+var maxParallelRequests = 8
+
+// forEachParallel calls f for every element, with up to maxParallelRequests
+// calls running at once. It waits for all of them and returns every error.
+func forEachParallel(elements []interface{}, f func(interface{}) error) error {
+	sem := make(chan struct{}, maxParallelRequests)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for _, e := range elements {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e interface{}) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := f(e); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(e)
+	}
+	wg.Wait()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return multierror.Append(nil, errs...)
+	}
+}
+
+// keyedChange is an element present in both the old and new value of a block
+// whose fields differ.
+type keyedChange struct {
+	Old, New map[string]interface{}
+}
+
+// diffByKey compares the old and new elements of a block by key rather than
+// by set hash, so objects with server-assigned IDs can be updated in place
+// instead of deleted and recreated. Elements whose key is on one side only are
+// removed or added; elements on both sides are changed when any field other
+// than the computed fields in ignore differs.
+func diffByKey(old, new []interface{}, key func(map[string]interface{}) string, ignore ...string) (removed, added []map[string]interface{}, changed []keyedChange) {
+	skip := make(map[string]bool, len(ignore))
+	for _, k := range ignore {
+		skip[k] = true
+	}
+
+	oldByKey := make(map[string]map[string]interface{}, len(old))
+	for _, oRaw := range old {
+		o := oRaw.(map[string]interface{})
+		oldByKey[key(o)] = o
+	}
+
+	newKeys := make(map[string]bool, len(new))
+	for _, nRaw := range new {
+		n := nRaw.(map[string]interface{})
+		k := key(n)
+		newKeys[k] = true
+
+		o, ok := oldByKey[k]
+		if !ok {
+			added = append(added, n)
+			continue
+		}
+		for f, v := range n {
+			if !skip[f] && !reflect.DeepEqual(o[f], v) {
+				changed = append(changed, keyedChange{Old: o, New: n})
+				break
+			}
+		}
+	}
+
+	for _, oRaw := range old {
+		o := oRaw.(map[string]interface{})
+		if !newKeys[key(o)] {
+			removed = append(removed, o)
+		}
+	}
+
+	return
+}
+
+func setElementNames(elements []interface{}) []string {
+	names := make([]string, 0, len(elements))
+	for _, eRaw := range elements {
+		names = append(names, eRaw.(map[string]interface{})["name"].(string))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortVCLsForUpload orders VCLs so that included VCLs, sorted by name, come
+// before the main VCL.
+func sortVCLsForUpload(vcls []interface{}) []interface{} {
+	sorted := make([]interface{}, len(vcls))
+	copy(sorted, vcls)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		vi, vj := sorted[i].(map[string]interface{}), sorted[j].(map[string]interface{})
+		mi, mj := vi["main"].(bool), vj["main"].(bool)
+		if mi != mj {
+			return !mi
+		}
+		return vi["name"].(string) < vj["name"].(string)
+	})
+	return sorted
+}
+
+// validateDefaultHosts warns when a request_setting overrides the service
+// default_host with a different value, as the precedence is easy to miss.
+func validateDefaultHosts(d *schema.ResourceData) (ws []string, es []error) {
+	defaultHost := d.Get("default_host").(string)
+	if defaultHost == "" {
+		return
+	}
+
+	for _, rRaw := range d.Get("request_setting").(*schema.Set).List() {
+		rf := rRaw.(map[string]interface{})
+		host := rf["default_host"].(string)
+		if host == "" || host == defaultHost {
+			continue
+		}
+
+		when := "for every request"
+		if cond := rf["request_condition"].(string); cond != "" {
+			when = fmt.Sprintf("whenever condition %q matches", cond)
+		}
+		ws = append(ws, fmt.Sprintf(
+			"request_setting %q: default_host %q overrides the service default_host %q %s",
+			rf["name"].(string), host, defaultHost, when))
+	}
+	return
+}
+
+// validateRequestSettingActions checks the fields of each request_setting
+// against its action. Fastly accepts every combination, including ones that
+// contradict each other.
+func validateRequestSettingActions(d *schema.ResourceData) (ws []string, es []error) {
+	for _, rRaw := range d.Get("request_setting").(*schema.Set).List() {
+		rf := rRaw.(map[string]interface{})
+		name := rf["name"].(string)
+		switch strings.ToLower(rf["action"].(string)) {
+		case "pass":
+			if rf["force_miss"].(bool) {
+				es = append(es, fmt.Errorf(
+					"request_setting %q: force_miss cannot be combined with action \"pass\", which already skips the cache", name))
+			}
+		case "lookup":
+			if rf["bypass_busy_wait"].(bool) {
+				ws = append(ws, fmt.Sprintf(
+					"request_setting %q: bypass_busy_wait disables request collapsing for action \"lookup\", so concurrent misses for the same object all reach the origin", name))
+			}
+		}
+	}
+	return
+}
+
+// validateRequestSettingHashKeys checks that each request_setting sets at
+// most one of hash_keys and hash_keys_list.
+func validateRequestSettingHashKeys(d *schema.ResourceData) (ws []string, es []error) {
+	for _, rRaw := range d.Get("request_setting").(*schema.Set).List() {
+		rf := rRaw.(map[string]interface{})
+		l, _ := rf["hash_keys_list"].(*schema.Set)
+		if rf["hash_keys"].(string) != "" && l != nil && l.Len() > 0 {
+			es = append(es, fmt.Errorf(
+				"request_setting %q: only one of hash_keys and hash_keys_list can be set", rf["name"].(string)))
+		}
+	}
+	return
+}
+
+// fastlyTLSDomainSuffixes are the Fastly subdomains served over HTTPS with a
+// shared certificate, without activating TLS for the domain.
+var fastlyTLSDomainSuffixes = []string{".global.ssl.fastly.net", ".freetls.fastly.net"}
+
+// validateForceSSL warns about request settings that redirect to HTTPS on a
+// service none of whose domains is known to serve HTTPS. Whether TLS is
+// activated for a custom domain is not part of the configuration, so the
+// warning cannot be more precise than that.
+func validateForceSSL(d *schema.ResourceData) (ws []string, es []error) {
+	domains := serviceV1Domains(d)
+	for _, domain := range domains {
+		for _, suffix := range fastlyTLSDomainSuffixes {
+			if strings.HasSuffix(strings.ToLower(domain), suffix) {
+				return
+			}
+		}
+	}
+
+	sort.Strings(domains)
+	for _, rRaw := range d.Get("request_setting").(*schema.Set).List() {
+		rf := rRaw.(map[string]interface{})
+		if !rf["force_ssl"].(bool) {
+			continue
+		}
+		ws = append(ws, fmt.Sprintf(
+			"request_setting %q: force_ssl redirects HTTP requests to HTTPS, but none of the domains [%s] is a Fastly TLS subdomain; unless TLS is activated for one of them, the redirects loop",
+			rf["name"].(string), strings.Join(domains, ", ")))
+	}
+	return
+}
+
+// validateResponseObjectBodies warns about response objects whose content
+// Fastly will never send, because their status does not allow a body.
+func validateResponseObjectBodies(d *schema.ResourceData) (ws []string, es []error) {
+	for _, rRaw := range d.Get("response_object").(*schema.Set).List() {
+		rf := rRaw.(map[string]interface{})
+		status := rf["status"].(int)
+		if rf["content"].(string) == "" {
+			continue
+		}
+		if (status >= 100 && status < 200) || status == 204 || status == 304 {
+			ws = append(ws, fmt.Sprintf(
+				"response_object %q: Fastly strips the body of %d responses, so its content is never sent",
+				rf["name"].(string), status))
+		}
+	}
+	return
+}
+
+// vclContentHash returns the hash of VCL content, which may already be the
+// hash kept in state by hashVCLContent.
+func vclContentHash(content string) string {
+	if _, err := hex.DecodeString(content); err == nil && len(content) == 2*sha1.Size {
+		return content
+	}
+	return hashVCLContent(content)
+}
+
+// vclMainFlip reports whether the removed and added VCLs are the same names
+// with the same content, differing only in which of them is main, and if so
+// returns the name of the new main VCL.
+func vclMainFlip(remove, add []interface{}) (string, bool) {
+	if len(remove) == 0 || len(remove) != len(add) {
+		return "", false
+	}
+
+	contents := make(map[string]string, len(remove))
+	for _, vRaw := range remove {
+		vf := vRaw.(map[string]interface{})
+		contents[vf["name"].(string)] = vclContentHash(vf["content"].(string))
+	}
+
+	var mainVCL string
+	for _, vRaw := range add {
+		vf := vRaw.(map[string]interface{})
+		name := vf["name"].(string)
+		content, ok := contents[name]
+		if !ok || content != vclContentHash(vf["content"].(string)) {
+			return "", false
+		}
+		delete(contents, name)
+
+		if vf["main"].(bool) {
+			mainVCL = name
+		}
+	}
+	return mainVCL, mainVCL != "" && len(contents) == 0
+}
+
+// hashVCLContent is the StateFunc of VCL content. Only a hash of each VCL is
+// kept in state.
+func hashVCLContent(v interface{}) string {
+	switch v.(type) {
+	case string:
+		hash := sha1.Sum([]byte(v.(string)))
+		return hex.EncodeToString(hash[:])
+	default:
+		return ""
+	}
+}
+
 func validateVCLs(d *schema.ResourceData) error {
 	// TODO: this would be nice to move into a resource/collection validation function, once that is available
 	// (see https://github.com/hashicorp/terraform/pull/4348 and https://github.com/hashicorp/terraform/pull/6508)
@@ -2698,9 +6317,23 @@ func validateVCLs(d *schema.ResourceData) error {
 		return nil
 	}
 
+	return validateVCLList(vcls.(*schema.Set).List())
+}
+
+// validateVCLList checks a set of VCL configurations, each a map with a name
+// and a main flag, has unique names and exactly one main configuration. It is
+// shared by the service resource and the fastly_vcl_bundle data source.
+func validateVCLList(vcls []interface{}) error {
+	names := make(map[string]bool)
 	numberOfMainVCLs, numberOfIncludeVCLs := 0, 0
-	for _, vclElem := range vcls.(*schema.Set).List() {
+	for _, vclElem := range vcls {
 		vcl := vclElem.(map[string]interface{})
+		name, _ := vcl["name"].(string)
+		if names[name] {
+			return fmt.Errorf("more than one VCL configuration is named %q", name)
+		}
+		names[name] = true
+
 		if mainVal, hasMain := vcl["main"]; hasMain && mainVal.(bool) {
 			numberOfMainVCLs++
 		} else {
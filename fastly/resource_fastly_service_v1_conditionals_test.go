@@ -2,13 +2,20 @@ package fastly
 
 import (
 	"fmt"
+	"net/http"
+	"os"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
 )
 
 func TestAccFastlyServiceV1_conditional_basic(t *testing.T) {
@@ -43,6 +50,656 @@ func TestAccFastlyServiceV1_conditional_basic(t *testing.T) {
 	})
 }
 
+func TestValidateConditionReferences(t *testing.T) {
+	conditions := []interface{}{
+		map[string]interface{}{
+			"name":      "req",
+			"type":      "REQUEST",
+			"priority":  10,
+			"statement": `req.url ~ "^/yolo/"`,
+		},
+		map[string]interface{}{
+			"name":      "resp",
+			"type":      "RESPONSE",
+			"priority":  10,
+			"statement": "resp.status == 404",
+		},
+	}
+
+	cases := []struct {
+		raw    map[string]interface{}
+		errors []string
+	}{
+		{
+			raw: map[string]interface{}{
+				"condition": conditions,
+				"backend": []interface{}{
+					map[string]interface{}{
+						"name":              "amazon docs",
+						"address":           "aws.amazon.com",
+						"request_condition": "req",
+					},
+				},
+			},
+		},
+		{
+			raw: map[string]interface{}{
+				"condition": conditions,
+				"backend": []interface{}{
+					map[string]interface{}{
+						"name":              "amazon docs",
+						"address":           "aws.amazon.com",
+						"request_condition": "missing",
+					},
+				},
+			},
+			errors: []string{`backend "amazon docs": request_condition "missing" is not a defined condition`},
+		},
+		{
+			raw: map[string]interface{}{
+				"condition": conditions,
+				"backend": []interface{}{
+					map[string]interface{}{
+						"name":              "amazon docs",
+						"address":           "aws.amazon.com",
+						"request_condition": "resp",
+					},
+				},
+				"papertrail": []interface{}{
+					map[string]interface{}{
+						"name":               "papertrailtesting",
+						"address":            "test1.papertrailapp.com",
+						"port":               3600,
+						"response_condition": "req",
+					},
+				},
+			},
+			errors: []string{
+				`backend "amazon docs": request_condition "resp" must be a REQUEST condition, not RESPONSE`,
+				`papertrail "papertrailtesting": response_condition "req" must be a RESPONSE condition, not REQUEST`,
+			},
+		},
+		{
+			raw: map[string]interface{}{
+				"condition": conditions,
+				"cache_setting": []interface{}{
+					map[string]interface{}{
+						"name":            "alt_backend",
+						"action":          "pass",
+						"cache_condition": "serve_alt_backend",
+					},
+				},
+			},
+			errors: []string{`cache_setting "alt_backend": cache_condition "serve_alt_backend" is not a defined condition`},
+		},
+		{
+			raw: map[string]interface{}{
+				"condition": conditions,
+				"cache_setting": []interface{}{
+					map[string]interface{}{
+						"name":            "alt_backend",
+						"action":          "pass",
+						"cache_condition": "req",
+					},
+				},
+			},
+			errors: []string{`cache_setting "alt_backend": cache_condition "req" must be a CACHE condition, not REQUEST`},
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, c.raw)
+		_, errors := validateConditionReferences(d)
+		if len(errors) != len(c.errors) {
+			t.Fatalf("expected %d errors, got: %q", len(c.errors), errors)
+		}
+		for i, e := range errors {
+			if e.Error() != c.errors[i] {
+				t.Fatalf("Error matching:\nexpected: %s\ngot: %s", c.errors[i], e)
+			}
+		}
+	}
+}
+
+func TestValidateBackendRequestConditions(t *testing.T) {
+	cases := []struct {
+		autoLoadbalance  bool
+		requestCondition string
+		warnings         int
+	}{
+		{autoLoadbalance: true, requestCondition: "", warnings: 0},
+		{autoLoadbalance: false, requestCondition: "req", warnings: 0},
+		{autoLoadbalance: true, requestCondition: "req", warnings: 1},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"backend": []interface{}{
+				map[string]interface{}{
+					"name":              "amazon docs",
+					"address":           "aws.amazon.com",
+					"auto_loadbalance":  c.autoLoadbalance,
+					"request_condition": c.requestCondition,
+				},
+			},
+		})
+
+		ws, es := validateBackendRequestConditions(d)
+		if len(es) != 0 {
+			t.Fatalf("expected no errors, got: %q", es)
+		}
+		if len(ws) != c.warnings {
+			t.Fatalf("%#v: expected %d warnings, got: %q", c, c.warnings, ws)
+		}
+		if len(ws) > 0 && !strings.Contains(ws[0], `backend "amazon docs"`) {
+			t.Fatalf("expected warning to name the backend, got: %s", ws[0])
+		}
+	}
+}
+
+func TestResourceServiceV1Update_conditionForNewLogging(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+	defer testSetDelay(&conditionReadyDelay, 0)()
+
+	var calls []string
+	record := testRecordCalls(&calls)
+
+	var reads int
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":              testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":      record("clone", testFastlyJSON(`{"number": 2}`)),
+		"PUT /service/test-service/version/2":            testFastlyJSON(`{"number": 2}`),
+		"POST /service/test-service/version/2/condition": record("create condition", testFastlyJSON(`{"name": "is_error"}`)),
+		// Not visible on the first read
+		"GET /service/test-service/version/2/condition/is_error": record("read condition", func(w http.ResponseWriter, r *http.Request) {
+			if reads++; reads == 1 {
+				http.NotFound(w, r)
+				return
+			}
+			testFastlyJSON(`{"name": "is_error"}`)(w, r)
+		}),
+		"POST /service/test-service/version/2/logging/papertrail": record("create papertrail", testFastlyJSON(`{"name": "errors"}`)),
+		"GET /service/test-service/version/2/validate":            record("validate", testFastlyJSON(`{"status": "ok"}`)),
+		"PUT /service/test-service/version/2/activate":            record("activate", testFastlyJSON(`{"number": 2, "active": true}`)),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"condition": []interface{}{map[string]interface{}{
+			"name":      "is_error",
+			"type":      "RESPONSE",
+			"statement": "resp.status >= 500",
+		}},
+		"papertrail": []interface{}{map[string]interface{}{
+			"name":               "errors",
+			"address":            "logs.papertrailapp.com",
+			"port":               514,
+			"response_condition": "is_error",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := r.Apply(od.State(), diff, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"clone", "create condition", "read condition", "read condition", "create papertrail", "validate", "activate"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+}
+
+func TestResourceServiceV1Update_conditionStillReferenced(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	routes := map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":         testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone": testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":       testFastlyJSON(`{"number": 2}`),
+		"DELETE /service/test-service/version/2/condition/mobile": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"msg": "Condition 'mobile' is in use"}`)
+		},
+		// Referencing objects created outside Terraform
+		"GET /service/test-service/version/2/backend":          testFastlyJSON(`[{"name": "origin-eu", "request_condition": "mobile"}, {"name": "origin-us"}]`),
+		"GET /service/test-service/version/2/header":           testFastlyJSON(`[{"name": "vary-device", "response_condition": "mobile"}]`),
+		"GET /service/test-service/version/2/cache_settings":   testFastlyJSON(`[]`),
+		"GET /service/test-service/version/2/gzip":             testFastlyJSON(`[]`),
+		"GET /service/test-service/version/2/response_object":  testFastlyJSON(`[]`),
+		"GET /service/test-service/version/2/request_settings": testFastlyJSON(`[]`),
+	}
+	for _, block := range loggingBlocks {
+		routes["GET /service/test-service/version/2/logging/"+loggingBlockEndpoints[block]] = testFastlyJSON(`[]`)
+	}
+	routes["GET /service/test-service/version/2/logging/papertrail"] = testFastlyJSON(`[{"name": "mobile-logs", "response_condition": "mobile"}]`)
+	client, closeServer := testFastlyServer(t, routes)
+	defer closeServer()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"condition": []interface{}{map[string]interface{}{
+			"name":      "mobile",
+			"type":      "REQUEST",
+			"statement": `req.http.User-Agent ~ "Mobile"`,
+		}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, err = r.Apply(od.State(), diff, client)
+	expected := `condition "mobile" is still referenced by backend "origin-eu", header "vary-device", papertrail "mobile-logs": `
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected an error containing %q, got: %v", expected, err)
+	}
+	if !strings.Contains(err.Error(), "is in use") {
+		t.Fatalf("expected the Fastly error to be kept, got: %v", err)
+	}
+}
+
+func TestLoggingBlockEndpoints(t *testing.T) {
+	for _, block := range loggingBlocks {
+		if loggingBlockEndpoints[block] == "" {
+			t.Errorf("logging block %q has no API endpoint", block)
+		}
+	}
+}
+
+func TestWaitForCondition_notReady(t *testing.T) {
+	defer testSetDelay(&conditionReadyDelay, 0)()
+
+	var reads int
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/version/2/condition/is_error": func(w http.ResponseWriter, r *http.Request) {
+			reads++
+			http.NotFound(w, r)
+		},
+	})
+	defer closeServer()
+
+	err := waitForCondition(client.conn, "test-service", 2, "is_error")
+	if err == nil || !strings.Contains(err.Error(), "is_error") {
+		t.Fatalf("expected an error naming the condition, got: %v", err)
+	}
+	if reads != conditionReadyAttempts {
+		t.Fatalf("expected %d attempts, got: %d", conditionReadyAttempts, reads)
+	}
+}
+
+func TestResourceFastlyServiceV1_conditionPriority(t *testing.T) {
+	for priority, valid := range map[interface{}]bool{nil: true, 0: true, 10: true, -1: false} {
+		condition := map[string]interface{}{
+			"name":      "condition",
+			"statement": `req.url ~ "^/"`,
+			"type":      "REQUEST",
+		}
+		if priority != nil {
+			condition["priority"] = priority
+		}
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name": "tf-test",
+			"domain": []map[string]interface{}{
+				{"name": "test.notadomain.com"},
+			},
+			"condition": []map[string]interface{}{condition},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, errs := resourceServiceV1().Validate(terraform.NewResourceConfig(c))
+		if valid && len(errs) != 0 {
+			t.Fatalf("priority %v should be valid: %q", priority, errs)
+		}
+		if !valid && len(errs) != 1 {
+			t.Fatalf("priority %v should not be valid, got: %q", priority, errs)
+		}
+	}
+}
+
+func TestAssignConditionPriorities(t *testing.T) {
+	conditions := []interface{}{
+		map[string]interface{}{"name": "d", "type": "REQUEST", "priority": conditionPriorityAuto},
+		map[string]interface{}{"name": "a", "type": "REQUEST", "priority": 2},
+		map[string]interface{}{"name": "c", "type": "REQUEST", "priority": conditionPriorityAuto},
+		map[string]interface{}{"name": "b", "type": "REQUEST", "priority": conditionPriorityAuto},
+		map[string]interface{}{"name": "e", "type": "RESPONSE", "priority": conditionPriorityAuto},
+		map[string]interface{}{"name": "f", "type": "response", "priority": conditionPriorityAuto},
+		map[string]interface{}{"name": "g", "type": "CACHE", "priority": 1},
+		map[string]interface{}{"name": "h", "type": "REQUEST", "priority": 0},
+	}
+
+	expected := map[string]int{
+		"a": 2,
+		"b": 1,
+		"c": 3,
+		"d": 4,
+		"e": 1,
+		"f": 2,
+		"g": 1,
+		"h": 0,
+	}
+	if got := assignConditionPriorities(conditions, nil); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", expected, got)
+	}
+
+	// A kept condition holds on to its priority, and new conditions are
+	// numbered around it.
+	kept := map[string]int{"d": 1}
+	expected = map[string]int{
+		"a": 2,
+		"b": 3,
+		"c": 4,
+		"d": 1,
+		"e": 1,
+		"f": 2,
+		"g": 1,
+		"h": 0,
+	}
+	if got := assignConditionPriorities(conditions, kept); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", expected, got)
+	}
+}
+
+func TestAccFastlyServiceV1_conditional_autoPriority(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	explicit := gofastly.Condition{
+		Name:      "explicit",
+		Priority:  1,
+		Type:      "REQUEST",
+		Statement: `req.url ~ "^/explicit/"`,
+	}
+	autoA := gofastly.Condition{
+		Name:      "auto a",
+		Priority:  2,
+		Type:      "REQUEST",
+		Statement: `req.url ~ "^/a/"`,
+	}
+	autoB := gofastly.Condition{
+		Name:      "auto b",
+		Priority:  3,
+		Type:      "REQUEST",
+		Statement: `req.url ~ "^/b/"`,
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1ConditionConfig_autoPriority(name, domainName1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1ConditionalAttributes(&service, name, []*gofastly.Condition{&explicit, &autoA, &autoB}),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "condition.#", "3"),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceFastlyFlattenConditions_comment(t *testing.T) {
+	remote := []*adapter.Condition{
+		{Name: "commented", Statement: `req.url ~ "^/a/"`, Type: "REQUEST", Priority: 1},
+		{Name: "bare", Statement: `req.url ~ "^/b/"`, Type: "REQUEST", Priority: 2},
+	}
+	comments := map[string]string{"commented": "Matches the /a/ tree", "bare": ""}
+
+	out := flattenConditions(remote, comments)
+	if got := out[0]["comment"]; got != "Matches the /a/ tree" {
+		t.Fatalf("expected the comment to be kept, got: %#v", got)
+	}
+	if _, ok := out[1]["comment"]; ok {
+		t.Fatalf("expected the empty comment to be pruned, got: %#v", out[1])
+	}
+}
+
+func TestResourceFastlyFlattenConditions_normalized(t *testing.T) {
+	remote := []*adapter.Condition{
+		{Name: "lowercase", Statement: `req.url ~ "^/a/"`, Type: "request", Priority: 10},
+	}
+
+	out := flattenConditions(remote, nil)
+	if got := out[0]["type"]; got != "REQUEST" {
+		t.Fatalf("expected the type to be uppercased, got: %#v", got)
+	}
+	if got := out[0]["priority"]; got != 10 {
+		t.Fatalf("expected the priority as an int, got: %#v", got)
+	}
+}
+
+func TestConditionHash(t *testing.T) {
+	condition := func(conditionType, statement string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":      "condition",
+			"type":      conditionType,
+			"statement": statement,
+			"priority":  10,
+			"comment":   "",
+		}
+	}
+
+	base := conditionHash(condition("REQUEST", `req.url ~ "^/a/"`))
+	for _, c := range []map[string]interface{}{
+		condition("request", `req.url ~ "^/a/"`),
+		condition("Request", "\n"+`req.url ~ "^/a/"`+"\n"),
+	} {
+		if conditionHash(c) != base {
+			t.Errorf("expected %v to hash like its normalized form", c)
+		}
+	}
+
+	for _, c := range []map[string]interface{}{
+		condition("RESPONSE", `req.url ~ "^/a/"`),
+		condition("REQUEST", `req.url ~ "^/b/"`),
+	} {
+		if conditionHash(c) == base {
+			t.Errorf("expected %v to hash differently", c)
+		}
+	}
+}
+
+// A condition created outside Terraform with a lowercase type and a priority
+// sent as a string must import without a diff against the equivalent
+// configuration.
+func TestAccFastlyServiceV1_conditional_importNormalized(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skip(fmt.Sprintf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar))
+	}
+	testAccPreCheck(t)
+
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domain := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	conn, err := gofastly.NewClient(os.Getenv("FASTLY_API_KEY"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	service := testAccCreateServiceWithLowercaseCondition(t, conn, name, domain)
+	defer func() {
+		conn.DeactivateVersion(&gofastly.DeactivateVersionInput{Service: service, Version: 1})
+		if err := conn.DeleteService(&gofastly.DeleteServiceInput{ID: service}); err != nil {
+			t.Errorf("error deleting Fastly Service (%s): %s", service, err)
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:        testAccServiceV1ConditionConfig(name, domain),
+				ResourceName:  "fastly_service_v1.foo",
+				ImportState:   true,
+				ImportStateId: service,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 imported state, got %d", len(states))
+					}
+
+					c, err := config.NewRawConfig(map[string]interface{}{
+						"name": name,
+						"condition": []interface{}{map[string]interface{}{
+							"name":      "some amz condition",
+							"type":      "REQUEST",
+							"statement": `req.url ~ "^/yolo/"`,
+							"priority":  10,
+						}},
+					})
+					if err != nil {
+						return err
+					}
+					diff, err := resourceServiceV1().Diff(states[0], terraform.NewResourceConfig(c))
+					if err != nil {
+						return err
+					}
+					if diff == nil {
+						return nil
+					}
+					for k, attr := range diff.Attributes {
+						if strings.HasPrefix(k, "condition.") {
+							return fmt.Errorf("expected no condition diff after import, got %s: %#v", k, attr)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// testAccCreateServiceWithLowercaseCondition creates and activates a service
+// through the API alone, with a REQUEST condition whose type is lowercase and
+// whose priority is a string, and returns its ID.
+func testAccCreateServiceWithLowercaseCondition(t *testing.T, conn *gofastly.Client, name, domain string) string {
+	service, err := conn.CreateService(&gofastly.CreateServiceInput{Name: name})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := conn.CreateDomain(&gofastly.CreateDomainInput{Service: service.ID, Version: 1, Name: domain}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := conn.CreateBackend(&gofastly.CreateBackendInput{Service: service.ID, Version: 1, Name: "amazon docs", Address: "aws.amazon.com"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resp, err := conn.PostForm(fmt.Sprintf("/service/%s/version/1/condition", service.ID), &struct {
+		Name      string `form:"name"`
+		Type      string `form:"type"`
+		Statement string `form:"statement"`
+		Priority  string `form:"priority"`
+	}{"some amz condition", "request", `req.url ~ "^/yolo/"`, "10"}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+
+	if _, err := conn.ActivateVersion(&gofastly.ActivateVersionInput{Service: service.ID, Version: 1}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return service.ID
+}
+
+func TestAccFastlyServiceV1_conditional_comment(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1ConditionConfig_comment(name, domainName1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1ConditionComment(&service, "commented", "Requests for the blog, old and new URLs"),
+					testAccCheckFastlyServiceV1ConditionComment(&service, "bare", ""),
+				),
+			},
+
+			// The comment reads back unchanged, so there is nothing to apply
+			resource.TestStep{
+				Config:   testAccServiceV1ConditionConfig_comment(name, domainName1),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1ConditionComment(service *gofastly.ServiceDetail, condition, comment string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		comments, err := listConditionComments(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Condition comments for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if comments[condition] != comment {
+			return fmt.Errorf("Bad comment for Condition (%s), expected (%q), got (%q)", condition, comment, comments[condition])
+		}
+
+		return nil
+	}
+}
+
+func TestAccFastlyServiceV1_conditional_missingReference(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccServiceV1ConditionConfig_missingReference(name, domainName1),
+				ExpectError: regexp.MustCompile(`request_condition "no such condition" is not a defined condition`),
+			},
+		},
+	})
+}
+
 func testAccCheckFastlyServiceV1ConditionalAttributes(service *gofastly.ServiceDetail, name string, conditions []*gofastly.Condition) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -120,3 +777,96 @@ resource "fastly_service_v1" "foo" {
   force_destroy = true
 }`, name, domain)
 }
+
+func testAccServiceV1ConditionConfig_missingReference(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address           = "aws.amazon.com"
+    name              = "amazon docs"
+    auto_loadbalance  = false
+    request_condition = "no such condition"
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
+func testAccServiceV1ConditionConfig_autoPriority(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  condition {
+    name      = "explicit"
+    type      = "REQUEST"
+    statement = "req.url ~ \"^/explicit/\""
+    priority  = 1
+  }
+
+  condition {
+    name      = "auto b"
+    type      = "REQUEST"
+    statement = "req.url ~ \"^/b/\""
+  }
+
+  condition {
+    name      = "auto a"
+    type      = "REQUEST"
+    statement = "req.url ~ \"^/a/\""
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
+func testAccServiceV1ConditionConfig_comment(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  condition {
+    name      = "commented"
+    type      = "REQUEST"
+    statement = "req.url ~ \"^/blog/\" || req.url ~ \"^/news/\""
+    priority  = 1
+    comment   = "Requests for the blog, old and new URLs"
+  }
+
+  condition {
+    name      = "bare"
+    type      = "REQUEST"
+    statement = "req.url ~ \"^/b/\""
+    priority  = 2
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
@@ -2,41 +2,71 @@ package fastly
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
 )
 
 func TestResourceFastlyFlattenGCS(t *testing.T) {
 	cases := []struct {
-		remote []*gofastly.GCS
+		remote []*adapter.GCS
 		local  []map[string]interface{}
 	}{
 		{
-			remote: []*gofastly.GCS{
-				&gofastly.GCS{
-					Name:      "GCS collector",
-					User:      "email@example.com",
-					Bucket:    "bucketName",
-					SecretKey: "secretKey",
-					Format:    "log format",
-					Period:    3600,
-					GzipLevel: 0,
+			remote: []*adapter.GCS{
+				&adapter.GCS{
+					Name:          "GCS collector",
+					User:          "email@example.com",
+					Bucket:        "bucketName",
+					SecretKey:     "secretKey",
+					Format:        "log format",
+					FormatVersion: 2,
+					Period:        3600,
+					GzipLevel:     0,
 				},
 			},
 			local: []map[string]interface{}{
 				map[string]interface{}{
-					"name":        "GCS collector",
-					"email":       "email@example.com",
-					"bucket_name": "bucketName",
-					"secret_key":  "secretKey",
-					"format":      "log format",
-					"period":      3600,
-					"gzip_level":  0,
+					"name":           "GCS collector",
+					"email":          "email@example.com",
+					"bucket_name":    "bucketName",
+					"secret_key":     "secretKey",
+					"format":         "log format",
+					"format_version": 2,
+					"period":         3600,
+					"gzip_level":     0,
+				},
+			},
+		},
+		{
+			remote: []*adapter.GCS{
+				&adapter.GCS{
+					Name:          "GCS collector",
+					User:          "email@example.com",
+					Bucket:        "bucketName",
+					FormatVersion: 1,
+					PublicKey:     testPGPPublicKey,
+				},
+			},
+			local: []map[string]interface{}{
+				map[string]interface{}{
+					"name":           "GCS collector",
+					"email":          "email@example.com",
+					"bucket_name":    "bucketName",
+					"format_version": 1,
+					"period":         0,
+					"gzip_level":     0,
+					"public_key":     testPGPPublicKey,
 				},
 			},
 		},
@@ -50,6 +80,269 @@ func TestResourceFastlyFlattenGCS(t *testing.T) {
 	}
 }
 
+// testGCSInherited configures a gcslogging endpoint without credentials of
+// its own, so it inherits gcp_credentials.
+func testGCSInherited() map[string]interface{} {
+	return map[string]interface{}{
+		"name":             "inherited",
+		"bucket_name":      "fastly-logs",
+		"period":           3600,
+		"format":           "%h %l %u %t %r %>s",
+		"timestamp_format": "%Y-%m-%dT%H:%M:%S.000",
+	}
+}
+
+// testCheckNoInheritedCredentials fails if any attribute of a raw state holds
+// the inherited credentials, or if the endpoint's credential fields are not
+// left empty.
+func testCheckNoInheritedCredentials(t *testing.T, state *terraform.InstanceState, email, secret string) {
+	var checked int
+	for k, v := range state.Attributes {
+		if v == email || v == secret {
+			t.Fatalf("expected the inherited credentials not to be stored in state, found %s", k)
+		}
+		if strings.HasPrefix(k, "gcslogging.") && (strings.HasSuffix(k, ".email") || strings.HasSuffix(k, ".secret_key")) {
+			checked++
+			if v != "" {
+				t.Fatalf("expected %s to be empty, got: %q", k, v)
+			}
+		}
+	}
+	if checked != 2 {
+		t.Fatalf("expected the endpoint's email and secret_key in state, got: %#v", state.Attributes)
+	}
+}
+
+func TestResourceServiceV1Update_gcsloggingInheritedCredentials(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	const email, secret = "logs@project.iam.gserviceaccount.com", "gcpsecret"
+
+	var sent url.Values
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":         testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone": testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":       testFastlyJSON(`{"number": 2}`),
+		"POST /service/test-service/version/2/logging/gcs": func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			sent = r.PostForm
+			testFastlyJSON(`{"name": "inherited"}`)(w, r)
+		},
+		"PUT /service/test-service/version/2/logging/gcs/inherited": testFastlyJSON(`{"name": "inherited", "format_version": "1"}`),
+		"GET /service/test-service/version/2/validate":              testFastlyJSON(`{"status": "ok"}`),
+		"PUT /service/test-service/version/2/activate":              testFastlyJSON(`{"number": 2, "active": true}`),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+	client.loggingDefaults = (&Config{GCPEmail: email, GCPSecretKey: secret}).loggingDefaults()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":       "test",
+		"domain":     []interface{}{map[string]interface{}{"name": "example.com"}},
+		"gcslogging": []interface{}{testGCSInherited()},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := r.Apply(od.State(), diff, client)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if sent.Get("user") != email || sent.Get("secret_key") != secret {
+		t.Fatalf("expected the inherited credentials to be sent to Fastly, got: %v", sent)
+	}
+	testCheckNoInheritedCredentials(t, state, email, secret)
+}
+
+func TestResourceServiceV1Read_gcsloggingInheritedCredentials(t *testing.T) {
+	const email, secret = "logs@project.iam.gserviceaccount.com", "gcpsecret"
+	defaults := (&Config{GCPEmail: email, GCPSecretKey: secret}).loggingDefaults()["gcslogging"]
+
+	r := resourceServiceV1()
+	raw := map[string]interface{}{
+		"name":       "test",
+		"domain":     []interface{}{map[string]interface{}{"name": "example.com"}},
+		"gcslogging": []interface{}{testGCSInherited()},
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+	d.SetId("test-service")
+
+	// Fastly returns the credentials the endpoint was created with
+	remote := flattenGCS([]*adapter.GCS{
+		&adapter.GCS{
+			Name:            "inherited",
+			Bucket:          "fastly-logs",
+			User:            email,
+			SecretKey:       secret,
+			Period:          3600,
+			Format:          "%h %l %u %t %r %>s",
+			FormatVersion:   1,
+			TimestampFormat: "%Y-%m-%dT%H:%M:%S.000",
+		},
+	})
+	omitLoggingDefaults(remote, priorElementsByName(d, "gcslogging"), defaults)
+	if err := d.Set("gcslogging", remote); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := d.State()
+	testCheckNoInheritedCredentials(t, state, email, secret)
+
+	c, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(state, terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil {
+		for k := range diff.Attributes {
+			if strings.HasPrefix(k, "gcslogging") {
+				t.Fatalf("expected no drift for inherited credentials, got: %#v", diff.Attributes)
+			}
+		}
+	}
+}
+
+func TestResourceServiceV1Update_gcsloggingFormatVersion(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var sent string
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":         testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone": testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":       testFastlyJSON(`{"number": 2}`),
+		"POST /service/test-service/version/2/logging/gcs": func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			sent = r.PostForm.Get("format_version")
+			testFastlyJSON(`{"name": "gcs", "format_version": "2"}`)(w, r)
+		},
+		"GET /service/test-service/version/2/validate": testFastlyJSON(`{"status": "ok"}`),
+		"PUT /service/test-service/version/2/activate": testFastlyJSON(`{"number": 2, "active": true}`),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"gcslogging": []interface{}{map[string]interface{}{
+			"name":           "gcs",
+			"email":          "email@example.com",
+			"bucket_name":    "bucketName",
+			"secret_key":     "secretKey",
+			"format_version": 2,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := r.Apply(od.State(), diff, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if sent != "2" {
+		t.Fatalf("expected format_version 2 to be sent to Fastly, got: %q", sent)
+	}
+}
+
+func TestAccFastlyServiceV1_gcslogging_formatVersion(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	gcsName := fmt.Sprintf("gcs %s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceV1Config_gcsFormatVersion(name, gcsName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_gcs(&service, name, gcsName),
+					testAccCheckFastlyServiceV1GCSFormatVersion(&service, gcsName, 2),
+				),
+			},
+
+			// format_version is read back without a diff
+			{
+				Config:   testAccServiceV1Config_gcsFormatVersion(name, gcsName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// testAccCheckFastlyServiceV1GCSFormatVersion checks the format version of a
+// GCS endpoint both in Fastly and in the state read back from it.
+func testAccCheckFastlyServiceV1GCSFormatVersion(service *gofastly.ServiceDetail, gcsName string, formatVersion int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		gcsList, err := adapter.ListGCSs(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up GCS Logging for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+		var found bool
+		for _, g := range gcsList {
+			if g.Name != gcsName {
+				continue
+			}
+			found = true
+			if g.FormatVersion != formatVersion {
+				return fmt.Errorf("GCS format_version mismatch, expected: %d, got: %d", formatVersion, g.FormatVersion)
+			}
+		}
+		if !found {
+			return fmt.Errorf("GCS Logging (%s) not found", gcsName)
+		}
+
+		attrs := s.RootModule().Resources["fastly_service_v1.foo"].Primary.Attributes
+		for k, v := range attrs {
+			if strings.HasPrefix(k, "gcslogging.") && strings.HasSuffix(k, ".format_version") {
+				if v != fmt.Sprintf("%d", formatVersion) {
+					return fmt.Errorf("GCS format_version in state mismatch, expected: %d, got: %s", formatVersion, v)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("GCS format_version missing from state: %#v", attrs)
+	}
+}
+
 func TestAccFastlyServiceV1_gcslogging(t *testing.T) {
 	var service gofastly.ServiceDetail
 	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
@@ -129,3 +422,33 @@ resource "fastly_service_v1" "foo" {
   force_destroy = true
 }`, name, backendName, gcsName)
 }
+
+func testAccServiceV1Config_gcsFormatVersion(name, gcsName string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  gcslogging {
+    name           = "%s"
+    email          = "email@example.com"
+    bucket_name    = "bucketName"
+    secret_key     = "secretKey"
+    format         = "%%h %%t %%r %%>s"
+    format_version = 2
+  }
+
+  force_destroy = true
+}`, name, backendName, gcsName)
+}
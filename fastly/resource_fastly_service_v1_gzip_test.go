@@ -2,9 +2,15 @@ package fastly
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -102,6 +108,252 @@ func TestFastlyServiceV1_FlattenGzips(t *testing.T) {
 	}
 }
 
+func TestValidateEmptyGzips(t *testing.T) {
+	cases := []struct {
+		name   string
+		gzip   map[string]interface{}
+		errors int
+	}{
+		{
+			name:   "empty",
+			gzip:   map[string]interface{}{"name": "gzip"},
+			errors: 1,
+		},
+		{
+			name:   "empty and allowed",
+			gzip:   map[string]interface{}{"name": "gzip", "allow_empty": true},
+			errors: 0,
+		},
+		{
+			name:   "cache condition only",
+			gzip:   map[string]interface{}{"name": "gzip", "cache_condition": "condition"},
+			errors: 0,
+		},
+		{
+			name:   "extensions",
+			gzip:   map[string]interface{}{"name": "gzip", "extensions": []interface{}{"css"}},
+			errors: 0,
+		},
+		{
+			name:   "content types",
+			gzip:   map[string]interface{}{"name": "gzip", "content_types": []interface{}{"text/html"}},
+			errors: 0,
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"gzip": []interface{}{c.gzip},
+		})
+		_, errors := validateEmptyGzips(d)
+		if len(errors) != c.errors {
+			t.Fatalf("%s: expected %d errors, got: %q", c.name, c.errors, errors)
+		}
+	}
+}
+
+func TestSplitGzipUpdates(t *testing.T) {
+	gzip := func(name, extensions, cacheCondition string, allowEmpty bool) map[string]interface{} {
+		var el []interface{}
+		if extensions != "" {
+			el = append(el, extensions)
+		}
+		return map[string]interface{}{
+			"name":            name,
+			"content_types":   schema.NewSet(schema.HashString, nil),
+			"extensions":      schema.NewSet(schema.HashString, el),
+			"cache_condition": cacheCondition,
+			"allow_empty":     allowEmpty,
+		}
+	}
+
+	remove := []interface{}{
+		gzip("changed", "css", "", false),
+		gzip("cleared", "css", "condition", false),
+		gzip("local", "css", "", false),
+		gzip("removed", "css", "", false),
+	}
+	add := []interface{}{
+		gzip("changed", "js", "", false),
+		gzip("cleared", "css", "", false),
+		gzip("local", "css", "", true),
+		gzip("added", "css", "", false),
+	}
+
+	update, newRemove, newAdd := splitGzipUpdates(remove, add)
+
+	if len(update) != 1 || update[0].Name != "changed" || update[0].Extensions != "js" {
+		t.Fatalf("expected only %q to be updated, got: %#v", "changed", update)
+	}
+	names := func(l []interface{}) (n []string) {
+		for _, g := range l {
+			n = append(n, g.(map[string]interface{})["name"].(string))
+		}
+		return
+	}
+	if got := names(newRemove); !reflect.DeepEqual(got, []string{"cleared", "removed"}) {
+		t.Fatalf("unexpected removals: %q", got)
+	}
+	if got := names(newAdd); !reflect.DeepEqual(got, []string{"cleared", "added"}) {
+		t.Fatalf("unexpected additions: %q", got)
+	}
+}
+
+func TestDefaultGzipLists(t *testing.T) {
+	toSet := func(l []string) *schema.Set {
+		s := schema.NewSet(schema.HashString, nil)
+		for _, v := range l {
+			s.Add(v)
+		}
+		return s
+	}
+
+	for name, l := range map[string][]string{
+		"DefaultGzipContentTypes": DefaultGzipContentTypes,
+		"DefaultGzipExtensions":   DefaultGzipExtensions,
+	} {
+		if len(l) == 0 {
+			t.Fatalf("expected %s not to be empty", name)
+		}
+		if n := toSet(l).Len(); n != len(l) {
+			t.Fatalf("expected %s to hold no duplicates, got %d unique of %d", name, n, len(l))
+		}
+	}
+	for _, e := range DefaultGzipExtensions {
+		if strings.HasPrefix(e, ".") {
+			t.Fatalf("expected extensions without a leading '.', got %q", e)
+		}
+	}
+
+	// The lists expand to the space separated form the API takes
+	opts, err := buildGzip(map[string]interface{}{
+		"name":            "defaults",
+		"content_types":   toSet(DefaultGzipContentTypes),
+		"extensions":      toSet(DefaultGzipExtensions),
+		"cache_condition": "",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for got, expected := range map[string][]string{
+		opts.ContentTypes: DefaultGzipContentTypes,
+		opts.Extensions:   DefaultGzipExtensions,
+	} {
+		fields := strings.Fields(got)
+		sort.Strings(fields)
+		sorted := append([]string{}, expected...)
+		sort.Strings(sorted)
+		if !reflect.DeepEqual(fields, sorted) {
+			t.Fatalf("expected %q to expand to %q, got %q", expected, sorted, fields)
+		}
+	}
+}
+
+func TestGzipHash(t *testing.T) {
+	gzip := func(contentTypes, extensions []interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":            "compress",
+			"content_types":   schema.NewSet(schema.HashString, contentTypes),
+			"extensions":      schema.NewSet(schema.HashString, extensions),
+			"cache_condition": "",
+			"allow_empty":     false,
+		}
+	}
+
+	base := gzipHash(gzip([]interface{}{"text/html", "text/css"}, []interface{}{"css"}))
+
+	if h := gzipHash(gzip([]interface{}{"text/css", "text/html"}, []interface{}{"css"})); h != base {
+		t.Fatalf("expected the order of content_types not to change the hash, got %d and %d", base, h)
+	}
+	if h := gzipHash(gzip([]interface{}{"text/html"}, []interface{}{"css"})); h == base {
+		t.Fatalf("expected different content_types to change the hash, both got %d", h)
+	}
+	if h := gzipHash(gzip([]interface{}{"text/html", "text/css"}, []interface{}{"js"})); h == base {
+		t.Fatalf("expected different extensions to change the hash, both got %d", h)
+	}
+
+	conditioned := gzip([]interface{}{"text/html", "text/css"}, []interface{}{"css"})
+	conditioned["cache_condition"] = "is_static"
+	if h := gzipHash(conditioned); h == base {
+		t.Fatalf("expected a different cache_condition to change the hash, both got %d", h)
+	}
+
+	// Lists, as found in raw configuration, hash like the equivalent sets
+	listed := gzip(nil, nil)
+	listed["content_types"] = []interface{}{"text/css", "text/html"}
+	listed["extensions"] = []interface{}{"css"}
+	if h := gzipHash(listed); h != base {
+		t.Fatalf("expected lists and sets to hash alike, got %d and %d", base, h)
+	}
+}
+
+func TestResourceServiceV1Update_gzipInPlace(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var calls []string
+	var updated url.Values
+	record := testRecordCalls(&calls)
+
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":         testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone": record("clone", testFastlyJSON(`{"number": 2}`)),
+		"PUT /service/test-service/version/2":       testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2/gzip/compress": record("update", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			updated, _ = url.ParseQuery(string(body))
+			testFastlyJSON(`{"name": "compress"}`)(w, r)
+		}),
+		"GET /service/test-service/version/2/validate": record("validate", testFastlyJSON(`{"status": "ok"}`)),
+		"PUT /service/test-service/version/2/activate": record("activate", testFastlyJSON(`{"number": 2, "active": true}`)),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	extensions := []interface{}{
+		"css", "js", "json", "svg", "txt", "xml", "html", "htm", "map", "ico",
+		"eot", "otf", "ttf", "woff", "rss", "atom", "csv", "md", "yaml", "wasm",
+	}
+	r := resourceServiceV1()
+	old := map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"gzip":   []interface{}{map[string]interface{}{"name": "compress", "extensions": extensions}},
+	}
+	od := schema.TestResourceDataRaw(t, r.Schema, old)
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"gzip": []interface{}{map[string]interface{}{
+			"name":       "compress",
+			"extensions": append(append([]interface{}{}, extensions...), "webmanifest"),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := r.Apply(od.State(), diff, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if expected := []string{"clone", "update", "validate", "activate"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+	if got := len(strings.Fields(updated.Get("extensions"))); got != 21 {
+		t.Fatalf("expected the update to send all 21 extensions, got %d: %q", got, updated.Get("extensions"))
+	}
+}
+
 func TestAccFastlyServiceV1_gzips_basic(t *testing.T) {
 	var service gofastly.ServiceDetail
 	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
@@ -9,21 +9,22 @@ import (
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
 )
 
 func TestFastlyServiceV1_BuildHeaders(t *testing.T) {
 	cases := []struct {
-		remote *gofastly.CreateHeaderInput
+		remote *adapter.Header
 		local  map[string]interface{}
 	}{
 		{
-			remote: &gofastly.CreateHeaderInput{
+			remote: &adapter.Header{
 				Name:        "someheadder",
-				Action:      gofastly.HeaderActionDelete,
-				IgnoreIfSet: gofastly.CBool(true),
-				Type:        gofastly.HeaderTypeCache,
+				Action:      "delete",
+				IgnoreIfSet: true,
+				Type:        "cache",
 				Destination: "http.aws-id",
-				Priority:    uint(100),
+				Priority:    100,
 			},
 			local: map[string]interface{}{
 				"name":               "someheadder",
@@ -41,13 +42,13 @@ func TestFastlyServiceV1_BuildHeaders(t *testing.T) {
 			},
 		},
 		{
-			remote: &gofastly.CreateHeaderInput{
+			remote: &adapter.Header{
 				Name:        "someheadder",
-				Action:      gofastly.HeaderActionSet,
-				IgnoreIfSet: gofastly.CBool(false),
-				Type:        gofastly.HeaderTypeCache,
+				Action:      "set",
+				IgnoreIfSet: false,
+				Type:        "cache",
 				Destination: "http.aws-id",
-				Priority:    uint(100),
+				Priority:    100,
 				Source:      "http.server-name",
 			},
 			local: map[string]interface{}{
@@ -75,6 +76,35 @@ func TestFastlyServiceV1_BuildHeaders(t *testing.T) {
 	}
 }
 
+func TestFastlyServiceV1_FlattenHeaders_priority(t *testing.T) {
+	cases := []struct {
+		remote []*adapter.Header
+		local  []int
+	}{
+		{
+			remote: []*adapter.Header{
+				{Name: "explicit", Priority: 10},
+				{Name: "default", Priority: 100},
+				{Name: "unset", Priority: 0},
+			},
+			local: []int{10, 100, 100},
+		},
+	}
+
+	for _, c := range cases {
+		out := flattenHeaders(c.remote)
+		for i, h := range out {
+			priority, ok := h["priority"].(int)
+			if !ok {
+				t.Fatalf("Expected priority of %q to be an int, got %T", h["name"], h["priority"])
+			}
+			if priority != c.local[i] {
+				t.Fatalf("Error matching priority of %q:\nexpected: %d\ngot: %d", h["name"], c.local[i], priority)
+			}
+		}
+	}
+}
+
 func TestAccFastlyServiceV1_headers_basic(t *testing.T) {
 	var service gofastly.ServiceDetail
 	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
@@ -153,6 +183,109 @@ func TestAccFastlyServiceV1_headers_basic(t *testing.T) {
 	})
 }
 
+func TestAccFastlyServiceV1_headers_priority(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	explicit := gofastly.Header{
+		Version:     1,
+		Name:        "explicit priority",
+		Destination: "http.x-amz-request-id",
+		Type:        "cache",
+		Action:      "delete",
+		Priority:    uint(10),
+	}
+
+	defaulted := gofastly.Header{
+		Version:     1,
+		Name:        "default priority",
+		Destination: "http.Server",
+		Type:        "cache",
+		Action:      "delete",
+		Priority:    uint(100),
+	}
+
+	check := resource.ComposeTestCheckFunc(
+		testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+		testAccCheckFastlyServiceV1HeaderAttributes(&service, []*gofastly.Header{&explicit, &defaulted}),
+		resource.TestCheckResourceAttr(
+			"fastly_service_v1.foo", "header.#", "2"),
+	)
+
+	// Apply the same configuration repeatedly; the priorities must read back
+	// unchanged each time so that no cycle produces a diff.
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1HeadersConfig_priority(name, domainName1),
+				Check:  check,
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1HeadersConfig_priority(name, domainName1),
+				Check:  check,
+			},
+
+			resource.TestStep{
+				Config:   testAccServiceV1HeadersConfig_priority(name, domainName1),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// A header and the condition it references are removed in one apply. The
+// header has to be deleted first, or Fastly rejects the condition delete.
+func TestAccFastlyServiceV1_headers_removeWithCondition(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	conditioned := gofastly.Header{
+		Version:          1,
+		Name:             "Add server name",
+		Destination:      "http.server-name",
+		Type:             "request",
+		Action:           "set",
+		Source:           "server.identity",
+		Priority:         uint(100),
+		RequestCondition: "test_req_condition",
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1HeadersConfig_removeWithCondition(name, domainName1, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1HeaderAttributes(&service, []*gofastly.Header{&conditioned}),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "condition.#", "1"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1HeadersConfig_removeWithCondition(name, domainName1, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1HeaderAttributes(&service, []*gofastly.Header{}),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "header.#", "0"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "condition.#", "0"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckFastlyServiceV1HeaderAttributes(service *gofastly.ServiceDetail, headers []*gofastly.Header) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -291,3 +424,77 @@ resource "fastly_service_v1" "foo" {
   force_destroy = true
 }`, name, domain)
 }
+
+func testAccServiceV1HeadersConfig_priority(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  header {
+    destination = "http.x-amz-request-id"
+    type        = "cache"
+    action      = "delete"
+    name        = "explicit priority"
+    priority    = 10
+  }
+
+  header {
+    destination = "http.Server"
+    type        = "cache"
+    action      = "delete"
+    name        = "default priority"
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
+func testAccServiceV1HeadersConfig_removeWithCondition(name, domain string, withHeader bool) string {
+	header := ""
+	if withHeader {
+		header = `
+  condition {
+    name      = "test_req_condition"
+    type      = "REQUEST"
+    priority  = 5
+    statement = "req.url ~ \"^/foo/bar$\""
+  }
+
+  header {
+    destination       = "http.server-name"
+    type              = "request"
+    action            = "set"
+    source            = "server.identity"
+    name              = "Add server name"
+    request_condition = "test_req_condition"
+  }
+`
+	}
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+%s
+  force_destroy = true
+}`, name, domain, header)
+}
@@ -1,12 +1,16 @@
 package fastly
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
 )
@@ -27,7 +31,7 @@ func TestAccFastlyServiceV1_healthcheck_basic(t *testing.T) {
 		Initial:          2,
 		Method:           "HEAD",
 		Threshold:        3,
-		Timeout:          5000,
+		Timeout:          3000,
 		Window:           5,
 	}
 
@@ -78,6 +82,335 @@ func TestAccFastlyServiceV1_healthcheck_basic(t *testing.T) {
 	})
 }
 
+func TestResourceFastlyHealthCheck_comment(t *testing.T) {
+	var stored string
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"PUT /service/test-service/version/1/healthcheck/commented": func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			stored = r.PostForm.Get("comment")
+			testFastlyJSON(`{"name": "commented"}`)(w, r)
+		},
+		"GET /service/test-service/version/1/healthcheck": func(w http.ResponseWriter, r *http.Request) {
+			testFastlyJSON(fmt.Sprintf(`[{"name": "commented", "comment": %q}, {"name": "bare", "comment": ""}]`, stored))(w, r)
+		},
+	})
+	defer closeServer()
+
+	comment := "Origin takes up to 5s to answer under load"
+	if err := updateHealthCheckComment(client.conn, "test-service", 1, "commented", comment); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	extras, err := listHealthCheckExtras(client.conn, "test-service", 1)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	out := flattenHealthchecks([]*gofastly.HealthCheck{{Name: "commented"}, {Name: "bare"}}, extras)
+	if got := out[0]["comment"]; got != comment {
+		t.Fatalf("expected the comment to be read back, got: %#v", got)
+	}
+	if _, ok := out[1]["comment"]; ok {
+		t.Fatalf("expected the empty comment to be pruned, got: %#v", out[1])
+	}
+}
+
+func TestResourceFastlyHealthCheck_headers(t *testing.T) {
+	var stored []string
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"PUT /service/test-service/version/1/healthcheck/probe": func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			stored = r.PostForm["headers[]"]
+			testFastlyJSON(`{"name": "probe"}`)(w, r)
+		},
+		"GET /service/test-service/version/1/healthcheck": func(w http.ResponseWriter, r *http.Request) {
+			headers, _ := json.Marshal(stored)
+			testFastlyJSON(fmt.Sprintf(`[{"name": "probe", "headers": %s}, {"name": "bare", "headers": []}]`, headers))(w, r)
+		},
+	})
+	defer closeServer()
+
+	headers := map[string]interface{}{"Host": "origin.example.com", "X-Probe": "fastly"}
+	if err := updateHealthCheckHeaders(client.conn, "test-service", 1, "probe", healthCheckHeaders(headers)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if want := []string{"Host: origin.example.com", "X-Probe: fastly"}; !reflect.DeepEqual(stored, want) {
+		t.Fatalf("expected headers %q to be sent, got: %q", want, stored)
+	}
+
+	extras, err := listHealthCheckExtras(client.conn, "test-service", 1)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	out := flattenHealthchecks([]*gofastly.HealthCheck{{Name: "probe"}, {Name: "bare"}}, extras)
+	if got := out[0]["headers"]; !reflect.DeepEqual(got, headers) {
+		t.Fatalf("expected the headers to be read back, got: %#v", got)
+	}
+	if _, ok := out[1]["headers"]; ok {
+		t.Fatalf("expected no headers, got: %#v", out[1])
+	}
+}
+
+func TestAccFastlyServiceV1_healthcheck_headers(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_headers(name, domainName, "origin1.example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1HealthCheckHeaders(&service, "example-healthcheck", []string{"Host: origin1.example.com"}),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_headers(name, domainName, "origin2.example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1HealthCheckHeaders(&service, "example-healthcheck", []string{"Host: origin2.example.com"}),
+				),
+			},
+
+			// the headers are read back without a diff
+			resource.TestStep{
+				Config:   testAccServiceV1HealthCheckConfig_headers(name, domainName, "origin2.example.com"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1HealthCheckHeaders(service *gofastly.ServiceDetail, healthcheck string, headers []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		extras, err := listHealthCheckExtras(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Healthcheck headers for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		extra, ok := extras[healthcheck]
+		if !ok {
+			return fmt.Errorf("Healthcheck (%s) not found", healthcheck)
+		}
+		if !reflect.DeepEqual(extra.Headers, headers) {
+			return fmt.Errorf("Bad headers for Healthcheck (%s), expected (%q), got (%q)", healthcheck, headers, extra.Headers)
+		}
+
+		return nil
+	}
+}
+
+func TestValidateHealthcheckTimeouts(t *testing.T) {
+	cases := []struct {
+		checkInterval int
+		timeout       int
+		errors        int
+	}{
+		{checkInterval: 5000, timeout: 500, errors: 0},
+		{checkInterval: 5000, timeout: 4999, errors: 0},
+		{checkInterval: 5000, timeout: 5000, errors: 1},
+		{checkInterval: 4000, timeout: 5000, errors: 1},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"healthcheck": []interface{}{
+				map[string]interface{}{
+					"name":           "example-healthcheck",
+					"host":           "example.com",
+					"path":           "/test.txt",
+					"check_interval": c.checkInterval,
+					"timeout":        c.timeout,
+				},
+			},
+		})
+
+		_, errors := validateHealthcheckTimeouts(d)
+		if len(errors) != c.errors {
+			t.Fatalf("check_interval %d, timeout %d: expected %d errors, got: %q", c.checkInterval, c.timeout, c.errors, errors)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_healthcheck_validation(t *testing.T) {
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config:      testAccServiceV1HealthCheckConfig_validation(name, domainName, 5000, 600, 500),
+				ExpectError: regexp.MustCompile("must be a valid HTTP status code"),
+			},
+			resource.TestStep{
+				Config:      testAccServiceV1HealthCheckConfig_validation(name, domainName, 0, 200, 500),
+				ExpectError: regexp.MustCompile("must be greater than 0"),
+			},
+			resource.TestStep{
+				Config:      testAccServiceV1HealthCheckConfig_validation(name, domainName, 4000, 200, 5000),
+				ExpectError: regexp.MustCompile("timeout \\(5000\\) must be less than check_interval \\(4000\\)"),
+			},
+		},
+	})
+}
+
+func TestSplitBackendHealthCheckToggles(t *testing.T) {
+	backend := func(name, address string, disabled bool) map[string]interface{} {
+		return map[string]interface{}{
+			"name":                 name,
+			"address":              address,
+			"healthcheck":          "example-healthcheck1",
+			"healthcheck_disabled": disabled,
+		}
+	}
+
+	remove := []interface{}{
+		backend("toggled", "a.example.com", false),
+		backend("moved", "b.example.com", false),
+		backend("dropped", "c.example.com", false),
+	}
+	add := []interface{}{
+		backend("toggled", "a.example.com", true),
+		backend("moved", "d.example.com", true),
+		backend("created", "e.example.com", false),
+	}
+
+	toggle, remove, add := splitBackendHealthCheckToggles(remove, add)
+
+	expectedToggle := []map[string]interface{}{backend("toggled", "a.example.com", true)}
+	if !reflect.DeepEqual(toggle, expectedToggle) {
+		t.Fatalf("Error matching toggled:\nexpected: %#v\ngot: %#v", expectedToggle, toggle)
+	}
+
+	expectedRemove := []interface{}{
+		backend("moved", "b.example.com", false),
+		backend("dropped", "c.example.com", false),
+	}
+	if !reflect.DeepEqual(remove, expectedRemove) {
+		t.Fatalf("Error matching removed:\nexpected: %#v\ngot: %#v", expectedRemove, remove)
+	}
+
+	expectedAdd := []interface{}{
+		backend("moved", "d.example.com", true),
+		backend("created", "e.example.com", false),
+	}
+	if !reflect.DeepEqual(add, expectedAdd) {
+		t.Fatalf("Error matching added:\nexpected: %#v\ngot: %#v", expectedAdd, add)
+	}
+}
+
+func TestAccFastlyServiceV1_healthcheck_backendDisabled(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_backendDisabled(name, domainName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1BackendHealthCheck(&service, "amazon docs", "example-healthcheck1"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_backendDisabled(name, domainName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1BackendHealthCheck(&service, "amazon docs", ""),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "backend.#", "1"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_backendDisabled(name, domainName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1BackendHealthCheck(&service, "amazon docs", "example-healthcheck1"),
+				),
+			},
+		},
+	})
+}
+
+// A backend and the healthcheck it references are removed in one apply. The
+// backend has to be deleted first, or Fastly rejects the healthcheck delete.
+func TestAccFastlyServiceV1_healthcheck_removeWithBackend(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_removeWithBackend(name, domainName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1BackendHealthCheck(&service, "amazon docs", "example-healthcheck1"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "backend.#", "2"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "healthcheck.#", "1"),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1HealthCheckConfig_removeWithBackend(name, domainName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1HealthCheckAttributes(&service, []*gofastly.HealthCheck{}),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "backend.#", "1"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "healthcheck.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1BackendHealthCheck(service *gofastly.ServiceDetail, backend, healthcheck string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		b, err := conn.GetBackend(&gofastly.GetBackendInput{
+			Service: service.ID,
+			Version: service.ActiveVersion.Number,
+			Name:    backend,
+		})
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Backend (%s) for (%s), version (%v): %s", backend, service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if b.HealthCheck != healthcheck {
+			return fmt.Errorf("Bad healthcheck for Backend (%s), expected (%q), got (%q)", backend, healthcheck, b.HealthCheck)
+		}
+
+		return nil
+	}
+}
+
 func testAccCheckFastlyServiceV1HealthCheckAttributes(service *gofastly.ServiceDetail, healthchecks []*gofastly.HealthCheck) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -143,7 +476,7 @@ resource "fastly_service_v1" "foo" {
 		initial           = 2
 		method            = "HEAD"
 		threshold         = 3
-		timeout           = 5000
+		timeout           = 3000
 		window            = 5
   }
 
@@ -176,7 +509,7 @@ resource "fastly_service_v1" "foo" {
 		initial           = 2
 		method            = "HEAD"
 		threshold         = 3
-		timeout           = 5000
+		timeout           = 3000
 		window            = 5
   }
 
@@ -197,3 +530,124 @@ resource "fastly_service_v1" "foo" {
   force_destroy = true
 }`, name, domain)
 }
+
+func testAccServiceV1HealthCheckConfig_validation(name, domain string, checkInterval, expectedResponse, timeout int) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  healthcheck {
+    name              = "example-healthcheck1"
+    host              = "example1.com"
+    path              = "/test1.txt"
+    check_interval    = %d
+    expected_response = %d
+    timeout           = %d
+  }
+
+  force_destroy = true
+}`, name, domain, checkInterval, expectedResponse, timeout)
+}
+
+func testAccServiceV1HealthCheckConfig_backendDisabled(name, domain string, disabled bool) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address              = "aws.amazon.com"
+    name                 = "amazon docs"
+    healthcheck          = "example-healthcheck1"
+    healthcheck_disabled = %t
+  }
+
+  healthcheck {
+    name = "example-healthcheck1"
+    host = "example1.com"
+    path = "/test1.txt"
+  }
+
+  force_destroy = true
+}`, name, domain, disabled)
+}
+
+func testAccServiceV1HealthCheckConfig_removeWithBackend(name, domain string, withHealthCheck bool) string {
+	checked := ""
+	if withHealthCheck {
+		checked = `
+  backend {
+    address     = "aws.amazon.com"
+    name        = "amazon docs"
+    healthcheck = "example-healthcheck1"
+  }
+
+  healthcheck {
+    name = "example-healthcheck1"
+    host = "example1.com"
+    path = "/test1.txt"
+  }
+`
+	}
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "docs.aws.amazon.com"
+    name    = "aws docs"
+  }
+%s
+  force_destroy = true
+}`, name, domain, checked)
+}
+
+func testAccServiceV1HealthCheckConfig_headers(name, domain, host string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address     = "aws.amazon.com"
+    name        = "amazon docs"
+    healthcheck = "example-healthcheck"
+  }
+
+  healthcheck {
+    name = "example-healthcheck"
+    host = "example.com"
+    path = "/healthcheck.txt"
+
+    headers {
+      Host = "%s"
+    }
+  }
+
+  force_destroy = true
+}`, name, domain, host)
+}
@@ -0,0 +1,284 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+func TestResourceFastlyFlattenHTTPSLogging(t *testing.T) {
+	cases := []struct {
+		remote []*adapter.HTTPS
+		local  []map[string]interface{}
+	}{
+		{
+			remote: []*adapter.HTTPS{
+				&adapter.HTTPS{
+					Name:              "https collector",
+					URL:               "https://logs.example.com/ingest",
+					RequestMaxEntries: 100,
+					RequestMaxBytes:   0,
+					ContentType:       "application/json",
+					HeaderName:        "Authorization",
+					HeaderValue:       "Bearer token",
+					Method:            "PUT",
+					JSONFormat:        "1",
+					Format:            `{"host": "%h"}`,
+					FormatVersion:     2,
+				},
+			},
+			local: []map[string]interface{}{
+				map[string]interface{}{
+					"name":                "https collector",
+					"url":                 "https://logs.example.com/ingest",
+					"request_max_entries": 100,
+					"request_max_bytes":   0,
+					"content_type":        "application/json",
+					"header_name":         "Authorization",
+					"header_value":        "Bearer token",
+					"method":              "PUT",
+					"json_format":         "1",
+					"format":              `{"host": "%h"}`,
+					"format_version":      2,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out := flattenHTTPSLogging(c.remote)
+		if !reflect.DeepEqual(out, c.local) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.local, out)
+		}
+	}
+}
+
+func TestResourceFastlyServiceV1_httpsloggingBatching(t *testing.T) {
+	cases := []struct {
+		entries, bytes int
+		valid          bool
+	}{
+		{0, 0, true},
+		{500, 1048576, true},
+		{-1, 0, false},
+		{0, -1, false},
+	}
+
+	for _, tc := range cases {
+		endpoint := testHTTPSLoggingRequired()
+		endpoint["request_max_entries"] = tc.entries
+		endpoint["request_max_bytes"] = tc.bytes
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name": "tf-test",
+			"domain": []map[string]interface{}{
+				{"name": "test.notadomain.com"},
+			},
+			"httpslogging": []map[string]interface{}{endpoint},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, errs := resourceServiceV1().Validate(terraform.NewResourceConfig(c))
+		if tc.valid && len(errs) != 0 {
+			t.Fatalf("entries %d, bytes %d should be valid: %q", tc.entries, tc.bytes, errs)
+		}
+		if !tc.valid && len(errs) != 1 {
+			t.Fatalf("entries %d, bytes %d should not be valid, got: %q", tc.entries, tc.bytes, errs)
+		}
+	}
+}
+
+func TestResourceFastlyServiceV1_httpsloggingRequired(t *testing.T) {
+	for field := range testHTTPSLoggingRequired() {
+		endpoint := testHTTPSLoggingRequired()
+		delete(endpoint, field)
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name": "tf-test",
+			"domain": []map[string]interface{}{
+				{"name": "test.notadomain.com"},
+			},
+			"httpslogging": []map[string]interface{}{endpoint},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, errs := resourceServiceV1().Validate(terraform.NewResourceConfig(c))
+		if len(errs) != 1 {
+			t.Fatalf("an endpoint without %s should not be valid, got: %q", field, errs)
+		}
+	}
+}
+
+// testHTTPSLoggingRequired returns an httpslogging block that sets exactly
+// the required arguments.
+func testHTTPSLoggingRequired() map[string]interface{} {
+	return map[string]interface{}{
+		"name":                "https-endpoint",
+		"url":                 "https://logs.example.com/ingest",
+		"request_max_entries": 0,
+		"request_max_bytes":   0,
+		"content_type":        "application/json",
+		"header_name":         "Authorization",
+		"header_value":        "Bearer token",
+		"method":              "POST",
+		"json_format":         "0",
+	}
+}
+
+func TestAccFastlyServiceV1_httpslogging(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	httpsName := fmt.Sprintf("https %s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceV1Config_httpslogging(name, httpsName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_httpslogging(&service, name, httpsName),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "httpslogging.#", "1"),
+				),
+			},
+			{
+				Config: testAccServiceV1Config_httpsloggingBatching(name, httpsName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_httpsloggingBatching(&service, 500, 1048576),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1Attributes_httpslogging(service *gofastly.ServiceDetail, name, httpsName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+
+		if service.Name != name {
+			return fmt.Errorf("Bad name, expected (%s), got (%s)", name, service.Name)
+		}
+
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		httpsList, err := adapter.ListHTTPS(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up HTTPS logging for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if len(httpsList) != 1 {
+			return fmt.Errorf("HTTPS logging missing, expected: 1, got: %d", len(httpsList))
+		}
+
+		if httpsList[0].Name != httpsName {
+			return fmt.Errorf("HTTPS logging name mismatch, expected: %s, got: %#v", httpsName, httpsList[0].Name)
+		}
+
+		if httpsList[0].Method != "PUT" {
+			return fmt.Errorf("HTTPS logging method mismatch, expected: PUT, got: %#v", httpsList[0].Method)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckFastlyServiceV1Attributes_httpsloggingBatching(service *gofastly.ServiceDetail, entries, bytes int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		httpsList, err := adapter.ListHTTPS(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up HTTPS logging for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if len(httpsList) != 1 {
+			return fmt.Errorf("HTTPS logging missing, expected: 1, got: %d", len(httpsList))
+		}
+
+		if httpsList[0].RequestMaxEntries != entries {
+			return fmt.Errorf("HTTPS logging request_max_entries mismatch, expected: %d, got: %d", entries, httpsList[0].RequestMaxEntries)
+		}
+
+		if httpsList[0].RequestMaxBytes != bytes {
+			return fmt.Errorf("HTTPS logging request_max_bytes mismatch, expected: %d, got: %d", bytes, httpsList[0].RequestMaxBytes)
+		}
+
+		return nil
+	}
+}
+
+func testAccServiceV1Config_httpslogging(name, httpsName string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  httpslogging {
+    name                = "%s"
+    url                 = "https://logs.example.com/ingest"
+    request_max_entries = 100
+    request_max_bytes   = 0
+    content_type        = "application/json"
+    header_name         = "Authorization"
+    header_value        = "Bearer token"
+    method              = "PUT"
+    json_format         = "1"
+  }
+
+  force_destroy = true
+}`, name, backendName, httpsName)
+}
+
+func testAccServiceV1Config_httpsloggingBatching(name, httpsName string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  httpslogging {
+    name                = "%s"
+    url                 = "https://logs.example.com/ingest"
+    request_max_entries = 500
+    request_max_bytes   = 1048576
+    content_type        = "application/json"
+    header_name         = "Authorization"
+    header_value        = "Bearer token"
+    method              = "PUT"
+    json_format         = "1"
+  }
+
+  force_destroy = true
+}`, name, backendName, httpsName)
+}
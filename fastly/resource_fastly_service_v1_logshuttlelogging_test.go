@@ -0,0 +1,137 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+func TestResourceFastlyFlattenLogShuttleLogging(t *testing.T) {
+	cases := []struct {
+		remote []*adapter.LogShuttle
+		local  []map[string]interface{}
+	}{
+		{
+			remote: []*adapter.LogShuttle{
+				&adapter.LogShuttle{
+					Name:          "logshuttle collector",
+					URL:           "https://logshuttle.example.com",
+					Token:         "token",
+					Format:        "%h %l %u %t %r %>s",
+					FormatVersion: 2,
+				},
+			},
+			local: []map[string]interface{}{
+				map[string]interface{}{
+					"name":           "logshuttle collector",
+					"url":            "https://logshuttle.example.com",
+					"token":          "token",
+					"format":         "%h %l %u %t %r %>s",
+					"format_version": 2,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out := flattenLogShuttleLogging(c.remote)
+		if !reflect.DeepEqual(out, c.local) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.local, out)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_logshuttlelogging(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	logShuttleName := fmt.Sprintf("logshuttle %s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceV1Config_logshuttlelogging(name, logShuttleName, "https://logshuttle.example.com"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_logshuttlelogging(&service, name, logShuttleName, "https://logshuttle.example.com"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "logshuttlelogging.#", "1"),
+				),
+			},
+
+			{
+				Config: testAccServiceV1Config_logshuttlelogging(name, logShuttleName, "https://logshuttle.example.net"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_logshuttlelogging(&service, name, logShuttleName, "https://logshuttle.example.net"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "logshuttlelogging.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1Attributes_logshuttlelogging(service *gofastly.ServiceDetail, name, logShuttleName, url string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+
+		if service.Name != name {
+			return fmt.Errorf("Bad name, expected (%s), got (%s)", name, service.Name)
+		}
+
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		logShuttleList, err := adapter.ListLogShuttles(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Log Shuttle logging for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if len(logShuttleList) != 1 {
+			return fmt.Errorf("Log Shuttle logging missing, expected: 1, got: %d", len(logShuttleList))
+		}
+
+		if logShuttleList[0].Name != logShuttleName {
+			return fmt.Errorf("Log Shuttle logging name mismatch, expected: %s, got: %#v", logShuttleName, logShuttleList[0].Name)
+		}
+
+		if logShuttleList[0].URL != url {
+			return fmt.Errorf("Log Shuttle logging URL mismatch, expected: %s, got: %#v", url, logShuttleList[0].URL)
+		}
+
+		return nil
+	}
+}
+
+func testAccServiceV1Config_logshuttlelogging(name, logShuttleName, url string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  logshuttlelogging {
+    name  = "%s"
+    url   = "%s"
+    token = "token"
+  }
+
+  force_destroy = true
+}`, name, backendName, logShuttleName, url)
+}
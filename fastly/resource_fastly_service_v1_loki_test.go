@@ -0,0 +1,284 @@
+package fastly
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+func TestResourceFastlyServiceV1_lokiFormatVersion(t *testing.T) {
+	for version, valid := range map[int]bool{1: false, 2: true} {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name": "tf-test",
+			"domain": []map[string]interface{}{
+				{"name": "test.notadomain.com"},
+			},
+			"loki": []map[string]interface{}{
+				{
+					"name":           "loki-endpoint",
+					"url":            "https://logs-prod-us-central1.grafana.net",
+					"tenant_id":      "123456",
+					"auth_token":     "token",
+					"index":          `{env="test"}`,
+					"format_version": version,
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, errs := resourceServiceV1().Validate(terraform.NewResourceConfig(c))
+		if valid && len(errs) != 0 {
+			t.Fatalf("format_version %d should be valid for loki: %q", version, errs)
+		}
+		if !valid && len(errs) != 1 {
+			t.Fatalf("format_version %d should not be valid for loki, got: %q", version, errs)
+		}
+	}
+}
+
+func TestResourceFastlyFlattenLoki(t *testing.T) {
+	cases := []struct {
+		remote []*adapter.Loki
+		local  []map[string]interface{}
+	}{
+		{
+			remote: []*adapter.Loki{
+				&adapter.Loki{
+					Name:          "loki collector",
+					URL:           "https://logs.example.com",
+					AuthToken:     "token",
+					TenantID:      "12345",
+					Index:         `{env="test"}`,
+					Format:        `level=info host=%h status=%>s`,
+					FormatVersion: 2,
+				},
+			},
+			local: []map[string]interface{}{
+				map[string]interface{}{
+					"name":           "loki collector",
+					"url":            "https://logs.example.com",
+					"auth_token":     "token",
+					"tenant_id":      "12345",
+					"index":          `{env="test"}`,
+					"format":         `level=info host=%h status=%>s`,
+					"format_version": 2,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out := flattenLoki(c.remote)
+		if !reflect.DeepEqual(out, c.local) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.local, out)
+		}
+	}
+}
+
+// A loki block without a format reads back the format Fastly defaults to
+// without a diff.
+func TestResourceServiceV1Read_lokiDefaultFormat(t *testing.T) {
+	r := resourceServiceV1()
+	raw := map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"loki": []interface{}{map[string]interface{}{
+			"name":       "loki",
+			"url":        "https://logs.example.com",
+			"auth_token": "token",
+			"index":      `{env="test"}`,
+		}},
+	}
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+	d.SetId("test-service")
+
+	remote := flattenLoki([]*adapter.Loki{
+		&adapter.Loki{
+			Name:          "loki",
+			URL:           "https://logs.example.com",
+			AuthToken:     "token",
+			Index:         `{env="test"}`,
+			Format:        "%h %l %u %t %r %>s",
+			FormatVersion: 2,
+		},
+	})
+	if err := d.Set("loki", remote); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	c, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(d.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil {
+		for k := range diff.Attributes {
+			if strings.HasPrefix(k, "loki") {
+				t.Fatalf("expected no diff for the default format, got: %#v", diff.Attributes)
+			}
+		}
+	}
+}
+
+func TestResourceServiceV1Update_lokiIndex(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var sent url.Values
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":         testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone": testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":       testFastlyJSON(`{"number": 2}`),
+		"POST /service/test-service/version/2/logging/grafanacloudlogs": func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			sent = r.PostForm
+			testFastlyJSON(`{"name": "loki-endpoint"}`)(w, r)
+		},
+		"GET /service/test-service/version/2/validate": testFastlyJSON(`{"status": "ok"}`),
+		"PUT /service/test-service/version/2/activate": testFastlyJSON(`{"number": 2, "active": true}`),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"loki": []interface{}{
+			map[string]interface{}{
+				"name":       "loki-endpoint",
+				"url":        "https://logs.example.com",
+				"auth_token": "token",
+				"tenant_id":  "12345",
+				"index":      `{env="test"}`,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := r.Apply(od.State(), diff, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]string{
+		"name":           "loki-endpoint",
+		"url":            "https://logs.example.com",
+		"token":          "token",
+		"user":           "12345",
+		"index":          `{env="test"}`,
+		"format_version": "2",
+	}
+	for k, v := range expected {
+		if sent.Get(k) != v {
+			t.Errorf("expected %s=%q to be sent to Fastly, got: %v", k, v, sent)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_loki(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	lokiName := fmt.Sprintf("loki %s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceV1Config_loki(name, lokiName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_loki(&service, name, lokiName),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "loki.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1Attributes_loki(service *gofastly.ServiceDetail, name, lokiName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+
+		if service.Name != name {
+			return fmt.Errorf("Bad name, expected (%s), got (%s)", name, service.Name)
+		}
+
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		lokiList, err := adapter.ListLokis(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up Loki for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if len(lokiList) != 1 {
+			return fmt.Errorf("Loki missing, expected: 1, got: %d", len(lokiList))
+		}
+
+		if lokiList[0].Name != lokiName {
+			return fmt.Errorf("Loki name mismatch, expected: %s, got: %#v", lokiName, lokiList[0].Name)
+		}
+
+		return nil
+	}
+}
+
+func testAccServiceV1Config_loki(name, lokiName string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  loki {
+    name       = "%s"
+    url        = "https://logs.example.com"
+    auth_token = "token"
+    tenant_id  = "12345"
+    index      = "{env=\"test\"}"
+    format     = "level=info host=%%h status=%%>s"
+  }
+
+  force_destroy = true
+}`, name, backendName, lokiName)
+}
@@ -0,0 +1,51 @@
+package fastly
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// resourceServiceV1MigrateState upgrades fastly_service_v1 state written by
+// older schema versions. This version of Terraform has no StateUpgraders, so
+// the migration works on the flatmapped attributes.
+func resourceServiceV1MigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found Fastly Service v0 State; migrating to v1")
+		return migrateServiceV1StateV0toV1(is)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateServiceV1StateV0toV1 copies each backend's deprecated ssl_hostname
+// into ssl_cert_hostname and ssl_sni_hostname, where those are not already
+// set, so removing ssl_hostname later does not lose the hostname.
+func migrateServiceV1StateV0toV1(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	// State holds credentials, so only the rewritten attributes are logged.
+	rewritten := make(map[string]string)
+	for k, hostname := range is.Attributes {
+		if !strings.HasPrefix(k, "backend.") || !strings.HasSuffix(k, ".ssl_hostname") || hostname == "" {
+			continue
+		}
+
+		prefix := strings.TrimSuffix(k, "ssl_hostname")
+		for _, field := range []string{"ssl_cert_hostname", "ssl_sni_hostname"} {
+			if is.Attributes[prefix+field] == "" {
+				is.Attributes[prefix+field] = hostname
+				rewritten[prefix+field] = hostname
+			}
+		}
+	}
+
+	log.Printf("[DEBUG] Backend attributes set by migration: %s", redactAttributes(rewritten))
+	return is, nil
+}
@@ -0,0 +1,146 @@
+package fastly
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestResourceServiceV1MigrateState(t *testing.T) {
+	cases := map[string]struct {
+		StateVersion int
+		Attributes   map[string]string
+		Expected     map[string]string
+	}{
+		"v0_1_ssl_hostname": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"backend.#":                      "1",
+				"backend.1234.name":              "origin",
+				"backend.1234.ssl_hostname":      "origin.example.com",
+				"backend.1234.ssl_cert_hostname": "",
+				"backend.1234.ssl_sni_hostname":  "",
+				"domain.#":                       "1",
+				"domain.5678.name":               "example.com",
+				"domain.5678.comment":            "",
+			},
+			Expected: map[string]string{
+				"backend.#":                      "1",
+				"backend.1234.name":              "origin",
+				"backend.1234.ssl_hostname":      "origin.example.com",
+				"backend.1234.ssl_cert_hostname": "origin.example.com",
+				"backend.1234.ssl_sni_hostname":  "origin.example.com",
+				"domain.#":                       "1",
+				"domain.5678.name":               "example.com",
+				"domain.5678.comment":            "",
+			},
+		},
+		"v0_1_keeps_explicit_hostnames": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"backend.#":                      "1",
+				"backend.1234.ssl_hostname":      "origin.example.com",
+				"backend.1234.ssl_cert_hostname": "cert.example.com",
+				"backend.1234.ssl_sni_hostname":  "",
+			},
+			Expected: map[string]string{
+				"backend.#":                      "1",
+				"backend.1234.ssl_hostname":      "origin.example.com",
+				"backend.1234.ssl_cert_hostname": "cert.example.com",
+				"backend.1234.ssl_sni_hostname":  "origin.example.com",
+			},
+		},
+		"v0_1_no_ssl_hostname": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"backend.#":                      "1",
+				"backend.1234.ssl_hostname":      "",
+				"backend.1234.ssl_cert_hostname": "",
+				"backend.1234.ssl_sni_hostname":  "",
+			},
+			Expected: map[string]string{
+				"backend.#":                      "1",
+				"backend.1234.ssl_hostname":      "",
+				"backend.1234.ssl_cert_hostname": "",
+				"backend.1234.ssl_sni_hostname":  "",
+			},
+		},
+	}
+
+	for tn, tc := range cases {
+		is := &terraform.InstanceState{
+			ID:         "test-service",
+			Attributes: tc.Attributes,
+		}
+		is, err := resourceServiceV1MigrateState(tc.StateVersion, is, nil)
+		if err != nil {
+			t.Fatalf("bad: %s, err: %#v", tn, err)
+		}
+
+		if !reflect.DeepEqual(is.Attributes, tc.Expected) {
+			t.Fatalf("bad: %s\nexpected: %#v\ngot: %#v", tn, tc.Expected, is.Attributes)
+		}
+	}
+}
+
+func TestResourceServiceV1MigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceServiceV1MigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	if _, err := resourceServiceV1MigrateState(0, is, nil); err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+}
+
+func TestResourceServiceV1MigrateState_unknownVersion(t *testing.T) {
+	is := &terraform.InstanceState{ID: "test-service"}
+	if _, err := resourceServiceV1MigrateState(5, is, nil); err == nil {
+		t.Fatal("expected an error for an unknown schema version")
+	}
+}
+
+func TestResourceServiceV1MigrateState_logsNoCredentials(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	is := &terraform.InstanceState{
+		ID: "test-service",
+		Attributes: map[string]string{
+			"backend.#":                      "1",
+			"backend.1234.ssl_hostname":      "origin.example.com",
+			"backend.1234.ssl_cert_hostname": "",
+			"backend.1234.ssl_sni_hostname":  "",
+			"backend.1234.ssl_client_key":    "client-key-pem",
+			"s3logging.#":                    "1",
+			"s3logging.5678.s3_secret_key":   "s3-secret",
+		},
+	}
+	if _, err := resourceServiceV1MigrateState(0, is, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !strings.Contains(logs.String(), `"backend.1234.ssl_cert_hostname":"origin.example.com"`) {
+		t.Fatalf("expected the rewritten attributes to be logged, got: %s", logs.String())
+	}
+	for _, secret := range []string{"client-key-pem", "s3-secret"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Fatalf("expected %q not to be logged, got: %s", secret, logs.String())
+		}
+	}
+}
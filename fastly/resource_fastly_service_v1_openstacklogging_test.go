@@ -0,0 +1,154 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+func TestResourceFastlyFlattenOpenstackLogging(t *testing.T) {
+	cases := []struct {
+		remote []*adapter.OpenStack
+		local  []map[string]interface{}
+	}{
+		{
+			remote: []*adapter.OpenStack{
+				&adapter.OpenStack{
+					Name:            "openstack collector",
+					URL:             "https://auth.example.com/v1",
+					User:            "user",
+					BucketName:      "logs",
+					AccessKey:       "secret",
+					Path:            "/fastly/",
+					Period:          3600,
+					GzipLevel:       0,
+					Format:          "%h %l %u %t %r %>s",
+					FormatVersion:   2,
+					MessageType:     "classic",
+					TimestampFormat: "%Y-%m-%dT%H:%M:%S.000",
+				},
+			},
+			local: []map[string]interface{}{
+				map[string]interface{}{
+					"name":             "openstack collector",
+					"url":              "https://auth.example.com/v1",
+					"user":             "user",
+					"bucket_name":      "logs",
+					"access_key":       "secret",
+					"path":             "/fastly/",
+					"period":           3600,
+					"gzip_level":       0,
+					"format":           "%h %l %u %t %r %>s",
+					"format_version":   2,
+					"message_type":     "classic",
+					"timestamp_format": "%Y-%m-%dT%H:%M:%S.000",
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out := flattenOpenstackLogging(c.remote)
+		if !reflect.DeepEqual(out, c.local) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.local, out)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_openstacklogging(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	openstackName := fmt.Sprintf("openstack %s", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServiceV1Config_openstacklogging(name, openstackName, "/fastly/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_openstacklogging(&service, name, openstackName, "/fastly/"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "openstacklogging.#", "1"),
+				),
+			},
+
+			{
+				Config: testAccServiceV1Config_openstacklogging(name, openstackName, "/archive/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_openstacklogging(&service, name, openstackName, "/archive/"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "openstacklogging.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1Attributes_openstacklogging(service *gofastly.ServiceDetail, name, openstackName, path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+
+		if service.Name != name {
+			return fmt.Errorf("Bad name, expected (%s), got (%s)", name, service.Name)
+		}
+
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		openstackList, err := adapter.ListOpenStacks(conn, service.ID, service.ActiveVersion.Number)
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up OpenStack logging for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		if len(openstackList) != 1 {
+			return fmt.Errorf("OpenStack logging missing, expected: 1, got: %d", len(openstackList))
+		}
+
+		if openstackList[0].Name != openstackName {
+			return fmt.Errorf("OpenStack logging name mismatch, expected: %s, got: %#v", openstackName, openstackList[0].Name)
+		}
+
+		if openstackList[0].Path != path {
+			return fmt.Errorf("OpenStack logging path mismatch, expected: %s, got: %#v", path, openstackList[0].Path)
+		}
+
+		return nil
+	}
+}
+
+func testAccServiceV1Config_openstacklogging(name, openstackName, path string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  openstacklogging {
+    name        = "%s"
+    url         = "https://auth.example.com/v1"
+    user        = "user"
+    bucket_name = "logs"
+    access_key  = "secret"
+    path        = "%s"
+  }
+
+  force_destroy = true
+}`, name, backendName, openstackName, path)
+}
@@ -2,11 +2,18 @@ package fastly
 
 import (
 	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
 )
@@ -46,6 +53,483 @@ func TestAccFastlyServiceV1RequestSetting_basic(t *testing.T) {
 	})
 }
 
+func TestAccFastlyServiceV1RequestSetting_geoHeaders(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	rq1 := gofastly.RequestSetting{
+		Name:             "alt_backend",
+		RequestCondition: "serve_alt_backend",
+		DefaultHost:      "tftestingother.tftesting.net.s3-website-us-west-2.amazonaws.com",
+		XForwardedFor:    "append",
+		MaxStaleAge:      uint(90),
+		GeoHeaders:       true,
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1RequestSetting_geoHeaders(name, domainName1, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1RequestSettingsAttributes(&service, []*gofastly.RequestSetting{&rq1}),
+				),
+			},
+			resource.TestStep{
+				Config: testAccServiceV1RequestSetting_geoHeaders(name, domainName1, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					func(s *terraform.State) error {
+						rq1.GeoHeaders = false
+						return testAccCheckFastlyServiceV1RequestSettingsAttributes(&service, []*gofastly.RequestSetting{&rq1})(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+// TestResourceFastlyRequestSetting_geoHeaders checks that geo_headers is sent
+// in the form Fastly expects and survives being read back.
+func TestResourceFastlyRequestSetting_geoHeaders(t *testing.T) {
+	for _, geo := range []bool{true, false} {
+		var sent string
+		client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+			"POST /service/test-service/version/1/request_settings": func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				sent = r.PostForm.Get("geo_headers")
+				testFastlyJSON(fmt.Sprintf(`{"name": "geo", "geo_headers": %q}`, sent))(w, r)
+			},
+		})
+
+		opts, err := buildRequestSetting(map[string]interface{}{
+			"name":              "geo",
+			"max_stale_age":     60,
+			"force_miss":        false,
+			"force_ssl":         false,
+			"action":            "",
+			"bypass_busy_wait":  false,
+			"hash_keys":         "",
+			"xff":               "append",
+			"timer_support":     false,
+			"geo_headers":       geo,
+			"default_host":      "",
+			"request_condition": "",
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		opts.Service = "test-service"
+		opts.Version = 1
+
+		rs, err := client.conn.CreateRequestSetting(opts)
+		closeServer()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		want := "0"
+		if geo {
+			want = "1"
+		}
+		if sent != want {
+			t.Errorf("geo_headers = %t: sent %q, want %q", geo, sent, want)
+		}
+		if got := flattenRequestSettings([]*gofastly.RequestSetting{rs})[0]["geo_headers"]; got != geo {
+			t.Errorf("geo_headers = %t: read back %v", geo, got)
+		}
+	}
+}
+
+func TestValidateDefaultHosts(t *testing.T) {
+	cases := []struct {
+		defaultHost      string
+		settingHost      string
+		requestCondition string
+		warning          string
+	}{
+		{defaultHost: "", settingHost: "b.example.com"},
+		{defaultHost: "a.example.com", settingHost: ""},
+		{defaultHost: "a.example.com", settingHost: "a.example.com"},
+		{
+			defaultHost: "a.example.com",
+			settingHost: "b.example.com",
+			warning:     `request_setting "alt_backend": default_host "b.example.com" overrides the service default_host "a.example.com" for every request`,
+		},
+		{
+			defaultHost:      "a.example.com",
+			settingHost:      "b.example.com",
+			requestCondition: "serve_alt_backend",
+			warning:          `whenever condition "serve_alt_backend" matches`,
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"default_host": c.defaultHost,
+			"request_setting": []interface{}{
+				map[string]interface{}{
+					"name":              "alt_backend",
+					"default_host":      c.settingHost,
+					"request_condition": c.requestCondition,
+				},
+			},
+		})
+
+		ws, es := validateDefaultHosts(d)
+		if len(es) != 0 {
+			t.Fatalf("expected no errors, got: %q", es)
+		}
+		if c.warning == "" {
+			if len(ws) != 0 {
+				t.Fatalf("%#v: expected no warnings, got: %q", c, ws)
+			}
+			continue
+		}
+		if len(ws) != 1 || !strings.Contains(ws[0], c.warning) {
+			t.Fatalf("%#v: expected warning containing %q, got: %q", c, c.warning, ws)
+		}
+	}
+}
+
+func TestValidateRequestSettingActions(t *testing.T) {
+	cases := []struct {
+		action         string
+		forceMiss      bool
+		bypassBusyWait bool
+		warning, error string
+	}{
+		{action: ""},
+		{action: "", forceMiss: true, bypassBusyWait: true},
+		{action: "pass"},
+		{action: "pass", forceMiss: true, error: `request_setting "rs": force_miss cannot be combined with action "pass"`},
+		{action: "pass", bypassBusyWait: true},
+		{action: "lookup"},
+		{action: "lookup", forceMiss: true},
+		{action: "lookup", bypassBusyWait: true, warning: `request_setting "rs": bypass_busy_wait disables request collapsing`},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"request_setting": []interface{}{
+				map[string]interface{}{
+					"name":             "rs",
+					"action":           c.action,
+					"force_miss":       c.forceMiss,
+					"bypass_busy_wait": c.bypassBusyWait,
+				},
+			},
+		})
+
+		ws, es := validateRequestSettingActions(d)
+		if c.error == "" && len(es) != 0 {
+			t.Fatalf("%#v: expected no errors, got: %q", c, es)
+		}
+		if c.error != "" && (len(es) != 1 || !strings.Contains(es[0].Error(), c.error)) {
+			t.Fatalf("%#v: expected an error containing %q, got: %q", c, c.error, es)
+		}
+		if c.warning == "" && len(ws) != 0 {
+			t.Fatalf("%#v: expected no warnings, got: %q", c, ws)
+		}
+		if c.warning != "" && (len(ws) != 1 || !strings.Contains(ws[0], c.warning)) {
+			t.Fatalf("%#v: expected a warning containing %q, got: %q", c, c.warning, ws)
+		}
+	}
+}
+
+func TestValidateForceSSL(t *testing.T) {
+	cases := []struct {
+		domains  []interface{}
+		forceSSL bool
+		warn     bool
+	}{
+		{domains: []interface{}{"www.example.com"}, forceSSL: false},
+		{domains: []interface{}{"www.example.com"}, forceSSL: true, warn: true},
+		{domains: []interface{}{"www.example.com", "example.global.ssl.fastly.net"}, forceSSL: true},
+		{domains: []interface{}{"Example.FreeTLS.Fastly.net"}, forceSSL: true},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"domains": c.domains,
+			"request_setting": []interface{}{
+				map[string]interface{}{
+					"name":      "https",
+					"force_ssl": c.forceSSL,
+				},
+			},
+		})
+
+		ws, es := validateForceSSL(d)
+		if len(es) != 0 {
+			t.Fatalf("expected no errors, got: %q", es)
+		}
+		if !c.warn {
+			if len(ws) != 0 {
+				t.Fatalf("%#v: expected no warnings, got: %q", c, ws)
+			}
+			continue
+		}
+		if len(ws) != 1 || !strings.Contains(ws[0], `request_setting "https"`) || !strings.Contains(ws[0], "www.example.com") {
+			t.Fatalf("%#v: expected a force_ssl warning naming the domains, got: %q", c, ws)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1RequestSetting_forceSSL(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("tf-acc-test-%s.global.ssl.fastly.net", acctest.RandString(10))
+
+	rq1 := gofastly.RequestSetting{
+		Name:          "force_ssl",
+		ForceSSL:      true,
+		XForwardedFor: "append",
+		MaxStaleAge:   uint(60),
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1RequestSetting_forceSSL(name, domainName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1RequestSettingsAttributes(&service, []*gofastly.RequestSetting{&rq1}),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "request_setting.#", "1"),
+				),
+			},
+
+			// Without a TLS domain, force_ssl only warns
+			resource.TestStep{
+				Config: testAccServiceV1RequestSetting_forceSSL(name, fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1RequestSettingsAttributes(&service, []*gofastly.RequestSetting{&rq1}),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateMaxStaleAge(t *testing.T) {
+	for _, v := range []int{0, 60, math.MaxInt32} {
+		_, errors := validateMaxStaleAge(v, "max_stale_age")
+		if len(errors) != 0 {
+			t.Fatalf("%d should be a valid max_stale_age: %q", v, errors)
+		}
+	}
+
+	for _, v := range []int{-1, math.MaxInt32 + 1} {
+		_, errors := validateMaxStaleAge(v, "max_stale_age")
+		if len(errors) != 1 {
+			t.Fatalf("%d should not be a valid max_stale_age", v)
+		}
+	}
+}
+
+func TestSuppressZeroMaxStaleAge(t *testing.T) {
+	cases := []struct {
+		old, new string
+		suppress bool
+	}{
+		{old: "", new: "0", suppress: true},
+		{old: "0", new: "", suppress: true},
+		{old: "0", new: "0", suppress: true},
+		{old: "", new: "60", suppress: false},
+		{old: "60", new: "0", suppress: false},
+		{old: "0", new: "60", suppress: false},
+	}
+
+	for _, c := range cases {
+		if got := suppressZeroMaxStaleAge("request_setting.1.max_stale_age", c.old, c.new, nil); got != c.suppress {
+			t.Fatalf("%q -> %q: expected suppress %t, got %t", c.old, c.new, c.suppress, got)
+		}
+	}
+}
+
+func TestResourceServiceV1Update_requestSettingZeroMaxStaleAge(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var calls []string
+	var updated url.Values
+	record := testRecordCalls(&calls)
+
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":                     testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":             record("clone", testFastlyJSON(`{"number": 2}`)),
+		"PUT /service/test-service/version/2":                   testFastlyJSON(`{"number": 2}`),
+		"POST /service/test-service/version/2/request_settings": record("create", testFastlyJSON(`{"name": "no-stale"}`)),
+		"PUT /service/test-service/version/2/request_settings/no-stale": record("max_stale_age", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			updated, _ = url.ParseQuery(string(body))
+			testFastlyJSON(`{"name": "no-stale"}`)(w, r)
+		}),
+		"GET /service/test-service/version/2/validate": record("validate", testFastlyJSON(`{"status": "ok"}`)),
+		"PUT /service/test-service/version/2/activate": record("activate", testFastlyJSON(`{"number": 2, "active": true}`)),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"request_setting": []interface{}{map[string]interface{}{
+			"name":          "no-stale",
+			"max_stale_age": 0,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := r.Apply(od.State(), diff, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if expected := []string{"clone", "create", "max_stale_age", "validate", "activate"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+	if v, ok := updated["max_stale_age"]; !ok || v[0] != "0" {
+		t.Fatalf("expected max_stale_age 0 to be sent, got: %v", updated)
+	}
+}
+
+func TestAccFastlyServiceV1RequestSetting_zeroMaxStaleAge(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	rq1 := gofastly.RequestSetting{
+		Name:          "no_stale",
+		XForwardedFor: "append",
+		MaxStaleAge:   uint(0),
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1RequestSetting_zeroMaxStaleAge(name, domainName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1RequestSettingsAttributes(&service, []*gofastly.RequestSetting{&rq1}),
+				),
+			},
+
+			// max_stale_age = 0 is read back without a diff
+			resource.TestStep{
+				Config:   testAccServiceV1RequestSetting_zeroMaxStaleAge(name, domainName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestValidateRequestSettingHashKeys(t *testing.T) {
+	cases := []struct {
+		hashKeys     string
+		hashKeysList []interface{}
+		error        bool
+	}{
+		{},
+		{hashKeys: "req.url,req.http.host"},
+		{hashKeysList: []interface{}{"req.url", "req.http.host"}},
+		{hashKeys: "req.url", hashKeysList: []interface{}{"req.http.host"}, error: true},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"request_setting": []interface{}{
+				map[string]interface{}{
+					"name":           "rs",
+					"hash_keys":      c.hashKeys,
+					"hash_keys_list": c.hashKeysList,
+				},
+			},
+		})
+
+		_, es := validateRequestSettingHashKeys(d)
+		if c.error != (len(es) != 0) {
+			t.Fatalf("%#v: expected error %t, got: %q", c, c.error, es)
+		}
+	}
+}
+
+func TestJoinHashKeys(t *testing.T) {
+	for _, keys := range [][]interface{}{
+		{"req.url", "req.http.host"},
+		{"req.http.host", " req.url "},
+	} {
+		if got, want := joinHashKeys(keys), "req.http.host,req.url"; got != want {
+			t.Errorf("joinHashKeys(%q) = %q, want %q", keys, got, want)
+		}
+	}
+
+	if got, want := splitHashKeys("req.http.host, req.url"), []string{"req.http.host", "req.url"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitHashKeys = %q, want %q", got, want)
+	}
+}
+
+func TestAccFastlyServiceV1RequestSetting_hashKeysList(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	rq1 := gofastly.RequestSetting{
+		Name:          "hashed",
+		XForwardedFor: "append",
+		HashKeys:      "req.http.host,req.url",
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1RequestSetting_hashKeysList(name, domainName, `"req.url", "req.http.host"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1RequestSettingsAttributes(&service, []*gofastly.RequestSetting{&rq1}),
+				),
+			},
+
+			// reordering the list is not a change
+			resource.TestStep{
+				Config:   testAccServiceV1RequestSetting_hashKeysList(name, domainName, `"req.http.host", "req.url"`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
 func testAccCheckFastlyServiceV1RequestSettingsAttributes(service *gofastly.ServiceDetail, rqs []*gofastly.RequestSetting) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -127,3 +611,115 @@ resource "fastly_service_v1" "foo" {
   force_destroy = true
 }`, name, domain)
 }
+
+func testAccServiceV1RequestSetting_geoHeaders(name, domain string, geoHeaders bool) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "demo"
+  }
+
+  backend {
+    address = "tftesting.tftesting.net.s3-website-us-west-2.amazonaws.com"
+    name    = "AWS S3 hosting"
+    port    = 80
+  }
+
+  condition {
+    name      = "serve_alt_backend"
+    type      = "REQUEST"
+    priority  = 10
+    statement = "req.url ~ \"^/alt/\""
+  }
+
+  request_setting {
+    default_host      = "tftestingother.tftesting.net.s3-website-us-west-2.amazonaws.com"
+    name              = "alt_backend"
+    request_condition = "serve_alt_backend"
+    max_stale_age     = 90
+    geo_headers       = %t
+  }
+
+  default_host = "tftesting.tftesting.net.s3-website-us-west-2.amazonaws.com"
+
+  force_destroy = true
+}`, name, domain, geoHeaders)
+}
+
+func testAccServiceV1RequestSetting_forceSSL(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "demo"
+  }
+
+  backend {
+    address = "tftesting.tftesting.net.s3-website-us-west-2.amazonaws.com"
+    name    = "AWS S3 hosting"
+    port    = 80
+  }
+
+  request_setting {
+    name      = "force_ssl"
+    force_ssl = true
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
+func testAccServiceV1RequestSetting_zeroMaxStaleAge(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "demo"
+  }
+
+  backend {
+    address = "tftesting.tftesting.net.s3-website-us-west-2.amazonaws.com"
+    name    = "AWS S3 hosting"
+    port    = 80
+  }
+
+  request_setting {
+    name          = "no_stale"
+    max_stale_age = 0
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
+func testAccServiceV1RequestSetting_hashKeysList(name, domain, hashKeys string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "demo"
+  }
+
+  backend {
+    address = "tftesting.tftesting.net.s3-website-us-west-2.amazonaws.com"
+    name    = "AWS S3 hosting"
+    port    = 80
+  }
+
+  request_setting {
+    name           = "hashed"
+    hash_keys_list = [%s]
+  }
+
+  force_destroy = true
+}`, name, domain, hashKeys)
+}
@@ -3,14 +3,76 @@ package fastly
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
 )
 
+func TestResourceFastlyServiceV1_responseObjectStatus(t *testing.T) {
+	for status, valid := range map[int]bool{99: false, 100: true, 503: true, 599: true, 600: false} {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name": "tf-test",
+			"domain": []map[string]interface{}{
+				{"name": "test.notadomain.com"},
+			},
+			"response_object": []map[string]interface{}{
+				{"name": "maintenance", "status": status},
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, errs := resourceServiceV1().Validate(terraform.NewResourceConfig(c))
+		if valid && len(errs) != 0 {
+			t.Fatalf("status %d should be valid: %q", status, errs)
+		}
+		if !valid && len(errs) != 1 {
+			t.Fatalf("status %d should not be valid, got: %q", status, errs)
+		}
+	}
+}
+
+func TestValidateResponseObjectBodies(t *testing.T) {
+	cases := []struct {
+		status   int
+		content  string
+		warnings int
+	}{
+		{200, "<html>OK</html>", 0},
+		{503, "<html>Down</html>", 0},
+		{101, "switching", 1},
+		{204, "nothing", 1},
+		{304, "not modified", 1},
+		{204, "", 0},
+		{304, "", 0},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"response_object": []interface{}{
+				map[string]interface{}{"name": "synthetic", "status": c.status, "content": c.content},
+			},
+		})
+		ws, es := validateResponseObjectBodies(d)
+		if len(es) != 0 {
+			t.Fatalf("status %d: unexpected errors: %q", c.status, es)
+		}
+		if len(ws) != c.warnings {
+			t.Fatalf("status %d with content %q: expected %d warnings, got: %q", c.status, c.content, c.warnings, ws)
+		}
+		if len(ws) > 0 && !strings.Contains(ws[0], `response_object "synthetic"`) {
+			t.Fatalf("expected the warning to name the response object, got: %s", ws[0])
+		}
+	}
+}
+
 func TestAccFastlyServiceV1_response_object_basic(t *testing.T) {
 	var service gofastly.ServiceDetail
 	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
@@ -0,0 +1,368 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+// The tests in this file push a fully populated Fastly object through its
+// flatten function, the resource schema and its build function, and check
+// that every field of the resulting object matches the original. A
+// field the provider forgets to map in either direction fails here rather
+// than being silently dropped on apply.
+
+func TestResourceFastlyRoundTrip(t *testing.T) {
+	cases := []struct {
+		block  string
+		remote interface{}
+		// flatten and build convert between remote and the schema.
+		flatten func(interface{}) []map[string]interface{}
+		build   func(interface{}) (interface{}, error)
+		// unmanaged are create input fields the schema does not expose yet.
+		unmanaged []string
+	}{
+		{
+			block: "condition",
+			remote: &adapter.Condition{
+				Name:      "condition",
+				Statement: `req.url ~ "^/yolo/"`,
+				Type:      "REQUEST",
+				Priority:  10,
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenConditions([]*adapter.Condition{r.(*adapter.Condition)}, nil)
+			},
+			build: func(m interface{}) (interface{}, error) { return buildCondition(m) },
+		},
+		{
+			block: "backend",
+			remote: &adapter.Backend{
+				Name:                "backend",
+				Address:             "www.example.com",
+				Port:                443,
+				ConnectTimeout:      1000,
+				MaxConn:             200,
+				ErrorThreshold:      5,
+				FirstByteTimeout:    15000,
+				BetweenBytesTimeout: 10000,
+				AutoLoadbalance:     true,
+				Weight:              100,
+				RequestCondition:    "condition",
+				HealthCheck:         "healthcheck",
+				Shield:              "sea-wa-us",
+				SSLCheckCert:        true,
+				SSLHostname:         "ssl.example.com",
+				SSLCertHostname:     "cert.example.com",
+				SSLSNIHostname:      "sni.example.com",
+				UseSSL:              true,
+				SSLCACert:           testPEMCertificate(t, "ca.example.com"),
+				SSLClientCert:       "client cert",
+				SSLClientKey:        "client key",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenBackends([]*adapter.Backend{r.(*adapter.Backend)}, nil, nil)
+			},
+			build: func(m interface{}) (interface{}, error) { return buildBackend(m) },
+			unmanaged: []string{
+				"MinTLSVersion",
+				"MaxTLSVersion",
+				"SSLCiphers",
+				// Hostname is reported by Fastly, never sent.
+				"Hostname",
+			},
+		},
+		{
+			block: "surrogate_key",
+			remote: &adapter.Header{
+				Name:           "surrogate_key: products",
+				Action:         "set",
+				Type:           "cache",
+				Destination:    "http.Surrogate-Key",
+				Source:         `"product-" req.url.basename`,
+				Priority:       100,
+				CacheCondition: "cache condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenSurrogateKeys([]*adapter.Header{r.(*adapter.Header)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildSurrogateKeyHeader(m) },
+			// The remaining header fields are fixed for surrogate keys
+			unmanaged: []string{"IgnoreIfSet", "Regex", "Substitution", "RequestCondition", "ResponseCondition"},
+		},
+		{
+			block: "gzip",
+			remote: &gofastly.Gzip{
+				Name:           "gzip",
+				ContentTypes:   "text/html",
+				Extensions:     "css",
+				CacheCondition: "condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenGzips([]*gofastly.Gzip{r.(*gofastly.Gzip)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildGzip(m) },
+		},
+		{
+			block: "header",
+			remote: &adapter.Header{
+				Name:              "header",
+				Action:            "regex",
+				IgnoreIfSet:       true,
+				Type:              "response",
+				Destination:       "http.X-Example",
+				Source:            "req.url",
+				Regex:             "^/(.*)$",
+				Substitution:      "\\1",
+				Priority:          20,
+				RequestCondition:  "request condition",
+				CacheCondition:    "cache condition",
+				ResponseCondition: "response condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenHeaders([]*adapter.Header{r.(*adapter.Header)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildHeader(m) },
+		},
+		{
+			block: "s3logging",
+			remote: &adapter.S3{
+				Name:              "s3",
+				BucketName:        "bucket",
+				Domain:            "s3-us-west-2.amazonaws.com",
+				AccessKey:         "access",
+				SecretKey:         "secret",
+				Path:              "/logs/",
+				Period:            600,
+				GzipLevel:         9,
+				Format:            "%h %l %u %t %r %>s",
+				FormatVersion:     2,
+				ResponseCondition: "condition",
+				TimestampFormat:   "%Y-%m-%dT%H:%M:%S.000",
+				PublicKey:         "key",
+				FileMaxBytes:      1048576,
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenS3s([]*adapter.S3{r.(*adapter.S3)})
+			},
+			build:     func(m interface{}) (interface{}, error) { return buildS3(m) },
+			unmanaged: []string{"Redundancy"},
+		},
+		{
+			block: "papertrail",
+			remote: &adapter.Papertrail{
+				Name:              "papertrail",
+				Address:           "logs.papertrailapp.com",
+				Port:              12345,
+				Format:            "%h %l %u %t %r %>s",
+				ResponseCondition: "condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenPapertrails([]*adapter.Papertrail{r.(*adapter.Papertrail)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildPapertrail(m) },
+		},
+		{
+			block: "sumologic",
+			remote: &adapter.Sumologic{
+				Name:              "sumologic",
+				URL:               "https://collectors.sumologic.com/receiver/v1/http/token",
+				Format:            "%h %l %u %t %r %>s",
+				ResponseCondition: "condition",
+				MessageType:       "blank",
+				FormatVersion:     2,
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenSumologics([]*adapter.Sumologic{r.(*adapter.Sumologic)})
+			},
+			build:     func(m interface{}) (interface{}, error) { return buildSumologic(m) },
+			unmanaged: []string{"Address"},
+		},
+		{
+			block: "gcslogging",
+			remote: &adapter.GCS{
+				Name:              "gcs",
+				Bucket:            "bucket",
+				User:              "user@example.com",
+				SecretKey:         "secret",
+				Path:              "/logs/",
+				Period:            600,
+				GzipLevel:         9,
+				Format:            "%h %l %u %t %r %>s",
+				FormatVersion:     2,
+				ResponseCondition: "condition",
+				TimestampFormat:   "%Y-%m-%dT%H:%M:%S.000",
+				PublicKey:         "key",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenGCS([]*adapter.GCS{r.(*adapter.GCS)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildGCS(m) },
+		},
+		{
+			block: "loki",
+			remote: &adapter.Loki{
+				Name:              "loki",
+				URL:               "https://logs.example.com",
+				AuthToken:         "token",
+				TenantID:          "tenant",
+				Index:             `{env="test"}`,
+				Format:            `{"host": "%h"}`,
+				FormatVersion:     2,
+				ResponseCondition: "condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenLoki([]*adapter.Loki{r.(*adapter.Loki)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildLoki(m) },
+		},
+		{
+			block: "httpslogging",
+			remote: &adapter.HTTPS{
+				Name:              "https",
+				URL:               "https://logs.example.com/ingest",
+				RequestMaxEntries: 100,
+				RequestMaxBytes:   1000,
+				ContentType:       "application/json",
+				HeaderName:        "Authorization",
+				HeaderValue:       "Bearer token",
+				Method:            "PUT",
+				JSONFormat:        "1",
+				TLSCACert:         "ca",
+				TLSClientCert:     "cert",
+				TLSClientKey:      "key",
+				TLSHostname:       "logs.example.com",
+				Format:            `{"host": "%h"}`,
+				FormatVersion:     2,
+				ResponseCondition: "condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenHTTPSLogging([]*adapter.HTTPS{r.(*adapter.HTTPS)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildHTTPSLogging(m) },
+		},
+		{
+			block: "openstacklogging",
+			remote: &adapter.OpenStack{
+				Name:              "openstack",
+				URL:               "https://auth.example.com/v1",
+				User:              "user",
+				BucketName:        "logs",
+				AccessKey:         "access",
+				Path:              "/logs/",
+				Period:            600,
+				GzipLevel:         9,
+				Format:            "%h %l %u %t %r %>s",
+				FormatVersion:     2,
+				ResponseCondition: "condition",
+				MessageType:       "blank",
+				TimestampFormat:   "%Y-%m-%dT%H:%M:%S.000",
+				PublicKey:         "key",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenOpenstackLogging([]*adapter.OpenStack{r.(*adapter.OpenStack)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildOpenstackLogging(m) },
+		},
+		{
+			block: "logshuttlelogging",
+			remote: &adapter.LogShuttle{
+				Name:              "logshuttle",
+				URL:               "https://logshuttle.example.com",
+				Token:             "token",
+				Format:            "%h %l %u %t %r %>s",
+				FormatVersion:     2,
+				ResponseCondition: "condition",
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenLogShuttleLogging([]*adapter.LogShuttle{r.(*adapter.LogShuttle)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildLogShuttleLogging(m) },
+		},
+		{
+			block: "pool",
+			remote: &pool{
+				ID:               "pool-id",
+				Name:             "pool",
+				Comment:          "origins",
+				Type:             "hash",
+				Healthcheck:      "healthcheck",
+				MaxConnDefault:   100,
+				ConnectTimeout:   500,
+				FirstByteTimeout: 10000,
+				Quorum:           50,
+			},
+			flatten: func(r interface{}) []map[string]interface{} {
+				return flattenPools([]*pool{r.(*pool)})
+			},
+			build: func(m interface{}) (interface{}, error) { return buildPool(m.(map[string]interface{})), nil },
+			// The ID is assigned by Fastly, never sent.
+			unmanaged: []string{"ID"},
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{})
+		if err := d.Set(c.block, c.flatten(c.remote)); err != nil {
+			t.Fatalf("%s: error setting state: %s", c.block, err)
+		}
+		elems := d.Get(c.block).(*schema.Set).List()
+		if len(elems) != 1 {
+			t.Fatalf("%s: expected 1 element in state, got %d", c.block, len(elems))
+		}
+
+		input, err := c.build(elems[0])
+		if err != nil {
+			t.Fatalf("%s: error building input: %s", c.block, err)
+		}
+
+		for _, err := range compareRoundTrip(c.remote, input, c.unmanaged) {
+			t.Errorf("%s: %s", c.block, err)
+		}
+	}
+}
+
+// compareRoundTrip checks every field of the built object against the
+// same-named field of the remote object. The remote object must set each of
+// those fields, so that a dropped field cannot hide behind a zero value.
+func compareRoundTrip(remote, input interface{}, unmanaged []string) []error {
+	skip := map[string]bool{"Service": true, "Version": true}
+	for _, f := range unmanaged {
+		skip[f] = true
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(remote))
+	iv := reflect.Indirect(reflect.ValueOf(input))
+
+	var errs []error
+	for i := 0; i < iv.NumField(); i++ {
+		name := iv.Type().Field(i).Name
+		if skip[name] {
+			continue
+		}
+
+		rf := rv.FieldByName(name)
+		if !rf.IsValid() {
+			errs = append(errs, fmt.Errorf("input field %s has no counterpart on %s", name, rv.Type()))
+			continue
+		}
+		if reflect.DeepEqual(rf.Interface(), reflect.Zero(rf.Type()).Interface()) {
+			errs = append(errs, fmt.Errorf("test object leaves %s unset", name))
+			continue
+		}
+
+		got := iv.Field(i).Interface()
+		if cb, ok := got.(*gofastly.Compatibool); ok && cb != nil {
+			got = bool(*cb)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(rf.Interface()) {
+			errs = append(errs, fmt.Errorf("%s did not survive the round trip: sent %v, expected %v", name, got, rf.Interface()))
+		}
+	}
+
+	return errs
+}
@@ -3,22 +3,25 @@ package fastly
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
 )
 
 func TestResourceFastlyFlattenSumologic(t *testing.T) {
 	cases := []struct {
-		remote []*gofastly.Sumologic
+		remote []*adapter.Sumologic
 		local  []map[string]interface{}
 	}{
 		{
-			remote: []*gofastly.Sumologic{
-				&gofastly.Sumologic{
+			remote: []*adapter.Sumologic{
+				&adapter.Sumologic{
 					Name:              "sumo collector",
 					URL:               "https://sumologic.com/collector/1",
 					Format:            "log format",
@@ -69,6 +72,127 @@ func TestAccFastlyServiceV1_sumologic(t *testing.T) {
 	})
 }
 
+func TestAccFastlyServiceV1_sumologicBlank(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	sumologicName := fmt.Sprintf("sumologic %s", acctest.RandString(3))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1Config_sumologicBlank(name, sumologicName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_sumologic(&service, name, sumologicName),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "sumologic.#", "1"),
+					testAccCheckFastlyServiceV1SumologicAttr("fastly_service_v1.foo", "message_type", "blank"),
+					testAccCheckFastlyServiceV1SumologicAttr("fastly_service_v1.foo", "format", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateSumologicRegions(t *testing.T) {
+	cases := []struct {
+		url, region string
+		warnings    []string
+	}{
+		{url: "https://endpoint1.collection.us2.sumologic.com/receiver/v1/http/token"},
+		{url: "https://endpoint1.collection.us2.sumologic.com/receiver/v1/http/token", region: "US2"},
+		{url: "https://endpoint1.collection.sumologic.com/receiver/v1/http/token", region: "US1"},
+		// Not a known collector, so nothing to check against
+		{url: "https://sumologic.example.com/receiver", region: "EU"},
+		{
+			url:      "https://endpoint1.collection.sumologic.com/receiver/v1/http/token",
+			region:   "EU",
+			warnings: []string{`sumologic "logs": region is EU, but url posts to the US1 deployment`},
+		},
+		{
+			url:      "https://endpoint3.collection.au.sumologic.com/receiver/v1/http/token",
+			region:   "US2",
+			warnings: []string{`sumologic "logs": region is US2, but url posts to the AU deployment`},
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"sumologic": []interface{}{map[string]interface{}{
+				"name":   "logs",
+				"url":    c.url,
+				"region": c.region,
+			}},
+		})
+
+		ws, es := validateSumologicRegions(d)
+		if len(es) != 0 {
+			t.Fatalf("%s in %s: unexpected errors: %q", c.url, c.region, es)
+		}
+		if !reflect.DeepEqual(ws, c.warnings) {
+			t.Errorf("%s in %s: expected warnings %q, got: %q", c.url, c.region, c.warnings, ws)
+		}
+		for _, w := range ws {
+			if strings.Contains(w, "token") {
+				t.Errorf("warning should not include the url: %s", w)
+			}
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_sumologicRegion(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	sumologicName := fmt.Sprintf("sumologic %s", acctest.RandString(3))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1Config_sumologicRegion(name, sumologicName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1Attributes_sumologic(&service, name, sumologicName),
+					testAccCheckFastlyServiceV1SumologicAttr("fastly_service_v1.foo", "region", "US2"),
+				),
+			},
+
+			// region is kept across refreshes, although Fastly does not store it
+			resource.TestStep{
+				Config:   testAccServiceV1Config_sumologicRegion(name, sumologicName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// testAccCheckFastlyServiceV1SumologicAttr checks an attribute of the single
+// sumologic block in state, whose set hash is not known in advance.
+func testAccCheckFastlyServiceV1SumologicAttr(n, key, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		for k, v := range rs.Primary.Attributes {
+			if strings.HasPrefix(k, "sumologic.") && strings.HasSuffix(k, "."+key) && strings.Count(k, ".") == 2 {
+				if v != value {
+					return fmt.Errorf("Sumologic %s mismatch, expected: %q, got: %q", key, value, v)
+				}
+				return nil
+			}
+		}
+
+		return fmt.Errorf("Sumologic %s not found in state", key)
+	}
+}
+
 func testAccCheckFastlyServiceV1Attributes_sumologic(service *gofastly.ServiceDetail, name, sumologic string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -124,3 +248,58 @@ resource "fastly_service_v1" "foo" {
   force_destroy = true
 }`, name, backendName, sumologic)
 }
+
+func testAccServiceV1Config_sumologicBlank(name, sumologic string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  sumologic {
+    name         = "%s"
+    url          = "https://sumologic.com/collector/1"
+    message_type = "blank"
+    format       = ""
+  }
+
+  force_destroy = true
+}`, name, backendName, sumologic)
+}
+
+func testAccServiceV1Config_sumologicRegion(name, sumologic string) string {
+	backendName := fmt.Sprintf("%s.aws.amazon.com", acctest.RandString(3))
+
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "test.notadomain.com"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "%s"
+    name    = "tf -test backend"
+  }
+
+  sumologic {
+    name   = "%s"
+    url    = "https://endpoint1.collection.us2.sumologic.com/receiver/v1/http/1"
+    region = "US2"
+  }
+
+  force_destroy = true
+}`, name, backendName, sumologic)
+}
@@ -0,0 +1,135 @@
+package fastly
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	gofastly "github.com/sethvargo/go-fastly"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+func TestBuildSurrogateKeyHeader(t *testing.T) {
+	out, _ := buildSurrogateKeyHeader(map[string]interface{}{
+		"name":            "products",
+		"key_template":    `"product-" req.url.basename`,
+		"cache_condition": "is product",
+	})
+
+	expected := &adapter.Header{
+		Name:           "surrogate_key: products",
+		Action:         "set",
+		Type:           "cache",
+		Destination:    "http.Surrogate-Key",
+		Source:         `"product-" req.url.basename`,
+		Priority:       100,
+		CacheCondition: "is product",
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", expected, out)
+	}
+}
+
+func TestSplitSurrogateKeyHeaders(t *testing.T) {
+	remote := []*adapter.Header{
+		{Name: "remove x-amz-request-id", Destination: "http.x-amz-request-id"},
+		{Name: "surrogate_key: products", Destination: "http.Surrogate-Key", Source: `"product-" req.url.basename`},
+		// Hand-built Surrogate-Key headers stay in the header block
+		{Name: "keys", Destination: "http.Surrogate-Key", Source: `"all"`},
+	}
+
+	headers, surrogateKeys := splitSurrogateKeyHeaders(remote)
+	if len(headers) != 2 || headers[0].Name != "remove x-amz-request-id" || headers[1].Name != "keys" {
+		t.Fatalf("unexpected headers: %#v", headers)
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"name":            "products",
+			"key_template":    `"product-" req.url.basename`,
+			"cache_condition": "",
+		},
+	}
+	if out := flattenSurrogateKeys(surrogateKeys); !reflect.DeepEqual(out, expected) {
+		t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", expected, out)
+	}
+}
+
+func TestAccFastlyServiceV1_surrogateKey(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	header := gofastly.Header{
+		Name:        "surrogate_key: products",
+		Destination: "http.Surrogate-Key",
+		Type:        "cache",
+		Action:      "set",
+		Source:      `"product-" req.url.basename`,
+		Priority:    uint(100),
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1SurrogateKeyConfig(name, domainName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1HeaderAttributes(&service, []*gofastly.Header{&header}),
+					testAccCheckFastlyServiceV1PurgeKey(&service, "product-widget"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "surrogate_key.#", "1"),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "header.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFastlyServiceV1PurgeKey(service *gofastly.ServiceDetail, key string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		purge, err := conn.PurgeKey(&gofastly.PurgeKeyInput{
+			Service: service.ID,
+			Key:     key,
+		})
+		if err != nil {
+			return fmt.Errorf("[ERR] Error purging key (%s) on (%s): %s", key, service.Name, err)
+		}
+		if purge.Status != "ok" {
+			return fmt.Errorf("Purging key (%s) returned status %q", key, purge.Status)
+		}
+		return nil
+	}
+}
+
+func testAccServiceV1SurrogateKeyConfig(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  surrogate_key {
+    name         = "products"
+    key_template = "\"product-\" req.url.basename"
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
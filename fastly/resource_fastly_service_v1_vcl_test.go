@@ -1,11 +1,18 @@
 package fastly
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	gofastly "github.com/sethvargo/go-fastly"
 )
@@ -47,6 +54,608 @@ func TestAccFastlyServiceV1_VCL_basic(t *testing.T) {
 	})
 }
 
+// A version Fastly fails to validate is never activated, and active_version
+// stays at the version that was active before the update.
+func TestResourceServiceV1Update_invalidVCL(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var activated bool
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":                 testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":         testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":               testFastlyJSON(`{"number": 2}`),
+		"DELETE /service/test-service/version/2/vcl/main":   testFastlyJSON(`{"status": "ok"}`),
+		"POST /service/test-service/version/2/vcl":          testFastlyJSON(`{"name": "main"}`),
+		"PUT /service/test-service/version/2/vcl/main/main": testFastlyJSON(`{"name": "main", "main": true}`),
+		"GET /service/test-service/version/2/validate": testFastlyJSON(
+			`{"status": "error", "msg": "Syntax error: Unexpected 'INVALID_TOKEN'"}`),
+		"PUT /service/test-service/version/2/activate": func(w http.ResponseWriter, r *http.Request) {
+			activated = true
+			testFastlyJSON(`{"number": 2, "active": true}`)(w, r)
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	domain := []interface{}{map[string]interface{}{"name": "example.com"}}
+	vcl := func(content string) []interface{} {
+		return []interface{}{map[string]interface{}{"name": "main", "content": content, "main": true}}
+	}
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": domain,
+		"vcl":    vcl("sub vcl_recv {\n#FASTLY recv\n}"),
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": domain,
+		"vcl":    vcl("sub vcl_recv { INVALID_TOKEN; }"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := r.Apply(od.State(), diff, client)
+	if err == nil || !strings.Contains(err.Error(), "Syntax error: Unexpected 'INVALID_TOKEN'") {
+		t.Fatalf("expected the Fastly validation message, got: %v", err)
+	}
+	if activated {
+		t.Fatal("expected the invalid version not to be activated")
+	}
+	if v := state.Attributes["active_version"]; v != "1" {
+		t.Fatalf("expected active_version to stay 1, got: %s", v)
+	}
+}
+
+func TestResourceServiceV1Update_activationError(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var attempts int
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":            testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":    testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":          testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2/settings": testFastlyJSON(`{"general.default_ttl": 60}`),
+		"GET /service/test-service/version/2/validate": testFastlyJSON(`{"status": "ok"}`),
+		"PUT /service/test-service/version/2/activate": func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"msg": "Bad request", "detail": "Backend is unreachable"}`))
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	domain := []interface{}{map[string]interface{}{"name": "example.com"}}
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": domain,
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":        "test",
+		"domain":      domain,
+		"default_ttl": 60,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := r.Apply(od.State(), diff, client)
+	if err == nil {
+		t.Fatal("expected an activation error")
+	}
+	for _, part := range []string{"Fastly Service (test-service), Version (2)", "Backend is unreachable", "Version 2 was built and validated but is left inactive"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Fatalf("expected the error to contain %q, got: %s", part, err)
+		}
+	}
+	if v := state.Attributes["active_version"]; v != "1" {
+		t.Fatalf("expected active_version to stay 1, got: %s", v)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a rejected activation not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestResourceServiceV1Update_activationNotReady(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+	defer testSetDelay(&versionNotReadyDelay, 0)()
+
+	var attempts int
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":            testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":    testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2":          testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2/settings": testFastlyJSON(`{"general.default_ttl": 60}`),
+		"GET /service/test-service/version/2/validate": testFastlyJSON(`{"status": "ok"}`),
+		"PUT /service/test-service/version/2/activate": func(w http.ResponseWriter, r *http.Request) {
+			if attempts++; attempts <= 2 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"msg": "Conflict", "detail": "Version is still being processed"}`))
+				return
+			}
+			testFastlyJSON(`{"number": 2, "active": true}`)(w, r)
+		},
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	r := resourceServiceV1()
+	domain := []interface{}{map[string]interface{}{"name": "example.com"}}
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": domain,
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":        "test",
+		"domain":      domain,
+		"default_ttl": 60,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := r.Apply(od.State(), diff, client)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 activation attempts, got %d", attempts)
+	}
+	if v := state.Attributes["active_version"]; v != "2" {
+		t.Fatalf("expected active_version 2, got: %s", v)
+	}
+}
+
+func TestRetryVersionNotReady(t *testing.T) {
+	defer testSetDelay(&versionNotReadyDelay, 0)()
+
+	cases := []struct {
+		err      error
+		attempts int
+	}{
+		{err: nil, attempts: 1},
+		{err: &gofastly.HTTPError{StatusCode: http.StatusBadRequest}, attempts: 1},
+		{err: &gofastly.HTTPError{StatusCode: http.StatusUnauthorized}, attempts: 1},
+		{err: errors.New("connection refused"), attempts: 1},
+		{err: &gofastly.HTTPError{StatusCode: http.StatusNotFound}, attempts: versionNotReadyAttempts},
+		{err: &gofastly.HTTPError{StatusCode: http.StatusConflict}, attempts: versionNotReadyAttempts},
+		{err: &gofastly.HTTPError{StatusCode: http.StatusServiceUnavailable}, attempts: versionNotReadyAttempts},
+	}
+
+	for _, c := range cases {
+		var attempts int
+		err := retryVersionNotReady("testing", func() error {
+			attempts++
+			return c.err
+		})
+		if err != c.err {
+			t.Fatalf("expected the last error to be returned, got: %v", err)
+		}
+		if attempts != c.attempts {
+			t.Fatalf("%v: expected %d attempts, got %d", c.err, c.attempts, attempts)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_invalidVCL(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1VCLConfig(name, domainName1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+				),
+			},
+
+			resource.TestStep{
+				Config:      testAccServiceV1VCLConfig_invalid(name, domainName1),
+				ExpectError: regexp.MustCompile(`Invalid configuration for Fastly Service \(.+\): .+`),
+			},
+
+			// The failed update must leave the valid version active. The refresh
+			// at the start of this step reads active_version back from Fastly.
+			resource.TestStep{
+				PreConfig: func() {
+					conn := testAccProvider.Meta().(*FastlyClient).conn
+					s, err := conn.GetServiceDetails(&gofastly.GetServiceInput{ID: service.ID})
+					if err != nil {
+						t.Fatalf("[ERR] Error looking up service (%s): %s", service.ID, err)
+					}
+					if s.ActiveVersion.Number != service.ActiveVersion.Number {
+						t.Fatalf("expected version %d to stay active after the invalid VCL, got %d",
+							service.ActiveVersion.Number, s.ActiveVersion.Number)
+					}
+				},
+				Config: testAccServiceV1VCLConfig(name, domainName1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1VCLAttributes(&service, name, 1),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceServiceV1Read_generatedVCL(t *testing.T) {
+	for _, include := range []bool{false, true} {
+		routes := map[string]func(w http.ResponseWriter, r *http.Request){
+			"GET /service":                      testFastlyJSON(`[{"id": "test-service"}]`),
+			"GET /service/test-service/details": testFastlyJSON(`{"id": "test-service", "active_version": {"number": 3}}`),
+			// Stop once the generated VCL would have been read
+			"GET /service/test-service/version/3/settings": func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			},
+		}
+		// Without include_generated_vcl the mock server fails the test on a
+		// request for the generated VCL
+		if include {
+			routes["GET /service/test-service/version/3/generated_vcl"] = testFastlyJSON(`{"name": "generated", "content": "sub vcl_recv { }"}`)
+		}
+		client, closeServer := testFastlyServer(t, routes)
+
+		d := schema.TestResourceDataRaw(t, resourceServiceV1().Schema, map[string]interface{}{
+			"name":                  "test",
+			"include_generated_vcl": include,
+		})
+		d.SetId("test-service")
+		err := resourceServiceV1Read(d, client)
+		closeServer()
+		if err == nil || !strings.Contains(err.Error(), "settings") {
+			t.Fatalf("expected the read to stop at the settings, got: %v", err)
+		}
+
+		expected := ""
+		if include {
+			expected = "sub vcl_recv { }"
+		}
+		if got := d.Get("generated_vcl").(string); got != expected {
+			t.Fatalf("include_generated_vcl %t: expected generated_vcl %q, got %q", include, expected, got)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_VCL_generated(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1VCLConfig_generated(name, domainName1, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "generated_vcl", ""),
+				),
+			},
+
+			resource.TestStep{
+				Config: testAccServiceV1VCLConfig_generated(name, domainName1, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					resource.TestMatchResourceAttr(
+						"fastly_service_v1.foo", "generated_vcl", regexp.MustCompile("sub vcl_recv")),
+				),
+			},
+		},
+	})
+}
+
+func TestVCLMainFlip(t *testing.T) {
+	vcl := func(name, content string, main bool) map[string]interface{} {
+		return map[string]interface{}{"name": name, "content": content, "main": main}
+	}
+
+	cases := []struct {
+		remove, add []interface{}
+		main        string
+		flip        bool
+	}{
+		// main moves from a to b, with state holding content hashes
+		{
+			remove: []interface{}{vcl("a", hashVCLContent("sub a {}"), true), vcl("b", hashVCLContent("sub b {}"), false)},
+			add:    []interface{}{vcl("a", "sub a {}", false), vcl("b", "sub b {}", true)},
+			main:   "b",
+			flip:   true,
+		},
+		// state refreshed from Fastly holds the content itself
+		{
+			remove: []interface{}{vcl("a", "sub a {}", true), vcl("b", "sub b {}", false)},
+			add:    []interface{}{vcl("a", "sub a {}", false), vcl("b", "sub b {}", true)},
+			main:   "b",
+			flip:   true,
+		},
+		// content changed as well
+		{
+			remove: []interface{}{vcl("a", "sub a {}", true), vcl("b", "sub b {}", false)},
+			add:    []interface{}{vcl("a", "sub a {}", false), vcl("b", "sub b { }", true)},
+		},
+		// a VCL renamed
+		{
+			remove: []interface{}{vcl("a", "sub a {}", true), vcl("b", "sub b {}", false)},
+			add:    []interface{}{vcl("a", "sub a {}", false), vcl("c", "sub b {}", true)},
+		},
+		// no main any more
+		{
+			remove: []interface{}{vcl("a", "sub a {}", true)},
+			add:    []interface{}{vcl("a", "sub a {}", false)},
+		},
+		// a VCL added
+		{
+			add: []interface{}{vcl("a", "sub a {}", true)},
+		},
+	}
+
+	for i, c := range cases {
+		main, flip := vclMainFlip(c.remove, c.add)
+		if main != c.main || flip != c.flip {
+			t.Fatalf("case %d: expected (%q, %t), got (%q, %t)", i, c.main, c.flip, main, flip)
+		}
+	}
+}
+
+func TestResourceServiceV1Update_vclMainFlip(t *testing.T) {
+	defer testSetDelay(&versionAvailableDelay, 0)()
+
+	var calls []string
+	record := testRecordCalls(&calls)
+
+	// Any DeleteVCL or CreateVCL call is an unexpected request, which fails
+	// the test
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/details":              testFastlyJSON(`{"id": "test-service", "active_version": {"number": 1}}`),
+		"PUT /service/test-service/version/1/clone":      record("clone", testFastlyJSON(`{"number": 2}`)),
+		"PUT /service/test-service/version/2":            testFastlyJSON(`{"number": 2}`),
+		"PUT /service/test-service/version/2/vcl/b/main": record("activate vcl b", testFastlyJSON(`{"name": "b", "main": true}`)),
+		"GET /service/test-service/version/2/validate":   record("validate", testFastlyJSON(`{"status": "ok"}`)),
+		"PUT /service/test-service/version/2/activate":   record("activate", testFastlyJSON(`{"number": 2, "active": true}`)),
+		// Stop at the refresh after activation
+		"GET /service": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		},
+	})
+	defer closeServer()
+
+	vcls := func(main string) []interface{} {
+		return []interface{}{
+			map[string]interface{}{"name": "a", "content": "sub vcl_recv { set req.http.X-VCL = \"a\"; }", "main": main == "a"},
+			map[string]interface{}{"name": "b", "content": "sub vcl_recv { set req.http.X-VCL = \"b\"; }", "main": main == "b"},
+		}
+	}
+
+	r := resourceServiceV1()
+	od := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"vcl":    vcls("a"),
+	})
+	od.SetId("test-service")
+	od.Set("active_version", 1)
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":   "test",
+		"domain": []interface{}{map[string]interface{}{"name": "example.com"}},
+		"vcl":    vcls("b"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff, err := r.Diff(od.State(), terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := r.Apply(od.State(), diff, client); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if expected := []string{"clone", "activate vcl b", "validate", "activate"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %q, got: %q", expected, calls)
+	}
+}
+
+func TestFillVCLContent(t *testing.T) {
+	var fetched []string
+	client, closeServer := testFastlyServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /service/test-service/version/2/vcl/main": func(w http.ResponseWriter, r *http.Request) {
+			fetched = append(fetched, "main")
+			testFastlyJSON(`{"name": "main", "main": true, "content": "sub vcl_recv {\n#FASTLY recv\n}"}`)(w, r)
+		},
+	})
+	defer closeServer()
+
+	vclList := []*gofastly.VCL{
+		{Name: "main", Main: true},
+		{Name: "library", Content: "sub library {}"},
+	}
+	if err := fillVCLContent(client.conn, "test-service", 2, vclList); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(fetched, []string{"main"}) {
+		t.Fatalf("expected only the VCL listed without content to be fetched, got: %q", fetched)
+	}
+	if vclList[0].Content != "sub vcl_recv {\n#FASTLY recv\n}" || !vclList[0].Main {
+		t.Fatalf("expected the fetched VCL to replace the listed one, got: %#v", vclList[0])
+	}
+	if vclList[1].Content != "sub library {}" {
+		t.Fatalf("expected the listed content to be kept, got: %#v", vclList[1])
+	}
+
+	vl := flattenVCLs(vclList)
+	if vl[0]["content"] != vclList[0].Content {
+		t.Fatalf("expected the fetched content in state, got: %#v", vl[0])
+	}
+}
+
+func TestSortVCLsForUpload(t *testing.T) {
+	vcl := func(name string, main bool) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"content": "",
+			"main":    main,
+		}
+	}
+
+	cases := []struct {
+		in       []interface{}
+		expected []interface{}
+	}{
+		{
+			in:       []interface{}{vcl("main", true), vcl("b", false), vcl("a", false)},
+			expected: []interface{}{vcl("a", false), vcl("b", false), vcl("main", true)},
+		},
+		{
+			in:       []interface{}{vcl("c", false), vcl("a", false)},
+			expected: []interface{}{vcl("a", false), vcl("c", false)},
+		},
+		{
+			in:       []interface{}{},
+			expected: []interface{}{},
+		},
+	}
+
+	for _, c := range cases {
+		out := sortVCLsForUpload(c.in)
+		if !reflect.DeepEqual(out, c.expected) {
+			t.Fatalf("Error matching:\nexpected: %#v\ngot: %#v", c.expected, out)
+		}
+	}
+}
+
+func TestAccFastlyServiceV1_VCL_contentRoundTrip(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1VCLConfig_includes(name, domainName1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1VCLContent("fastly_service_v1.foo", &service),
+				),
+			},
+			// Refreshing must not turn the content into a diff
+			resource.TestStep{
+				Config:   testAccServiceV1VCLConfig_includes(name, domainName1),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// testAccCheckFastlyServiceV1VCLContent checks that the content hash of each
+// VCL in state matches the full content Fastly holds for it.
+func testAccCheckFastlyServiceV1VCLContent(n string, service *gofastly.ServiceDetail) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		hashes := make(map[string]string)
+		for k, v := range rs.Primary.Attributes {
+			if strings.HasPrefix(k, "vcl.") && strings.HasSuffix(k, ".content") {
+				hashes[v] = k
+			}
+		}
+
+		conn := testAccProvider.Meta().(*FastlyClient).conn
+		vclList, err := conn.ListVCLs(&gofastly.ListVCLsInput{
+			Service: service.ID,
+			Version: service.ActiveVersion.Number,
+		})
+		if err != nil {
+			return fmt.Errorf("[ERR] Error looking up VCL for (%s), version (%v): %s", service.Name, service.ActiveVersion.Number, err)
+		}
+
+		for _, vcl := range vclList {
+			full, err := conn.GetVCL(&gofastly.GetVCLInput{
+				Service: service.ID,
+				Version: service.ActiveVersion.Number,
+				Name:    vcl.Name,
+			})
+			if err != nil {
+				return fmt.Errorf("[ERR] Error fetching VCL (%s): %s", vcl.Name, err)
+			}
+			if full.Content == "" {
+				return fmt.Errorf("VCL (%s) has no content", vcl.Name)
+			}
+			if _, ok := hashes[hashVCLContent(full.Content)]; !ok {
+				return fmt.Errorf("VCL (%s) content hash %s not found in state: %v", vcl.Name, hashVCLContent(full.Content), hashes)
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestAccFastlyServiceV1_VCL_includes(t *testing.T) {
+	var service gofastly.ServiceDetail
+	name := fmt.Sprintf("tf-test-%s", acctest.RandString(10))
+	domainName1 := fmt.Sprintf("%s.notadomain.com", acctest.RandString(10))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckServiceV1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccServiceV1VCLConfig_includes(name, domainName1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckServiceV1Exists("fastly_service_v1.foo", &service),
+					testAccCheckFastlyServiceV1VCLAttributes(&service, name, 3),
+					resource.TestCheckResourceAttr(
+						"fastly_service_v1.foo", "vcl.#", "3"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckFastlyServiceV1VCLAttributes(service *gofastly.ServiceDetail, name string, vclCount int) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 
@@ -107,6 +716,26 @@ EOF
 }`, name, domain)
 }
 
+func testAccServiceV1VCLConfig_invalid(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  vcl {
+    name    = "my_custom_main_vcl"
+    content = "sub vcl_recv { INVALID_TOKEN; }"
+    main    = true
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
 func testAccServiceV1VCLConfig_update(name, domain string) string {
 	return fmt.Sprintf(`
 resource "fastly_service_v1" "foo" {
@@ -155,3 +784,71 @@ EOF
   force_destroy = true
 }`, name, domain)
 }
+
+func testAccServiceV1VCLConfig_includes(name, domain string) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name = "%s"
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  vcl {
+    name    = "my_custom_main_vcl"
+    content = <<EOF
+include "backends";
+include "errors";
+
+sub vcl_recv {
+#FASTLY recv
+
+    return(lookup);
+}
+EOF
+    main    = true
+  }
+
+  vcl {
+    name    = "backends"
+    content = <<EOF
+backend amazondocs {
+  .host = "127.0.0.1";
+  .port = "80";
+}
+EOF
+  }
+
+  vcl {
+    name    = "errors"
+    content = <<EOF
+sub vcl_error {
+#FASTLY error
+}
+EOF
+  }
+
+  force_destroy = true
+}`, name, domain)
+}
+
+func testAccServiceV1VCLConfig_generated(name, domain string, include bool) string {
+	return fmt.Sprintf(`
+resource "fastly_service_v1" "foo" {
+  name                  = "%s"
+  include_generated_vcl = %t
+
+  domain {
+    name    = "%s"
+    comment = "tf-testing-domain"
+  }
+
+  backend {
+    address = "aws.amazon.com"
+    name    = "amazon docs"
+  }
+
+  force_destroy = true
+}`, name, include, domain)
+}
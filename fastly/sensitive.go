@@ -0,0 +1,92 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// walkSchema calls f for every attribute of s, including the attributes of
+// nested blocks, with its dotted path below prefix.
+func walkSchema(s map[string]*schema.Schema, prefix string, f func(path string, attr *schema.Schema)) {
+	for name, attr := range s {
+		path := prefix + name
+		f(path, attr)
+		if r, ok := attr.Elem.(*schema.Resource); ok {
+			walkSchema(r.Schema, path+".", f)
+		}
+	}
+}
+
+var (
+	serviceV1SensitiveOnce   sync.Once
+	serviceV1SensitiveFields map[string][]string
+	serviceV1SensitivePaths  map[string]bool
+)
+
+// loadServiceV1Sensitive derives the Sensitive attributes of
+// fastly_service_v1 from its schema.
+func loadServiceV1Sensitive() {
+	serviceV1SensitiveOnce.Do(func() {
+		serviceV1SensitiveFields = make(map[string][]string)
+		serviceV1SensitivePaths = make(map[string]bool)
+		walkSchema(resourceServiceV1().Schema, "", func(path string, attr *schema.Schema) {
+			if !attr.Sensitive {
+				return
+			}
+			serviceV1SensitivePaths[path] = true
+			if parts := strings.Split(path, "."); len(parts) == 2 {
+				serviceV1SensitiveFields[parts[0]] = append(serviceV1SensitiveFields[parts[0]], parts[1])
+			}
+		})
+		for _, fields := range serviceV1SensitiveFields {
+			sort.Strings(fields)
+		}
+	})
+}
+
+// sensitiveFields returns the Sensitive attributes of each fastly_service_v1
+// block, keyed by block name. It is derived from the schema once, so redacted
+// debug output always follows the Sensitive marking.
+func sensitiveFields(block string) []string {
+	loadServiceV1Sensitive()
+	return serviceV1SensitiveFields[block]
+}
+
+// redactSensitive formats opts, the API input built from an element of block,
+// for debug logging with the values of the element's Sensitive attributes
+// masked.
+func redactSensitive(block string, element map[string]interface{}, opts interface{}) string {
+	out := fmt.Sprintf("%#v", opts)
+	for _, field := range sensitiveFields(block) {
+		if v, ok := element[field].(string); ok && v != "" {
+			out = strings.Replace(out, fmt.Sprintf("%#v", v), `"<sensitive>"`, -1)
+		}
+	}
+	return out
+}
+
+// redactAttributes formats flatmapped fastly_service_v1 state attributes,
+// such as "backend.1234.ssl_client_key", for debug logging with the values of
+// Sensitive attributes masked.
+func redactAttributes(attrs map[string]string) string {
+	loadServiceV1Sensitive()
+	redacted := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		// Drop the set hashes and list indexes to get the schema path.
+		var path []string
+		for _, part := range strings.Split(k, ".") {
+			if strings.Trim(part, "0123456789") != "" {
+				path = append(path, part)
+			}
+		}
+		if serviceV1SensitivePaths[strings.Join(path, ".")] && v != "" {
+			v = "<sensitive>"
+		}
+		redacted[k] = v
+	}
+	return fmt.Sprintf("%#v", redacted)
+}
@@ -0,0 +1,238 @@
+package fastly
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-fastly/fastly/internal/adapter"
+)
+
+// credentialAttribute matches attribute names that usually hold credentials.
+var credentialAttribute = regexp.MustCompile(`key|token|secret|password|cert.*key`)
+
+// notCredentials lists attributes matching credentialAttribute that hold no
+// secret, with the reason.
+var notCredentials = map[string]string{
+	"fastly_service_v1.surrogate_key.key_template":     "a VCL expression",
+	"fastly_service_v1.request_setting.hash_keys":      "a VCL expression",
+	"fastly_service_v1.request_setting.hash_keys_list": "VCL expressions",
+	"fastly_service_v1.s3logging.public_key":           "a PGP public key",
+	"fastly_service_v1.gcslogging.public_key":          "a PGP public key",
+	"fastly_service_v1.openstacklogging.public_key":    "a PGP public key",
+}
+
+// Every attribute that looks like it holds a credential must be Sensitive, so
+// it is hidden from plan output and redacted from debug logs, unless it is
+// listed in notCredentials.
+func TestSchemaCredentialsSensitive(t *testing.T) {
+	p := Provider().(*provider).Provider
+	schemas := map[string]map[string]*schema.Schema{"provider": p.Schema}
+	for name, r := range p.ResourcesMap {
+		schemas[name] = r.Schema
+	}
+	for name, r := range p.DataSourcesMap {
+		schemas["data."+name] = r.Schema
+	}
+
+	for name, s := range schemas {
+		walkSchema(s, name+".", func(path string, attr *schema.Schema) {
+			if _, ok := attr.Elem.(*schema.Resource); ok {
+				return
+			}
+			parts := strings.Split(path, ".")
+			if !credentialAttribute.MatchString(parts[len(parts)-1]) {
+				return
+			}
+			if _, ok := notCredentials[path]; ok {
+				if attr.Sensitive {
+					t.Errorf("%s is Sensitive, remove it from notCredentials", path)
+				}
+				return
+			}
+			if !attr.Sensitive {
+				t.Errorf("%s looks like a credential but is not Sensitive; mark it or add it to notCredentials", path)
+			}
+		})
+	}
+}
+
+func TestSensitiveFields(t *testing.T) {
+	cases := map[string][]string{
+		"s3logging":    {"s3_access_key", "s3_secret_key"},
+		"httpslogging": {"header_value", "tls_client_key"},
+		"backend":      {"ssl_client_key"},
+		"papertrail":   nil,
+	}
+
+	for block, expected := range cases {
+		got := sensitiveFields(block)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("%s: expected Sensitive fields %q, got: %q", block, expected, got)
+		}
+	}
+}
+
+func TestRedactSensitive(t *testing.T) {
+	element := map[string]interface{}{
+		"name":       "loki",
+		"url":        "https://logs.example.com",
+		"auth_token": "s3cr3t\ntoken",
+	}
+	opts := &adapter.Loki{
+		Name:      "loki",
+		URL:       "https://logs.example.com",
+		AuthToken: "s3cr3t\ntoken",
+	}
+
+	out := redactSensitive("loki", element, opts)
+	if strings.Contains(out, "s3cr3t") {
+		t.Fatalf("expected the token to be redacted, got: %s", out)
+	}
+	if !strings.Contains(out, `AuthToken:"<sensitive>"`) || !strings.Contains(out, `URL:"https://logs.example.com"`) {
+		t.Fatalf("expected only the token to be redacted, got: %s", out)
+	}
+}
+
+func TestRedactAttributes(t *testing.T) {
+	out := redactAttributes(map[string]string{
+		"name":                           "test",
+		"backend.1234.ssl_cert_hostname": "origin.example.com",
+		"backend.1234.ssl_client_key":    "client-key-pem",
+		"s3logging.5678.s3_secret_key":   "s3-secret",
+		"s3logging.5678.s3_access_key":   "",
+	})
+
+	for _, secret := range []string{"client-key-pem", "s3-secret"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("expected %q to be redacted, got: %s", secret, out)
+		}
+	}
+	for _, kept := range []string{`"backend.1234.ssl_cert_hostname":"origin.example.com"`, `"s3logging.5678.s3_access_key":""`} {
+		if !strings.Contains(out, kept) {
+			t.Fatalf("expected %s to be kept, got: %s", kept, out)
+		}
+	}
+}
+
+// TestLogsNoRawDumps fails on log calls that format state attributes, schema
+// elements or decoded API objects directly. Those hold credentials, so they
+// must be formatted with redactSensitive or redactAttributes instead.
+func TestLogsNoRawDumps(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			// Variables holding maps, which is how state attributes, schema
+			// elements and decoded API objects are passed around.
+			maps := make(map[string]bool)
+			isMap := func(e ast.Expr) bool {
+				switch e := e.(type) {
+				case *ast.MapType:
+					return true
+				case *ast.TypeAssertExpr:
+					_, ok := e.Type.(*ast.MapType)
+					return ok
+				case *ast.CompositeLit:
+					_, ok := e.Type.(*ast.MapType)
+					return ok
+				case *ast.CallExpr:
+					if id, ok := e.Fun.(*ast.Ident); ok && id.Name == "make" && len(e.Args) > 0 {
+						_, ok := e.Args[0].(*ast.MapType)
+						return ok
+					}
+				}
+				return false
+			}
+			for _, field := range fn.Type.Params.List {
+				if isMap(field.Type) {
+					for _, name := range field.Names {
+						maps[name.Name] = true
+					}
+				}
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ValueSpec:
+					if n.Type != nil && isMap(n.Type) {
+						for _, name := range n.Names {
+							maps[name.Name] = true
+						}
+					}
+				case *ast.AssignStmt:
+					if len(n.Lhs) == len(n.Rhs) {
+						for i, lhs := range n.Lhs {
+							if id, ok := lhs.(*ast.Ident); ok && isMap(n.Rhs[i]) {
+								maps[id.Name] = true
+							}
+						}
+					}
+				case *ast.CallExpr:
+					if len(n.Args) == 2 {
+						if id, ok := n.Fun.(*ast.Ident); ok && id.Name == "decodeFastlyJSON" {
+							if u, ok := n.Args[0].(*ast.UnaryExpr); ok {
+								if x, ok := u.X.(*ast.Ident); ok {
+									maps[x.Name] = true
+								}
+							}
+						}
+					}
+				}
+				return true
+			})
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "log" {
+					return true
+				}
+				for _, arg := range call.Args {
+					raw := false
+					switch arg := arg.(type) {
+					case *ast.Ident:
+						raw = maps[arg.Name]
+					case *ast.SelectorExpr:
+						raw = arg.Sel.Name == "Attributes"
+					}
+					if raw {
+						t.Errorf("%s: %s logs a map directly; format it with redactSensitive or redactAttributes",
+							fset.Position(arg.Pos()), fn.Name.Name)
+					}
+				}
+				return true
+			})
+		}
+	}
+}
@@ -1,6 +1,18 @@
 package fastly
 
-import "fmt"
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 func validateLoggingFormatVersion(v interface{}, k string) (ws []string, errors []error) {
 	value := uint(v.(int))
@@ -16,6 +28,36 @@ func validateLoggingFormatVersion(v interface{}, k string) (ws []string, errors
 	return
 }
 
+// loggingFormatVersions lists the format versions a logging block accepts when
+// the endpoint supports fewer than validateLoggingFormatVersion allows.
+var loggingFormatVersions = map[string][]int{
+	"loki": {2},
+}
+
+// validateLoggingFormatVersionFor returns a format_version validator for the
+// named logging block, so unsupported versions fail at plan time rather than
+// on activation.
+func validateLoggingFormatVersionFor(block string) func(interface{}, string) ([]string, []error) {
+	versions, ok := loggingFormatVersions[block]
+	if !ok {
+		return validateLoggingFormatVersion
+	}
+
+	return func(v interface{}, k string) (ws []string, errors []error) {
+		value := v.(int)
+		var valid []string
+		for _, version := range versions {
+			if value == version {
+				return
+			}
+			valid = append(valid, fmt.Sprintf("'%d'", version))
+		}
+		errors = append(errors, fmt.Errorf(
+			"%q must be one of [%s] for %s endpoints, got: %d", k, strings.Join(valid, ", "), block, value))
+		return
+	}
+}
+
 func validateLoggingMessageType(v interface{}, k string) (ws []string, errors []error) {
 	value := v.(string)
 	validTypes := map[string]struct{}{
@@ -31,3 +73,459 @@ func validateLoggingMessageType(v interface{}, k string) (ws []string, errors []
 	}
 	return
 }
+
+func validateHealthcheckHTTPVersion(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	validVersions := map[string]struct{}{
+		"1.0": {},
+		"1.1": {},
+	}
+
+	if _, ok := validVersions[value]; !ok {
+		errors = append(errors, fmt.Errorf(
+			"%q must be one of ['1.0', '1.1']", k))
+	}
+	return
+}
+
+func validateHealthcheckMethod(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	validMethods := map[string]struct{}{
+		"HEAD": {},
+		"GET":  {},
+		"POST": {},
+	}
+
+	if _, ok := validMethods[value]; !ok {
+		errors = append(errors, fmt.Errorf(
+			"%q must be one of ['HEAD', 'GET', 'POST']", k))
+	}
+	return
+}
+
+func validateHTTPStatusCode(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < 100 || value > 599 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a valid HTTP status code between 100 and 599, got: %d", k, value))
+	}
+	return
+}
+
+func validatePositiveInt(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value <= 0 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be greater than 0, got: %d", k, value))
+	}
+	return
+}
+
+func validateRFC3339Timestamp(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		errors = append(errors, fmt.Errorf(
+			"%q must be an RFC 3339 timestamp, such as 2017-01-02T15:04:05Z: %s", k, err))
+	}
+	return
+}
+
+func validateNonNegativeInt(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < 0 {
+		errors = append(errors, fmt.Errorf(
+			"%q must not be negative, got: %d", k, value))
+	}
+	return
+}
+
+// validateMaxStaleAge checks that max_stale_age fits the 32-bit field Fastly
+// stores it in.
+func validateMaxStaleAge(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < 0 || value > math.MaxInt32 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be between 0 and %d seconds, got: %d", k, math.MaxInt32, value))
+	}
+	return
+}
+
+func validateHTTPSURL(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		errors = append(errors, fmt.Errorf(
+			"%q must be an https:// URL, got: %q", k, value))
+	}
+	return
+}
+
+func validateHTTPSLoggingMethod(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	validMethods := map[string]struct{}{
+		"POST": {},
+		"PUT":  {},
+	}
+
+	if _, ok := validMethods[value]; !ok {
+		errors = append(errors, fmt.Errorf(
+			"%q must be one of ['POST', 'PUT']", k))
+	}
+	return
+}
+
+func validateHTTPSLoggingJSONFormat(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	validFormats := map[string]struct{}{
+		"0": {},
+		"1": {},
+		"2": {},
+	}
+
+	if _, ok := validFormats[value]; !ok {
+		errors = append(errors, fmt.Errorf(
+			"%q must be one of ['0', '1', '2']", k))
+	}
+	return
+}
+
+// sumologicRegions are the Sumo Logic deployment codes, mapped to the segment
+// their collector hostnames carry, such as endpoint1.collection.us2.sumologic.com.
+// US1, the original deployment, has none.
+var sumologicRegions = map[string]string{
+	"US1": "",
+	"US2": "us2",
+	"EU":  "eu",
+	"AU":  "au",
+	"CA":  "ca",
+	"JP":  "jp",
+	"IN":  "in",
+	"DE":  "de",
+	"FED": "fed",
+}
+
+func validateSumologicRegion(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if _, ok := sumologicRegions[value]; !ok {
+		var regions []string
+		for r := range sumologicRegions {
+			regions = append(regions, r)
+		}
+		sort.Strings(regions)
+		errors = append(errors, fmt.Errorf(
+			"%q must be a Sumo Logic deployment, one of ['%s']", k, strings.Join(regions, "', '")))
+	}
+	return
+}
+
+// sumologicCollectorHost matches the hostname of a Sumo Logic HTTP source,
+// capturing its deployment segment.
+var sumologicCollectorHost = regexp.MustCompile(`^(?:[a-z0-9-]+\.)?collection\.(?:([a-z0-9]+)\.)?sumologic\.com$`)
+
+// sumologicURLRegion returns the deployment a Sumo Logic URL posts to. It
+// returns false for URLs that are not on a known Sumo Logic collector.
+func sumologicURLRegion(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	m := sumologicCollectorHost.FindStringSubmatch(strings.ToLower(u.Hostname()))
+	if m == nil {
+		return "", false
+	}
+	for region, segment := range sumologicRegions {
+		if segment == m[1] {
+			return region, true
+		}
+	}
+	return "", false
+}
+
+// strftimeConversions are the conversion characters understood by strftime,
+// including the common GNU and BSD extensions.
+const strftimeConversions = "aAbBcCdDeFgGhHIjklmMnpPrRsStTuUvVwWxXyYzZ+%"
+
+// validateTimestampFormat warns about strftime tokens in a timestamp_format
+// that are not known conversions. Unknown tokens are copied into the log line
+// as is, which is rarely intended, but custom tokens may exist, so they are
+// not an error.
+func validateTimestampFormat(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	var unknown []string
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			continue
+		}
+		// Skip flags, a field width and the E and O modifiers.
+		j := i + 1
+		for j < len(value) && strings.IndexByte("-_0^#", value[j]) >= 0 {
+			j++
+		}
+		for j < len(value) && value[j] >= '0' && value[j] <= '9' {
+			j++
+		}
+		if j < len(value) && (value[j] == 'E' || value[j] == 'O') {
+			j++
+		}
+		if j == len(value) {
+			unknown = append(unknown, value[i:])
+			break
+		}
+		if strings.IndexByte(strftimeConversions, value[j]) < 0 {
+			unknown = append(unknown, value[i:j+1])
+		}
+		i = j
+	}
+
+	if len(unknown) > 0 {
+		ws = append(ws, fmt.Sprintf(
+			"%q contains unknown strftime tokens %q, they will be written to the logs as is", k, unknown))
+	}
+	return
+}
+
+// domainLabel matches a single hostname label.
+var domainLabel = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?$`)
+
+// validateDomainName accepts a hostname, optionally prefixed by a single "*."
+// wildcard label, and rejects values that are clearly not a domain, such as
+// URLs or names with paths, ports or spaces.
+func validateDomainName(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	host := strings.TrimPrefix(value, "*.")
+	if strings.ContainsAny(value, ":/ ") || len(host) > 253 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a hostname such as www.example.com or *.example.com, got: %q", k, value))
+		return
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if !domainLabel.MatchString(label) {
+			errors = append(errors, fmt.Errorf(
+				"%q must be a hostname such as www.example.com or *.example.com, got: %q", k, value))
+			return
+		}
+	}
+	return
+}
+
+// validateFileMaxBytes checks that a log file size limit is between 1MB and
+// 1GB.
+func validateFileMaxBytes(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < 1048576 || value > 1073741824 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be between 1048576 (1MB) and 1073741824 (1GB) bytes, got: %d", k, value))
+	}
+	return
+}
+
+// validateS3Domain accepts an empty domain, for AWS S3, or the hostname of an
+// S3-compatible endpoint with an optional port. Fastly implies the scheme, so
+// URLs are rejected.
+func validateS3Domain(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a hostname without a scheme, such as s3.us-west-2.amazonaws.com, got: %q", k, value))
+		return
+	}
+
+	host := value
+	if i := strings.LastIndex(value, ":"); i != -1 {
+		port, err := strconv.Atoi(value[i+1:])
+		if err != nil || port < 1 || port > 65535 {
+			errors = append(errors, fmt.Errorf(
+				"%q must be a hostname such as s3.us-west-2.amazonaws.com, with an optional port, got: %q", k, value))
+			return
+		}
+		host = value[:i]
+	}
+
+	if _, es := validateDomainName(host, k); len(es) > 0 || strings.HasPrefix(host, "*.") {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a hostname such as s3.us-west-2.amazonaws.com, with an optional port, got: %q", k, value))
+	}
+	return
+}
+
+var (
+	awsAccessKeyID = regexp.MustCompile(`^AKIA[0-9A-Z]{16}$`)
+	// oracleAccessKey matches the access key of an Oracle Cloud customer
+	// secret key, which Oracle's S3-compatible API accepts.
+	oracleAccessKey = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// validateS3AccessKey checks that an access key is an AWS access key ID, or
+// the access key of an Oracle Cloud customer secret key. It may be empty, to
+// use the provider's default. The value is sensitive, so it is not included in
+// the error.
+func validateS3AccessKey(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value != "" && !awsAccessKeyID.MatchString(value) && !oracleAccessKey.MatchString(value) {
+		errors = append(errors, fmt.Errorf(
+			"%q must be an AWS access key ID, AKIA followed by 16 uppercase letters or digits, or an Oracle Cloud access key of 40 lowercase hex digits", k))
+	}
+	return
+}
+
+// validateS3SecretKey checks that a secret key has the 40 characters of an AWS
+// secret access key or the 44 of an Oracle Cloud customer secret key. It may
+// be empty, to use the provider's default.
+func validateS3SecretKey(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value != "" && len(value) != 40 && len(value) != 44 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be an AWS secret access key of 40 characters or an Oracle Cloud secret key of 44 characters, got %d characters", k, len(value)))
+	}
+	return
+}
+
+var (
+	// vclVariable matches a VCL variable such as req.url or
+	// beresp.http.X-Custom. Header names may contain hyphens.
+	vclVariable = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z0-9_-]+)*$`)
+	// vclString matches a VCL string literal, either "short" or {"long"}.
+	vclString = regexp.MustCompile(`^("[^"]*"|\{"(?s:.*)"\})$`)
+)
+
+// validateHeaderSource warns when a header source is neither a VCL variable
+// nor a string literal. Sources may also be expressions, such as string
+// concatenation or function calls, which only Fastly can check, so this is not
+// an error. An empty source is left for delete actions.
+func validateHeaderSource(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" || vclVariable.MatchString(value) || vclString.MatchString(value) {
+		return
+	}
+	ws = append(ws, fmt.Sprintf(
+		"%q is neither a VCL variable, such as req.url, nor a quoted string, got: %s; Fastly will reject it on activation unless it is a valid VCL expression", k, value))
+	return
+}
+
+// validateSSLHostname warns at plan time that ssl_hostname is deprecated. It
+// replaces a Deprecated annotation, which also warned when the field was
+// explicitly set to "".
+func validateSSLHostname(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) != "" {
+		ws = append(ws, fmt.Sprintf(
+			"%q is deprecated by Fastly, set ssl_cert_hostname and ssl_sni_hostname instead", k))
+	}
+	return
+}
+
+func validatePEMCertificates(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+
+	if _, err := parsePEMCertificates(value); err != nil {
+		errors = append(errors, fmt.Errorf(
+			"%q must be one or more PEM encoded certificates: %s", k, err))
+	}
+	return
+}
+
+func validatePEMPrivateKey(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+
+	block, rest := pem.Decode([]byte(value))
+	if block == nil || len(strings.TrimSpace(string(rest))) != 0 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a single PEM encoded private key", k))
+		return
+	}
+
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return
+	}
+	if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return
+	}
+	if _, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return
+	}
+	errors = append(errors, fmt.Errorf(
+		"%q must be a PKCS #1, PKCS #8 or EC private key, got a %s block that does not parse", k, block.Type))
+	return
+}
+
+const (
+	pgpPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	pgpPublicKeyFooter = "-----END PGP PUBLIC KEY BLOCK-----"
+)
+
+// validatePGPPublicKey checks that a value is an ASCII-armored PGP public key
+// block, so a key pasted in the wrong format fails at plan time rather than
+// when Fastly first tries to encrypt a log file.
+func validatePGPPublicKey(v interface{}, k string) (ws []string, errors []error) {
+	value := strings.TrimSpace(v.(string))
+	if value == "" {
+		return
+	}
+
+	lines := strings.Split(strings.Replace(value, "\r\n", "\n", -1), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[0]) != pgpPublicKeyHeader || strings.TrimSpace(lines[len(lines)-1]) != pgpPublicKeyFooter {
+		errors = append(errors, fmt.Errorf(
+			"%q must be an ASCII-armored PGP public key, starting with %s and ending with %s", k, pgpPublicKeyHeader, pgpPublicKeyFooter))
+		return
+	}
+
+	// Skip armor headers such as "Version: ...", which end at the first blank
+	// line, and the "=XXXX" checksum that follows the body.
+	body := lines[1 : len(lines)-1]
+	for i, line := range body {
+		if strings.TrimSpace(line) == "" {
+			body = body[i+1:]
+			break
+		}
+		if !strings.Contains(line, ": ") {
+			break
+		}
+	}
+	var encoded string
+	for _, line := range body {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "=") {
+			break
+		}
+		encoded += line
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(encoded); err != nil || len(decoded) == 0 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be an ASCII-armored PGP public key, but its body is not valid base64", k))
+	}
+	return
+}
+
+func validatePoolType(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	validTypes := map[string]struct{}{
+		"random": {},
+		"hash":   {},
+		"client": {},
+	}
+
+	if _, ok := validTypes[value]; !ok {
+		errors = append(errors, fmt.Errorf(
+			"%q must be one of ['random', 'hash', 'client']", k))
+	}
+	return
+}
+
+func validatePercentage(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(int)
+	if value < 0 || value > 100 {
+		errors = append(errors, fmt.Errorf(
+			"%q must be a percentage between 0 and 100, got: %d", k, value))
+	}
+	return
+}